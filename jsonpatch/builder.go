@@ -0,0 +1,68 @@
+package jsonpatch
+
+import "slices"
+
+// PatchBuilder assembles a patch from path segments, escaping "~" and "/"
+// in each segment as JSON Pointer requires, so callers never format pointers
+// by hand: Replace([]string{"a/b", "c"}, v) targets "/a~1b/c". Array
+// positions are given as segments too ("0", or "-" to append). Nil or empty
+// segments address the document root. Methods return the builder for
+// chaining; the zero value is ready to use.
+type PatchBuilder struct {
+	patches []Patch
+}
+
+// NewPatchBuilder returns an empty PatchBuilder.
+func NewPatchBuilder() *PatchBuilder {
+	return &PatchBuilder{}
+}
+
+// Add appends an add operation setting path to value.
+func (b *PatchBuilder) Add(path []string, value any) *PatchBuilder {
+	return b.append(Patch{Op: "add", Path: segmentsPointer(path), Value: value})
+}
+
+// Remove appends a remove operation for path.
+func (b *PatchBuilder) Remove(path []string) *PatchBuilder {
+	return b.append(Patch{Op: "remove", Path: segmentsPointer(path)})
+}
+
+// Replace appends a replace operation setting path to value.
+func (b *PatchBuilder) Replace(path []string, value any) *PatchBuilder {
+	return b.append(Patch{Op: "replace", Path: segmentsPointer(path), Value: value})
+}
+
+// Move appends a move operation from from to path.
+func (b *PatchBuilder) Move(from, path []string) *PatchBuilder {
+	return b.append(Patch{Op: "move", From: segmentsPointer(from), Path: segmentsPointer(path)})
+}
+
+// Copy appends a copy operation from from to path.
+func (b *PatchBuilder) Copy(from, path []string) *PatchBuilder {
+	return b.append(Patch{Op: "copy", From: segmentsPointer(from), Path: segmentsPointer(path)})
+}
+
+// Test appends a test operation asserting that path equals value.
+func (b *PatchBuilder) Test(path []string, value any) *PatchBuilder {
+	return b.append(Patch{Op: "test", Path: segmentsPointer(path), Value: value})
+}
+
+// Build returns the operations added so far, in order. The slice is a copy,
+// so the builder can keep being extended without affecting it.
+func (b *PatchBuilder) Build() []Patch {
+	return slices.Clone(b.patches)
+}
+
+func (b *PatchBuilder) append(op Patch) *PatchBuilder {
+	b.patches = append(b.patches, op)
+	return b
+}
+
+// segmentsPointer returns the JSON Pointer for path segments; no segments
+// is the root pointer "".
+func segmentsPointer(segments []string) string {
+	if len(segments) == 0 {
+		return ""
+	}
+	return pointerOf(segments)
+}
@@ -0,0 +1,62 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldBuildEscapedPatchGivenPathSegments(t *testing.T) {
+	// Arrange
+	builder := NewPatchBuilder()
+
+	// Act
+	patches := builder.
+		Test([]string{"version"}, 3).
+		Replace([]string{"a/b", "c"}, "x").
+		Add([]string{"m~n", "-"}, 1).
+		Remove([]string{"tags", "0"}).
+		Move([]string{"old~/key"}, []string{"new"}).
+		Copy([]string{"name"}, []string{"alias"}).
+		Add(nil, map[string]any{"root": true}).
+		Build()
+
+	// Assert
+	assert.Equal(t, []Patch{
+		{Op: "test", Path: "/version", Value: 3},
+		{Op: "replace", Path: "/a~1b/c", Value: "x"},
+		{Op: "add", Path: "/m~0n/-", Value: 1},
+		{Op: "remove", Path: "/tags/0"},
+		{Op: "move", From: "/old~0~1key", Path: "/new"},
+		{Op: "copy", From: "/name", Path: "/alias"},
+		{Op: "add", Path: "", Value: map[string]any{"root": true}},
+	}, patches)
+}
+
+func TestShouldApplyBuiltPatchGivenEscapedKeys(t *testing.T) {
+	// Arrange
+	doc := map[string]any{"a/b": map[string]any{"c": 1}, "x~y": []any{"p"}}
+	var builder PatchBuilder
+
+	// Act
+	patches := builder.Replace([]string{"a/b", "c"}, 2).Add([]string{"x~y", "0"}, "q").Build()
+	result, err := ApplyPatch(doc, patches)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a/b": map[string]any{"c": 2}, "x~y": []any{"q", "p"}}, result)
+}
+
+func TestShouldKeepBuiltPatchGivenLaterBuilderCalls(t *testing.T) {
+	// Arrange
+	builder := NewPatchBuilder().Remove([]string{"a"})
+
+	// Act
+	first := builder.Build()
+	builder.Remove([]string{"b"})
+
+	// Assert
+	assert.Equal(t, []Patch{{Op: "remove", Path: "/a"}}, first)
+	assert.Len(t, builder.Build(), 2)
+}
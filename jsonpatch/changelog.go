@@ -0,0 +1,78 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// FormatChangelog renders each patch operation as a human-readable line
+// suitable for audit logs, for example "Set user.email to alice@new.com" or
+// "Removed city". JSON Pointer paths are rendered in dotted notation with
+// array indices in brackets (e.g. "items[0].name").
+func FormatChangelog(patches []Patch) []string {
+	lines := make([]string, 0, len(patches))
+	for _, op := range patches {
+		lines = append(lines, formatChangelogLine(op))
+	}
+	return lines
+}
+
+func formatChangelogLine(op Patch) string {
+	path := readablePath(op.Path)
+	switch op.Op {
+	case "add":
+		if strings.HasSuffix(op.Path, "/-") {
+			return fmt.Sprintf("Appended %s to %s", readableValue(op.Value), readablePath(strings.TrimSuffix(op.Path, "/-")))
+		}
+		return fmt.Sprintf("Added %s=%s", path, readableValue(op.Value))
+	case "remove":
+		return "Removed " + path
+	case "replace":
+		return fmt.Sprintf("Set %s to %s", path, readableValue(op.Value))
+	case "move":
+		return fmt.Sprintf("Moved %s to %s", readablePath(op.From), path)
+	case "copy":
+		return fmt.Sprintf("Copied %s to %s", readablePath(op.From), path)
+	case "test":
+		return fmt.Sprintf("Verified %s equals %s", path, readableValue(op.Value))
+	default:
+		return fmt.Sprintf("Applied %s to %s", op.Op, path)
+	}
+}
+
+// readablePath converts a JSON Pointer into dotted notation, rendering
+// numeric segments as array indices.
+func readablePath(pointer string) string {
+	parts, err := parsePath(pointer)
+	if err != nil {
+		return pointer
+	}
+	if len(parts) == 0 {
+		return "document"
+	}
+
+	var builder strings.Builder
+	for i, part := range parts {
+		if isArrayIndex(part) && i > 0 {
+			builder.WriteString("[" + part + "]")
+			continue
+		}
+		if i > 0 {
+			builder.WriteByte('.')
+		}
+		builder.WriteString(part)
+	}
+	return builder.String()
+}
+
+func readableValue(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
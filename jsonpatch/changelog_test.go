@@ -0,0 +1,54 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldFormatChangelogGivenMixedPatch(t *testing.T) {
+	// Arrange
+	patches := []Patch{
+		{Op: "replace", Path: "/user/email", Value: "alice@new.com"},
+		{Op: "remove", Path: "/city"},
+		{Op: "add", Path: "/country", Value: "USA"},
+		{Op: "add", Path: "/tags/-", Value: "vip"},
+		{Op: "replace", Path: "/orders/0/total", Value: 42.5},
+		{Op: "move", Path: "/archive/address", From: "/user/address"},
+		{Op: "copy", Path: "/billing/name", From: "/user/name"},
+		{Op: "test", Path: "/version", Value: 3},
+		{Op: "add", Path: "/a~1b", Value: map[string]any{"x": true}},
+	}
+
+	// Act
+	lines := FormatChangelog(patches)
+
+	// Assert
+	assert.Equal(t, []string{
+		"Set user.email to alice@new.com",
+		"Removed city",
+		"Added country=USA",
+		"Appended vip to tags",
+		"Set orders[0].total to 42.5",
+		"Moved user.address to archive.address",
+		"Copied user.name to billing.name",
+		"Verified version equals 3",
+		`Added a/b={"x":true}`,
+	}, lines)
+}
+
+func TestShouldDescribeDocumentRootGivenEmptyPath(t *testing.T) {
+	// Act
+	lines := FormatChangelog([]Patch{{Op: "replace", Path: "", Value: map[string]any{}}})
+
+	// Assert
+	assert.Equal(t, []string{"Set document to {}"}, lines)
+}
+
+func TestShouldRenderLargeIndexAsArrayIndexGivenChangelog(t *testing.T) {
+	// Act
+	lines := FormatChangelog([]Patch{{Op: "remove", Path: "/items/18446744073709551616/name"}})
+
+	// Assert
+	assert.Equal(t, []string{"Removed items[18446744073709551616].name"}, lines)
+}
@@ -0,0 +1,171 @@
+package jsonpatch
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ApplyPatchWithChanges behaves like ApplyPatch and also returns the sorted,
+// de-duplicated JSON Pointers of the locations the patch changed, for
+// example to invalidate cache entries.
+//
+// Paths written by add, replace, copy, and move are reported where the value
+// ends up in the result: later operations that insert into or remove from
+// the same array shift them, "-" is resolved to the concrete index, and
+// paths overwritten or removed by a later operation are dropped. Paths
+// emptied by remove and move are reported as they were at the time of the
+// operation. test and guard operations change nothing and are not reported;
+// operations registered with RegisterOp are reported as writes to their path.
+func ApplyPatchWithChanges(original any, patches []Patch, opts ...ApplyOption) (map[string]any, []string, error) {
+	originalMap, err := toMap(original)
+	if err != nil {
+		return nil, nil, err
+	}
+	target := deepCopy(originalMap)
+	cfg := newApplyConfig(opts)
+	tracker := &changeTracker{}
+
+	for _, op := range patches {
+		parts, err := parsePath(op.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		var fromParts []string
+		fromInArray := false
+		if op.Op == "move" {
+			if fromParts, err = cfg.fromParts(op); err != nil {
+				return nil, nil, err
+			}
+			fromInArray = isArrayElement(target, fromParts)
+		}
+		removedFromArray := op.Op == "remove" && isArrayElement(target, parts)
+		effective := cfg.missingOp(op.Op, func() bool { _, ok := getValue(target, parts); return ok })
+
+		if err := cfg.applyOperation(target, op); err != nil {
+			return nil, nil, err
+		}
+
+		switch effective {
+		case "":
+			// A tolerated remove or replace of a missing path changes nothing.
+		case "add", "copy":
+			tracker.insert(target, parts)
+		case "remove":
+			tracker.remove(parts, removedFromArray)
+		case "replace":
+			tracker.write(parts)
+		case "move":
+			if slices.Equal(fromParts, parts) {
+				continue
+			}
+			tracker.remove(fromParts, fromInArray)
+			tracker.insert(target, parts)
+		default:
+			if _, ok := lookupOp(op.Op); ok {
+				tracker.write(parts)
+			}
+		}
+	}
+	return target, tracker.paths(), nil
+}
+
+// changeTracker records the locations changed while applying a patch.
+// live holds paths of written values, kept in sync with later operations;
+// removed holds paths emptied by removals, frozen when recorded.
+type changeTracker struct {
+	live    [][]string
+	removed [][]string
+}
+
+// insert records a value added at parts, which was already applied to
+// target. Array insertions shift later siblings and resolve "-".
+func (t *changeTracker) insert(target map[string]any, parts []string) {
+	if len(parts) > 0 {
+		parent := parts[:len(parts)-1]
+		if arr, ok := getValue(target, parent); ok {
+			if items, ok := arr.([]any); ok {
+				idx := len(items) - 1
+				if last := parts[len(parts)-1]; last != "-" {
+					idx, _ = strconv.Atoi(last)
+				}
+				t.shift(parent, idx, 1)
+				t.live = append(t.live, appendPath(parent, strconv.Itoa(idx)))
+				return
+			}
+		}
+	}
+	t.write(parts)
+}
+
+// write records a value stored at parts, replacing anything beneath it.
+func (t *changeTracker) write(parts []string) {
+	t.drop(parts)
+	t.live = append(t.live, slices.Clone(parts))
+}
+
+// remove records the removal of parts, shifting later array siblings.
+func (t *changeTracker) remove(parts []string, inArray bool) {
+	t.drop(parts)
+	t.removed = append(t.removed, slices.Clone(parts))
+	if inArray {
+		idx, _ := strconv.Atoi(parts[len(parts)-1])
+		t.shift(parts[:len(parts)-1], idx+1, -1)
+	}
+}
+
+// drop forgets live paths at or beneath parts.
+func (t *changeTracker) drop(parts []string) {
+	t.live = slices.DeleteFunc(t.live, func(path []string) bool {
+		return len(path) >= len(parts) && slices.Equal(path[:len(parts)], parts)
+	})
+}
+
+// shift moves live paths addressing elements at index from or later in the
+// array at parent by delta.
+func (t *changeTracker) shift(parent []string, from, delta int) {
+	depth := len(parent)
+	for _, path := range t.live {
+		if len(path) <= depth || !slices.Equal(path[:depth], parent) {
+			continue
+		}
+		if idx, err := strconv.Atoi(path[depth]); err == nil && idx >= from {
+			path[depth] = strconv.Itoa(idx + delta)
+		}
+	}
+}
+
+func (t *changeTracker) paths() []string {
+	result := make([]string, 0, len(t.live)+len(t.removed))
+	for _, parts := range append(slices.Clone(t.live), t.removed...) {
+		escaped := make([]string, len(parts))
+		for i, part := range parts {
+			escaped[i] = escapePathSegment(part)
+		}
+		if len(parts) == 0 {
+			result = append(result, "")
+			continue
+		}
+		result = append(result, "/"+strings.Join(escaped, "/"))
+	}
+	slices.Sort(result)
+	return slices.Compact(result)
+}
+
+// isArrayElement reports whether parts addresses an element of an array in
+// target.
+func isArrayElement(target map[string]any, parts []string) bool {
+	if len(parts) == 0 {
+		return false
+	}
+	parent, ok := getValue(target, parts[:len(parts)-1])
+	if !ok {
+		return false
+	}
+	_, isArray := parent.([]any)
+	return isArray
+}
+
+func appendPath(parent []string, part string) []string {
+	return append(slices.Clone(parent), part)
+}
@@ -0,0 +1,63 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldReportChangedPathsGivenMultiOpPatch(t *testing.T) {
+	// Arrange
+	original := map[string]any{
+		"name":  "Alice",
+		"tags":  []any{"a", "b", "c"},
+		"draft": map[string]any{"title": "x"},
+		"meta":  map[string]any{"views": 1.0},
+	}
+	patches := []Patch{
+		{Op: "replace", Path: "/tags/2", Value: "C"},
+		{Op: "add", Path: "/tags/0", Value: "first"},
+		{Op: "add", Path: "/tags/-", Value: "last"},
+		{Op: "remove", Path: "/tags/1"},
+		{Op: "move", From: "/draft", Path: "/published"},
+		{Op: "replace", Path: "/meta", Value: map[string]any{"views": 2.0}},
+		{Op: "test", Path: "/name", Value: "Alice"},
+	}
+
+	// Act
+	result, changed, err := ApplyPatchWithChanges(original, patches)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []any{"first", "b", "C", "last"}, result["tags"])
+	assert.Equal(t, []string{
+		"/draft",
+		"/meta",
+		"/published",
+		"/tags/0",
+		"/tags/1",
+		"/tags/2",
+		"/tags/3",
+	}, changed)
+}
+
+func TestShouldDropOverwrittenPathsGivenLaterOps(t *testing.T) {
+	// Arrange
+	original := map[string]any{"profile": map[string]any{"name": "a"}, "list": []any{1.0, 2.0}}
+	patches := []Patch{
+		{Op: "replace", Path: "/profile/name", Value: "b"},
+		{Op: "add", Path: "/profile/age", Value: 3.0},
+		{Op: "remove", Path: "/profile"},
+		{Op: "add", Path: "/list/0", Value: 0.0},
+		{Op: "move", From: "/list/0", Path: "/list/-"},
+	}
+
+	// Act
+	result, changed, err := ApplyPatchWithChanges(original, patches)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []any{1.0, 2.0, 0.0}, result["list"])
+	assert.Equal(t, []string{"/list/0", "/list/2", "/profile"}, changed)
+}
@@ -0,0 +1,67 @@
+package jsonpatch
+
+import "encoding/json"
+
+// Clone returns a copy of p whose Value shares no maps, slices, or ordered
+// objects with the original, so either can be modified (for example while
+// remapping paths or rewriting values) without affecting the other. Values
+// nested inside map[string]any, []any, and *OrderedObject are copied
+// recursively and json.RawMessage bytes are duplicated; other values, such as
+// structs or typed slices, are copied as assigned.
+func (p Patch) Clone() Patch {
+	p.Value = cloneValue(p.Value)
+	return p
+}
+
+// ClonePatches returns a deep copy of patches as produced by Patch.Clone. A
+// nil slice stays nil.
+func ClonePatches(patches []Patch) []Patch {
+	if patches == nil {
+		return nil
+	}
+	cloned := make([]Patch, len(patches))
+	for i, op := range patches {
+		cloned[i] = op.Clone()
+	}
+	return cloned
+}
+
+// cloneValue deep-copies the JSON-like containers in v.
+func cloneValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		if val == nil {
+			return val
+		}
+		cp := make(map[string]any, len(val))
+		for key, item := range val {
+			cp[key] = cloneValue(item)
+		}
+		return cp
+	case []any:
+		if val == nil {
+			return val
+		}
+		cp := make([]any, len(val))
+		for i, item := range val {
+			cp[i] = cloneValue(item)
+		}
+		return cp
+	case *OrderedObject:
+		if val == nil {
+			return val
+		}
+		cp := &OrderedObject{keys: val.Keys(), values: make(map[string]any, len(val.values))}
+		for key, item := range val.values {
+			cp.values[key] = cloneValue(item)
+		}
+		return cp
+	case json.RawMessage:
+		if val == nil {
+			return val
+		}
+		return append(json.RawMessage(nil), val...)
+	default:
+		return v
+	}
+}
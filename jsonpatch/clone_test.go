@@ -0,0 +1,76 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldLeaveOriginalUnchangedGivenClonedPatchMutated(t *testing.T) {
+	// Arrange
+	original := Patch{
+		Op:   "add",
+		Path: "/user",
+		Value: map[string]any{
+			"name": "Ada",
+			"tags": []any{"admin", map[string]any{"scope": "read"}},
+		},
+	}
+
+	// Act
+	clone := original.Clone()
+	clone.Path = "/account/user"
+	value := clone.Value.(map[string]any)
+	value["name"] = "Grace"
+	tags := value["tags"].([]any)
+	tags[0] = "guest"
+	tags[1].(map[string]any)["scope"] = "write"
+
+	// Assert
+	assert.Equal(t, Patch{
+		Op:   "add",
+		Path: "/user",
+		Value: map[string]any{
+			"name": "Ada",
+			"tags": []any{"admin", map[string]any{"scope": "read"}},
+		},
+	}, original)
+}
+
+func TestShouldDeepCopyOrderedAndRawValuesGivenClone(t *testing.T) {
+	// Arrange
+	obj := NewOrderedObject()
+	obj.Set("b", map[string]any{"x": 1.0})
+	obj.Set("a", 2.0)
+	raw := json.RawMessage(`{"x":1}`)
+	patches := []Patch{
+		{Op: "add", Path: "/obj", Value: obj},
+		{Op: "add", Path: "/raw", Value: raw},
+	}
+
+	// Act
+	cloned := ClonePatches(patches)
+	clonedObj := cloned[0].Value.(*OrderedObject)
+	clonedObj.Set("c", 3.0)
+	nested, _ := clonedObj.Get("b")
+	nested.(map[string]any)["x"] = 9.0
+	cloned[1].Value.(json.RawMessage)[2] = 'y'
+
+	// Assert
+	require.Len(t, cloned, 2)
+	assert.Equal(t, []string{"b", "a"}, obj.Keys())
+	original, _ := obj.Get("b")
+	assert.Equal(t, map[string]any{"x": 1.0}, original)
+	assert.JSONEq(t, `{"x":1}`, string(raw))
+	assert.Equal(t, []string{"b", "a", "c"}, clonedObj.Keys())
+}
+
+func TestShouldReturnNilGivenClonePatchesOfNil(t *testing.T) {
+	// Act
+	cloned := ClonePatches(nil)
+
+	// Assert
+	assert.Nil(t, cloned)
+}
@@ -0,0 +1,44 @@
+package jsonpatch
+
+// CompactPatch returns an equivalent, shorter patch. A remove of a path
+// immediately followed by an add of a new value at the same path is folded
+// into a single replace, which has the same effect on an object member: both
+// forms fail when the member is missing and leave it holding the new value.
+// The replace keeps the add's value and Reason.
+//
+// Pairs whose last path segment is an array index or "-" are left alone,
+// since removing and inserting array elements is not the same as replacing
+// one when other operations depend on the ordering. Patches whose object
+// members are named with digits are treated the same way, as the pointer
+// alone cannot tell them apart from indices. The input slice is not
+// modified.
+func CompactPatch(patches []Patch) []Patch {
+	compacted := make([]Patch, 0, len(patches))
+	for i := 0; i < len(patches); i++ {
+		op := patches[i]
+		if i+1 < len(patches) && foldsIntoReplace(op, patches[i+1]) {
+			next := patches[i+1]
+			compacted = append(compacted, Patch{Op: "replace", Path: next.Path, Value: next.Value, Reason: next.Reason})
+			i++
+			continue
+		}
+		compacted = append(compacted, op)
+	}
+	return compacted
+}
+
+// foldsIntoReplace reports whether remove followed by add is a replace of
+// an object member. Index-shaped segments are recognized by their digits
+// rather than by parsing, so an index too large for int is still treated as
+// an array position on every platform.
+func foldsIntoReplace(remove, add Patch) bool {
+	if remove.Op != "remove" || add.Op != "add" || remove.Path != add.Path {
+		return false
+	}
+	parts, err := parsePath(remove.Path)
+	if err != nil || len(parts) == 0 {
+		return false
+	}
+	last := parts[len(parts)-1]
+	return last != "-" && !isArrayIndex(last)
+}
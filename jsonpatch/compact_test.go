@@ -0,0 +1,57 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldFoldRemoveThenAddIntoReplaceGivenObjectPath(t *testing.T) {
+	// Arrange
+	original := map[string]any{"a": 1, "user": map[string]any{"email": "a@old.com"}}
+	patches := []Patch{
+		{Op: "remove", Path: "/a"},
+		{Op: "add", Path: "/a", Value: 2},
+		{Op: "remove", Path: "/user/email"},
+		{Op: "add", Path: "/user/email", Value: "a@new.com", Reason: "email changed"},
+	}
+
+	// Act
+	compacted := CompactPatch(patches)
+
+	// Assert
+	assert.Equal(t, []Patch{
+		{Op: "replace", Path: "/a", Value: 2},
+		{Op: "replace", Path: "/user/email", Value: "a@new.com", Reason: "email changed"},
+	}, compacted)
+	expected, err := ApplyPatch(original, patches)
+	require.NoError(t, err)
+	actual, err := ApplyPatch(original, compacted)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestShouldLeavePatchUnchangedGivenNoFoldablePair(t *testing.T) {
+	tests := []struct {
+		name    string
+		patches []Patch
+	}{
+		{name: "array index", patches: []Patch{{Op: "remove", Path: "/items/0"}, {Op: "add", Path: "/items/0", Value: "x"}}},
+		{name: "index above 2^32", patches: []Patch{{Op: "remove", Path: "/items/4294967296"}, {Op: "add", Path: "/items/4294967296", Value: "x"}}},
+		{name: "array append", patches: []Patch{{Op: "remove", Path: "/items/-"}, {Op: "add", Path: "/items/-", Value: "x"}}},
+		{name: "different paths", patches: []Patch{{Op: "remove", Path: "/a"}, {Op: "add", Path: "/b", Value: 1}}},
+		{name: "add then remove", patches: []Patch{{Op: "add", Path: "/a", Value: 1}, {Op: "remove", Path: "/a"}}},
+		{name: "not adjacent", patches: []Patch{{Op: "remove", Path: "/a"}, {Op: "test", Path: "/b", Value: 1}, {Op: "add", Path: "/a", Value: 1}}},
+		{name: "root", patches: []Patch{{Op: "remove", Path: ""}, {Op: "add", Path: "", Value: map[string]any{}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			compacted := CompactPatch(tt.patches)
+
+			// Assert
+			assert.Equal(t, tt.patches, compacted)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package jsonpatch
+
+// WithDetectCopies replaces an add operation with a copy when its value
+// deep-equals a non-empty object or array that is unchanged between the two
+// documents, so duplicating a large value costs a pointer instead of the
+// whole value. Sources are only taken from paths reached through object
+// members, whose location cannot shift while the patch is applied, and from
+// members not excluded by WithIgnorePaths. When more than one unchanged
+// location holds the value the add is kept, so the generated patch never
+// depends on which duplicate was picked.
+func WithDetectCopies() DiffOption {
+	return func(c *diffConfig) {
+		c.copies = true
+	}
+}
+
+// detectCopies rewrites the add operations in patches whose value has
+// exactly one unchanged source location in before.
+func (c *diffConfig) detectCopies(before, after any, basePath string, patches []Patch) []Patch {
+	if !c.copies {
+		return patches
+	}
+	beforeMap, err := toMap(before)
+	if err != nil {
+		return patches
+	}
+	afterMap, err := toMap(after)
+	if err != nil {
+		return patches
+	}
+
+	var sources []copySource
+	c.collectCopySources(beforeMap, afterMap, basePath, &sources)
+	if len(sources) == 0 {
+		return patches
+	}
+	for i, op := range patches {
+		if op.Op != "add" || !isCopyCandidate(op.Value) {
+			continue
+		}
+		from := ""
+		matches := 0
+		for _, source := range sources {
+			if jsonEqual(source.value, op.Value) {
+				from = source.path
+				matches++
+			}
+		}
+		if matches == 1 {
+			patches[i] = Patch{Op: "copy", From: from, Path: op.Path}
+		}
+	}
+	return patches
+}
+
+// copySource is an unchanged value that a copy operation may read.
+type copySource struct {
+	path  string
+	value any
+}
+
+// collectCopySources appends the non-empty containers that are equal in
+// before and after, walking only object members present on both sides.
+func (c *diffConfig) collectCopySources(before, after map[string]any, basePath string, sources *[]copySource) {
+	for key, beforeVal := range before {
+		afterVal, ok := after[key]
+		if !ok {
+			continue
+		}
+		path := basePath + "/" + escapePathSegment(key)
+		if c.ignored(path) {
+			continue
+		}
+		beforeVal, afterVal = convertValue(beforeVal), convertValue(afterVal)
+		unchanged := c.deepEqualFiltered(beforeVal, afterVal)
+		if unchanged && isCopyCandidate(beforeVal) {
+			*sources = append(*sources, copySource{path: path, value: beforeVal})
+		}
+		beforeObj, beforeIsObj := beforeVal.(map[string]any)
+		afterObj, afterIsObj := afterVal.(map[string]any)
+		if beforeIsObj && afterIsObj {
+			c.collectCopySources(beforeObj, afterObj, path, sources)
+		}
+	}
+}
+
+// isCopyCandidate reports whether v is a non-empty object or array, the only
+// values for which a copy is worth emitting.
+func isCopyCandidate(v any) bool {
+	switch typed := v.(type) {
+	case map[string]any:
+		return len(typed) > 0
+	case []any:
+		return len(typed) > 0
+	}
+	return false
+}
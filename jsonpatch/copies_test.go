@@ -0,0 +1,114 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func largeAddress() map[string]any {
+	return map[string]any{
+		"street": "1 Main St",
+		"city":   "Springfield",
+		"geo": map[string]any{
+			"lat":  39.78,
+			"lng":  -89.65,
+			"tags": []any{"home", "primary", "verified"},
+		},
+	}
+}
+
+func TestShouldEmitCopyGivenDuplicatedNestedObject(t *testing.T) {
+	// Arrange
+	before := map[string]any{"user": map[string]any{"name": "Ann", "billing": largeAddress()}}
+	after := map[string]any{"user": map[string]any{"name": "Ann", "billing": largeAddress(), "shipping": largeAddress()}}
+
+	// Act
+	patches, err := GeneratePatch(before, after, "", WithDetectCopies())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Patch{{Op: "copy", From: "/user/billing", Path: "/user/shipping"}}, patches)
+	result, err := ApplyPatch(before, patches)
+	require.NoError(t, err)
+	assert.Equal(t, after, result)
+}
+
+func TestShouldKeepAddGivenAmbiguousCopySource(t *testing.T) {
+	// Arrange
+	before := map[string]any{"home": largeAddress(), "work": largeAddress()}
+	after := map[string]any{"home": largeAddress(), "work": largeAddress(), "shipping": largeAddress()}
+
+	// Act
+	patches, err := GeneratePatch(before, after, "", WithDetectCopies())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Patch{{Op: "add", Path: "/shipping", Value: largeAddress()}}, patches)
+}
+
+func TestShouldKeepAddGivenNoUnchangedCopySource(t *testing.T) {
+	tests := []struct {
+		name   string
+		before map[string]any
+		after  map[string]any
+	}{
+		{
+			name:   "source changed",
+			before: map[string]any{"billing": map[string]any{"city": "Shelbyville"}},
+			after:  map[string]any{"billing": map[string]any{"city": "Springfield"}, "shipping": map[string]any{"city": "Shelbyville"}},
+		},
+		{
+			name:   "source inside array",
+			before: map[string]any{"addresses": []any{map[string]any{"city": "Springfield"}}},
+			after:  map[string]any{"addresses": []any{map[string]any{"city": "Springfield"}}, "shipping": map[string]any{"city": "Springfield"}},
+		},
+		{
+			name:   "scalar value",
+			before: map[string]any{"billing": "Springfield"},
+			after:  map[string]any{"billing": "Springfield", "shipping": "Springfield"},
+		},
+		{
+			name:   "source ignored",
+			before: map[string]any{"meta": map[string]any{"city": "Springfield"}},
+			after:  map[string]any{"meta": map[string]any{"city": "Springfield"}, "shipping": map[string]any{"city": "Springfield"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			patches, err := GeneratePatch(tt.before, tt.after, "", WithDetectCopies(), WithIgnorePaths("/meta"))
+
+			// Assert
+			require.NoError(t, err)
+			for _, op := range patches {
+				assert.NotEqual(t, "copy", op.Op, "unexpected copy %+v", op)
+			}
+			result, err := ApplyPatch(tt.before, patches)
+			require.NoError(t, err)
+			assert.Equal(t, tt.after, result)
+		})
+	}
+}
+
+func TestShouldCopyFromStructFieldGivenBasePath(t *testing.T) {
+	// Arrange
+	type profile struct {
+		Tags []string `json:"tags"`
+	}
+	type account struct {
+		Profile profile        `json:"profile"`
+		Extra   map[string]any `json:"extra,omitempty"`
+	}
+	before := account{Profile: profile{Tags: []string{"a", "b"}}}
+	after := account{Profile: profile{Tags: []string{"a", "b"}}, Extra: map[string]any{"tags": []any{"a", "b"}}}
+
+	// Act
+	patches, err := GeneratePatch(before, after, "/account", WithDetectCopies())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Patch{{Op: "copy", From: "/account/profile", Path: "/account/extra"}}, patches)
+}
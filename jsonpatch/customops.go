@@ -0,0 +1,50 @@
+package jsonpatch
+
+import (
+	"fmt"
+	"sync"
+)
+
+// OpHandler applies a custom operation to target, the document being
+// patched, in place. op.Value has already been decoded from
+// json.RawMessage. Returning an error aborts the patch like any failing
+// operation.
+type OpHandler = func(target map[string]any, op Patch) error
+
+var (
+	customOps   = make(map[string]OpHandler)
+	customOpsMu sync.RWMutex
+)
+
+// RegisterOp makes ApplyPatch and the functions built on it (DryRunPatch,
+// ApplyRawPatch, ApplyPatchAndHydrate, ApplyWithPrecondition, and
+// ApplyPatchWithChanges, which reports the operation's path as changed)
+// dispatch operations named name to fn, for non-standard operations such as
+// "increment". Registering a nil fn removes the operation. The registry is
+// process-wide; ApplyPatchOrdered and ApplyPatchReflect keep rejecting
+// custom operations.
+//
+// RegisterOp panics if name is empty or names a standard operation or a
+// guard, which cannot be overridden.
+func RegisterOp(name string, fn OpHandler) {
+	switch name {
+	case "", "add", "remove", "replace", "move", "copy", "test", GuardExists, GuardAbsent:
+		panic(fmt.Sprintf("jsonpatch: cannot register op %q", name))
+	}
+
+	customOpsMu.Lock()
+	if fn == nil {
+		delete(customOps, name)
+	} else {
+		customOps[name] = fn
+	}
+	customOpsMu.Unlock()
+}
+
+// lookupOp returns the handler registered for name with RegisterOp.
+func lookupOp(name string) (OpHandler, bool) {
+	customOpsMu.RLock()
+	fn, ok := customOps[name]
+	customOpsMu.RUnlock()
+	return fn, ok
+}
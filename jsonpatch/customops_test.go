@@ -0,0 +1,77 @@
+package jsonpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// incrementOp adds op.Value to the number at op.Path.
+func incrementOp(target map[string]any, op Patch) error {
+	parts, err := parsePath(op.Path)
+	if err != nil {
+		return err
+	}
+	current, ok := getValue(target, parts)
+	if !ok {
+		return fmt.Errorf("increment: path %s does not exist", op.Path)
+	}
+	base, ok := numericValue(current)
+	if !ok {
+		return fmt.Errorf("increment: value at %s is not a number", op.Path)
+	}
+	delta, ok := numericValue(op.Value)
+	if !ok {
+		return fmt.Errorf("increment: value %v is not a number", op.Value)
+	}
+	return applyReplace(target, parts, base+delta)
+}
+
+func TestShouldApplyRegisteredIncrementOp(t *testing.T) {
+	// Arrange
+	RegisterOp("increment", incrementOp)
+	t.Cleanup(func() { RegisterOp("increment", nil) })
+	doc := map[string]any{"stats": map[string]any{"views": 41.0}, "title": "a"}
+
+	// Act
+	result, err := ApplyPatch(doc, []Patch{
+		{Op: "increment", Path: "/stats/views", Value: 1},
+		{Op: "replace", Path: "/title", Value: "b"},
+	})
+	raw, rawErr := ApplyRawPatch(doc, []byte(`[{"op":"increment","path":"/stats/views","value":2}]`))
+	_, changed, changesErr := ApplyPatchWithChanges(doc, []Patch{{Op: "increment", Path: "/stats/views", Value: 1}})
+	_, failErr := ApplyPatch(doc, []Patch{{Op: "increment", Path: "/title", Value: 1}})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 42.0, result["stats"].(map[string]any)["views"])
+	assert.Equal(t, "b", result["title"])
+	require.NoError(t, rawErr)
+	assert.Equal(t, 43.0, raw["stats"].(map[string]any)["views"])
+	require.NoError(t, changesErr)
+	assert.Equal(t, []string{"/stats/views"}, changed)
+	assert.EqualError(t, failErr, "increment: value at /title is not a number")
+	assert.Equal(t, 41.0, doc["stats"].(map[string]any)["views"])
+}
+
+func TestShouldRejectUnregisteredOpGivenRemovedRegistration(t *testing.T) {
+	// Arrange
+	RegisterOp("increment", incrementOp)
+	RegisterOp("increment", nil)
+
+	// Act
+	_, err := ApplyPatch(map[string]any{"n": 1.0}, []Patch{{Op: "increment", Path: "/n", Value: 1}})
+
+	// Assert
+	assert.EqualError(t, err, "unsupported op: increment")
+}
+
+func TestShouldPanicGivenStandardOpName(t *testing.T) {
+	for _, name := range []string{"", "add", "replace", "test", GuardExists} {
+		t.Run(name, func(t *testing.T) {
+			assert.Panics(t, func() { RegisterOp(name, incrementOp) })
+		})
+	}
+}
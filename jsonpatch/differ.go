@@ -0,0 +1,29 @@
+package jsonpatch
+
+import "slices"
+
+// Differ generates patches with a fixed set of DiffOption values, for
+// services that diff many documents with the same configuration. A Differ is
+// safe for concurrent use as long as the functions passed in its options
+// (such as an annotator) are.
+type Differ struct {
+	opts []DiffOption
+}
+
+// NewDiffer returns a Differ applying opts to every diff.
+func NewDiffer(opts ...DiffOption) *Differ {
+	return &Differ{opts: slices.Clone(opts)}
+}
+
+// Diff returns the operations transforming before into after, with paths
+// relative to the document root. It is equivalent to GeneratePatch with the
+// Differ's options and an empty basePath.
+func (d *Differ) Diff(before, after any) ([]Patch, error) {
+	return d.DiffAt(before, after, "")
+}
+
+// DiffAt is like Diff but prefixes every path with basePath, as
+// GeneratePatch does.
+func (d *Differ) DiffAt(before, after any, basePath string) ([]Patch, error) {
+	return newDiffConfig(d.opts).generate(before, after, basePath)
+}
@@ -0,0 +1,139 @@
+package jsonpatch
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldReuseConfigurationGivenDiffer(t *testing.T) {
+	// Arrange
+	differ := NewDiffer(
+		WithIgnorePaths("/meta"),
+		WithStrictStrings(false),
+		WithAnnotator(func(op Patch) string { return op.Op + " " + op.Path }),
+	)
+	pairs := []struct {
+		before map[string]any
+		after  map[string]any
+		want   []Patch
+	}{
+		{
+			before: map[string]any{"name": "a", "meta": map[string]any{"rev": 1.0}},
+			after:  map[string]any{"name": "b", "meta": map[string]any{"rev": 2.0}},
+			want:   []Patch{{Op: "replace", Path: "/name", Value: "b", Reason: "replace /name"}},
+		},
+		{
+			before: map[string]any{"name": "a"},
+			after:  map[string]any{"name": " a ", "meta": map[string]any{"rev": 1.0}},
+			want:   nil,
+		},
+		{
+			before: map[string]any{"tags": []any{"x"}},
+			after:  map[string]any{"tags": []any{"x", "y"}},
+			want:   []Patch{{Op: "add", Path: "/tags/1", Value: "y", Reason: "add /tags/1"}},
+		},
+	}
+
+	for _, pair := range pairs {
+		// Act
+		patches, err := differ.Diff(pair.before, pair.after)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, pair.want, patches)
+	}
+}
+
+func TestShouldIgnoreNumericNoiseGivenDifferWithEpsilon(t *testing.T) {
+	// Arrange
+	differ := NewDiffer(WithEpsilon(1e-9))
+	before := map[string]any{"total": 0.3, "points": []any{1.0, 2.0}, "count": 3}
+	after := map[string]any{"total": 0.1 + 0.2, "points": []any{1.0 + 1e-12, 2.5}, "count": 4}
+
+	// Act
+	patches, err := differ.Diff(before, after)
+
+	// Assert
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Patch{
+		{Op: "replace", Path: "/count", Value: 4},
+		{Op: "replace", Path: "/points/1", Value: 2.5},
+	}, patches)
+}
+
+func TestShouldUseEqualFuncGivenDifferWithEqualFunc(t *testing.T) {
+	// Arrange
+	differ := NewDiffer(WithEqualFunc(func(a, b any) (bool, bool) {
+		as, aOK := a.(string)
+		bs, bOK := b.(string)
+		if !aOK || !bOK {
+			return false, false
+		}
+		return strings.EqualFold(as, bs), true
+	}))
+	before := map[string]any{"email": "Alice@Example.com", "name": "Alice", "age": 30.0}
+	after := map[string]any{"email": "alice@example.com", "name": "Alicia", "age": 31.0}
+
+	// Act
+	patches, err := differ.Diff(before, after)
+
+	// Assert
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Patch{
+		{Op: "replace", Path: "/age", Value: 31.0},
+		{Op: "replace", Path: "/name", Value: "Alicia"},
+	}, patches)
+}
+
+func TestShouldMatchGeneratePatchGivenDifferDiffAt(t *testing.T) {
+	// Arrange
+	before := map[string]any{"a": 1.0, "b": map[string]any{"c": "x"}}
+	after := map[string]any{"a": 2.0, "b": map[string]any{"c": "y"}}
+	opts := []DiffOption{WithIgnorePaths("/a")}
+
+	// Act
+	fromDiffer, err := NewDiffer(opts...).DiffAt(before, after, "/doc")
+	require.NoError(t, err)
+	fromFunc, err := GeneratePatch(before, after, "/doc", opts...)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, fromFunc, fromDiffer)
+	assert.Equal(t, []Patch{{Op: "replace", Path: "/doc/b/c", Value: "y"}}, fromDiffer)
+}
+
+func TestShouldDiffConcurrentlyGivenSharedDiffer(t *testing.T) {
+	// Arrange
+	differ := NewDiffer(WithMaxDepth(3))
+	shallow := map[string]any{"a": map[string]any{"b": 1.0}}
+	deep := nestedDocument(5, 1.0)
+	var wg sync.WaitGroup
+	errs := make([]error, 20)
+
+	// Act
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				_, errs[i] = differ.Diff(shallow, map[string]any{"a": map[string]any{"b": 2.0}})
+			} else {
+				_, errs[i] = differ.Diff(deep, nestedDocument(5, 2.0))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	for i, err := range errs {
+		if i%2 == 0 {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, ErrMaxDepthExceeded)
+		}
+	}
+}
@@ -4,14 +4,101 @@
 //
 // GeneratePatch(before, after, basePath) produces a slice of Patch operations that
 // transform the before document into the after document. Both inputs may be Go structs
-// or map[string]any; they are normalized to a JSON-like map representation. basePath
+// or map[string]any; they are normalized to a JSON-like map representation, so
+// two different Go types with the same JSON shape (for example a DTO and a
+// domain model using int32 and int64, a named string type, or a pointer field)
+// diff by their JSON field names and values. basePath
 // is a JSON Pointer prefix (e.g. "" for the root or "/items" for a nested path).
+// Optional DiffOption values tune generation; for example WithAnnotator labels
+// each operation with a human-readable Reason, marshaled under the non-standard
+// "reason" key and ignored when patches are applied. NewDiffer(opts...) bundles
+// options once; its Diff and DiffAt methods behave like GeneratePatch with
+// those options, and a Differ may be shared between goroutines. WithEpsilon
+// compares numbers within a tolerance, and WithEqualFunc supplies domain
+// equality for value pairs. Values captured in
+// generated operations are deep copies, so mutating the after document later
+// does not change the patch.
+//
+// String comparison is strict by default: a change that only adds or removes
+// surrounding whitespace is still emitted. WithStrictStrings(false) opts into
+// trimmed comparison, which ignores such differences (including inside nested
+// arrays). WithNormalizeUnicode compares strings by their NFC form, so text
+// that was only re-encoded between composed and decomposed characters does not
+// produce replaces.
+//
+// WithIgnorePaths("/meta/updatedAt", "/*/total") suppresses operations for
+// matching object members and their descendants at any depth. Patterns are
+// relative to the diffed documents, not to basePath, and segments may use
+// path.Match wildcards.
+//
+// WithWholeSubtreeThreshold(n) replaces a changed nested object with a single
+// replace of its new value when diffing it would take more than n operations.
+//
+// WithCaseInsensitiveKeys matches object keys that differ only in case, so
+// "Name" becoming "name" with the same value produces no operation.
+//
+// WithTypeChangeAsRemoveAdd emits a remove followed by an add, instead of a
+// replace, when an object member changes JSON type (say from a string to an
+// object).
+//
+// WithDetectCopies emits a copy instead of an add when the added value is a
+// non-empty object or array equal to exactly one unchanged location reached
+// through object members; ambiguous duplicates keep the add.
+//
+// GeneratePatch descends at most DefaultMaxDepth levels of nested objects and
+// fails with an error wrapping ErrMaxDepthExceeded beyond that; WithMaxDepth
+// adjusts the limit for untrusted input.
+//
+// GenerateBidirectionalPatch(before, after) returns the forward patch and the
+// reverse patch that turns after back into before, for undo.
+// GenerateVerifiedPatch(before, after) also applies the patch to a copy of
+// before and fails with an error wrapping ErrPatchVerification unless the
+// result equals after.
+//
+// GeneratePatchFromMask(after, paths) builds a patch from a protobuf-style
+// field mask ("profile.displayName", with "*" matching every member or
+// element): each masked value in after becomes an add (a replace for array
+// elements), and each masked path missing from after becomes a remove.
+//
+// GenerateSubtreePatch(before, after, "/user/preferences") diffs only the
+// value at a JSON Pointer, emitting absolute paths beneath it; the error wraps
+// ErrSubtreeNotFound when the pointer is missing from either document.
+//
+// GeneratePatchNDJSON(before, after, out) diffs paired documents from two
+// newline-delimited JSON streams and writes one patch array per line, failing
+// if the streams differ in length. With WithDecodeStrict it also rejects
+// documents that repeat an object key, returning *DuplicateKeyError, instead
+// of keeping the last value as encoding/json does.
 //
 // ApplyPatch(original, patches) applies the operations in order and returns the
 // result as map[string]any. ApplyPatchAndHydrate(original, updated, patches) applies
 // the patch and unmarshals the result into the typed updated value, which is useful
 // for types whose JSON form differs from their in-memory representation (e.g.
-// uuid.UUID, time.Time, json.RawMessage).
+// uuid.UUID, time.Time, json.RawMessage). Top-level keys the struct does not
+// model are stored in a map[string]any field tagged jsonpatch:"extra" when
+// present, or reported through WithDroppedKeys. DryRunPatch(original, patches) runs the
+// same application against a copy and reports only whether it would succeed.
+// ApplyRawPatch(original, patchJSON) decodes a JSON Patch document received on
+// the wire, validates every operation (known op, required path, from, and
+// value members), and then applies it like ApplyPatch.
+// ApplyWithPrecondition(original, patches, preconditions) runs a list of test
+// operations against original first and applies patches only if all of them
+// hold, failing with an error wrapping ErrPreconditionFailed otherwise.
+// ApplyPatchWithChanges(original, patches) additionally returns the sorted JSON
+// Pointers the patch changed, with array indices resolved against shifts from
+// later operations. ApplyPatchToEach(arrayDoc, patches) applies the same patch
+// to every object in an array independently and joins one *ElementError per
+// element that failed.
+// TranslatePatch(patches, translator) hands each operation to a Translator as
+// storage-level Set, Unset, ArrayInsert, ArrayRemove, Move, Copy, and Test
+// calls, for building backend update statements; array positions are
+// recognized from the path alone. RecordingTranslator records the calls.
+// ApplyPatchReflect(&target, patches) skips the JSON round-trip and sets
+// exported struct fields, map entries, and slice elements in place by
+// reflection, converting values to the field types and reporting values that
+// do not fit as *TypeMismatchError; a number replacing one held in an
+// interface keeps the existing numeric type when it fits. Struct fields cannot
+// be deleted, so remove resets them to their zero value.
 //
 // ApplyPatch is object-root oriented: it always returns map[string]any. The empty
 // JSON Pointer path targets the document root. Root add/replace operations require
@@ -22,14 +109,75 @@
 //
 // Supported operations: add, remove, replace, move, copy, and test. Path and From
 // use JSON Pointer (RFC 6901). The implementation applies patches sequentially and
-// returns an error on the first failing operation.
+// returns an error on the first failing operation. Paths may descend through
+// consecutive array indices, as in /grid/1/0 for an array of arrays. Values of
+// type json.RawMessage
+// are decoded before they are applied, so raw wire values are stored as JSON
+// values rather than byte slices.
+//
+// PatchBuilder assembles operations from unescaped path segments, so
+// NewPatchBuilder().Replace([]string{"a/b", "c"}, v).Build() targets
+// "/a~1b/c".
+//
+// Apply functions accept ApplyOption values for non-standard extensions, all
+// off by default. WithGuards enables the guard operations "exists" and
+// "absent" (GuardExists, GuardAbsent), which assert only whether a path is
+// present and change nothing. WithTolerant skips remove and replace operations
+// whose path does not exist, and WithUpsert applies such a replace as an add,
+// for idempotent reconciliation. WithRelativePointers lets move and copy name
+// their source with a Relative JSON Pointer resolved against the operation's
+// path, so {"op":"copy","from":"2/name","path":"/user/billing/name"} copies
+// /user/name.
+//
+// RegisterOp(name, fn) adds a process-wide non-standard operation such as
+// "increment"; ApplyPatch dispatches operations with that name to fn instead
+// of rejecting them. Standard operations and guards cannot be overridden.
 //
 // # Array handling
 //
 // Patch generation uses a longest-common-subsequence (LCS) heuristic for arrays to
 // produce minimal edit sequences. Element identity is based on deep equality;
 // for complex arrays without stable identity, consider replacing whole arrays or
-// keying by an identity field.
+// keying by an identity field. Element types that implement ElementKeyer
+// (ElementKey() string) are matched by key instead, so reordering yields moves
+// and changed elements are diffed in place. WithElementKeyField("id") does the
+// same for arrays of objects carrying a unique scalar "id" member, so an
+// element that moved and changed yields a move plus nested operations.
+// Without identity, WithSimilarityThreshold(0.5) replaces an object in place
+// instead of removing it and adding its successor when at least half of
+// their members are equal. The underlying algorithm is exported as the
+// generic Diff[T](before, after, equal), which returns Keep/Delete/Insert edits
+// for any slices. ArrayAlignment(before, after, equal) presents the same result
+// as rows of before/after index Pairs for side-by-side diff views.
+//
+// # Merging
+//
+// MergePatches(a, b) combines two patches generated independently against the
+// same base. Non-overlapping operations are concatenated (a's first), identical
+// operations are kept once, and operations whose paths overlap (one equals or
+// contains the other) with different effects are dropped and reported as
+// Conflict values. Array indices are compared literally.
+//
+// CompactPatch(patches) folds a remove immediately followed by an add of the
+// same object member into a single replace. Array index paths are left alone.
+//
+// Patch.Clone and ClonePatches deep-copy operation values, so pipelines that
+// rewrite paths or values do not modify patches shared with other code.
+//
+// Flatten(doc) maps each leaf of a document to its JSON Pointer, such as
+// {"/user/name":"Alice","/list/0":1}, keeping empty objects and arrays as
+// leaves; Unflatten reverses it, rebuilding containers keyed 0 through n-1
+// as arrays.
+//
+// # Ordered objects
+//
+// OrderedObject is a JSON object that keeps its key order; unmarshal JSON into
+// it when order matters (for example configuration files). With WithKeyOrder,
+// GeneratePatch compares the key order of ordered objects and emits a move of a
+// key onto itself for each key that must be re-appended. Such a move is a no-op
+// for unordered documents, while ApplyPatchOrdered treats it as remove-then-add
+// and so reproduces the new order. Key order of objects inside arrays is not
+// tracked.
 //
 // # Special types
 //
@@ -0,0 +1,87 @@
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// DuplicateKeyError reports an object that contains the same key twice.
+// Path is the JSON Pointer of the duplicated member.
+type DuplicateKeyError struct {
+	Path string
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("duplicate object key at %s", e.Path)
+}
+
+// checkDuplicateKeys scans the token stream of data and returns a
+// *DuplicateKeyError for the first object key that appears twice in the same
+// object. Syntax errors are returned as reported by encoding/json.
+func checkDuplicateKeys(data []byte) error {
+	type frame struct {
+		object     bool
+		path       []string
+		keys       map[string]bool
+		awaitKey   bool
+		currentKey string
+		index      int
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var stack []*frame
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var top *frame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		if top != nil && top.object && top.awaitKey {
+			key := tok.(string)
+			if top.keys[key] {
+				return &DuplicateKeyError{Path: pointerOf(appendPath(top.path, key))}
+			}
+			top.keys[key] = true
+			top.currentKey = key
+			top.awaitKey = false
+			continue
+		}
+
+		// tok starts a value; work out where it lives.
+		var path []string
+		switch {
+		case top == nil:
+		case top.object:
+			path = appendPath(top.path, top.currentKey)
+			top.awaitKey = true
+		default:
+			path = appendPath(top.path, strconv.Itoa(top.index))
+			top.index++
+		}
+		if delim, ok := tok.(json.Delim); ok {
+			stack = append(stack, &frame{
+				object:   delim == '{',
+				path:     slices.Clip(path),
+				keys:     map[string]bool{},
+				awaitKey: delim == '{',
+			})
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package jsonpatch
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldRejectDuplicateKeysGivenWithDecodeStrict(t *testing.T) {
+	// Arrange
+	before := strings.NewReader(`{"a":1,"a":2}` + "\n")
+	after := strings.NewReader(`{"a":3}` + "\n")
+	var out bytes.Buffer
+
+	// Act
+	err := GeneratePatchNDJSON(before, after, &out, WithDecodeStrict())
+
+	// Assert
+	var dup *DuplicateKeyError
+	require.True(t, errors.As(err, &dup))
+	assert.Equal(t, "/a", dup.Path)
+	assert.ErrorContains(t, err, "record 1: decode before: duplicate object key at /a")
+	assert.Empty(t, out.String())
+}
+
+func TestShouldKeepLastDuplicateKeyGivenDefaultDecoding(t *testing.T) {
+	// Arrange
+	before := strings.NewReader(`{"a":1,"a":2}` + "\n")
+	after := strings.NewReader(`{"a":2}` + "\n")
+	var out bytes.Buffer
+
+	// Act
+	err := GeneratePatchNDJSON(before, after, &out)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "[]\n", out.String())
+}
+
+func TestShouldLocateDuplicateKeysGivenNestedDocuments(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{name: "unique keys", doc: `{"a":1,"b":{"a":1},"c":[{"a":1},{"a":2}]}`},
+		{name: "root", doc: `{"a":1,"b":2,"a":3}`, want: "/a"},
+		{name: "nested object", doc: `{"a":{"x":1,"y":{"z":1,"z":2}}}`, want: "/a/y/z"},
+		{name: "inside array", doc: `{"items":[{"id":1},{"id":2,"id":3}]}`, want: "/items/1/id"},
+		{name: "array of arrays", doc: `{"grid":[[1],[{"k":1,"k":1}]]}`, want: "/grid/1/0/k"},
+		{name: "escaped key", doc: `{"a/b":1,"a/b":2}`, want: "/a~1b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := checkDuplicateKeys([]byte(tt.doc))
+
+			// Assert
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var dup *DuplicateKeyError
+			require.True(t, errors.As(err, &dup))
+			assert.Equal(t, tt.want, dup.Path)
+		})
+	}
+}
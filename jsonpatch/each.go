@@ -0,0 +1,47 @@
+package jsonpatch
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ElementError reports that a patch failed for one element of the array
+// passed to ApplyPatchToEach. Index is the element's position.
+type ElementError struct {
+	Index int
+	Err   error
+}
+
+func (e *ElementError) Error() string {
+	return fmt.Sprintf("jsonpatch: element %d: %v", e.Index, e.Err)
+}
+
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyPatchToEach applies patches to every element of arrayDoc, a slice or
+// array of JSON-like objects, independently, as in a bulk edit of a list of
+// records. Paths are relative to each element, so "/status" targets each
+// element's status member. Every element is attempted; the result holds the
+// patched elements in order, with each element that failed left as the
+// original, and the error joins one *ElementError per failure. arrayDoc is
+// never modified. A document that is not an array fails without a result.
+func ApplyPatchToEach(arrayDoc any, patches []Patch, opts ...ApplyOption) ([]any, error) {
+	elements, err := toSlice(arrayDoc)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]any, len(elements))
+	var errs []error
+	for i, element := range elements {
+		patched, err := ApplyPatch(element, patches, opts...)
+		if err != nil {
+			results[i] = element
+			errs = append(errs, &ElementError{Index: i, Err: err})
+			continue
+		}
+		results[i] = patched
+	}
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,62 @@
+package jsonpatch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldReplaceFieldInEveryElementGivenArrayDocument(t *testing.T) {
+	// Arrange
+	type Task struct {
+		ID     int    `json:"id"`
+		Status string `json:"status"`
+	}
+	tasks := []Task{{ID: 1, Status: "open"}, {ID: 2, Status: "blocked"}}
+	patch := []Patch{{Op: "replace", Path: "/status", Value: "done"}}
+
+	// Act
+	results, err := ApplyPatchToEach(tasks, patch)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		map[string]any{"id": 1, "status": "done"},
+		map[string]any{"id": 2, "status": "done"},
+	}, results)
+	assert.Equal(t, "open", tasks[0].Status)
+}
+
+func TestShouldAggregateElementErrorsGivenFailingElements(t *testing.T) {
+	// Arrange
+	doc := []any{
+		map[string]any{"name": "a"},
+		map[string]any{"title": "b"},
+		"not an object",
+	}
+	patch := []Patch{{Op: "replace", Path: "/name", Value: "z"}}
+
+	// Act
+	results, err := ApplyPatchToEach(doc, patch)
+
+	// Assert
+	require.Error(t, err)
+	assert.Equal(t, []any{map[string]any{"name": "z"}, doc[1], doc[2]}, results)
+	var elementErr *ElementError
+	require.ErrorAs(t, err, &elementErr)
+	assert.Equal(t, 1, elementErr.Index)
+	assert.Contains(t, err.Error(), "element 2")
+	assert.Equal(t, map[string]any{"name": "a"}, doc[0])
+}
+
+func TestShouldRejectNonArrayDocumentGivenApplyPatchToEach(t *testing.T) {
+	// Act
+	results, err := ApplyPatchToEach(map[string]any{"a": 1}, nil)
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, results)
+	assert.False(t, errors.As(err, new(*ElementError)))
+}
@@ -0,0 +1,159 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Flatten maps every leaf of doc to its JSON Pointer, so
+// {"user":{"name":"Alice"},"list":[1]} becomes
+// {"/user/name":"Alice","/list/0":1}, which suits indexing, search, and
+// comparing documents member by member. Leaves are scalars, null, and empty
+// objects and arrays, which are kept so Unflatten can restore them; a scalar
+// doc flattens to the key "". Decoded JSON and OrderedObject values are
+// walked directly; other values such as structs are read in their JSON form,
+// and a value that cannot be marshaled is kept as a leaf.
+func Flatten(doc any) map[string]any {
+	flat := make(map[string]any)
+	flattenInto(flat, "", doc)
+	return flat
+}
+
+func flattenInto(flat map[string]any, pointer string, value any) {
+	switch node := value.(type) {
+	case map[string]any:
+		if len(node) == 0 {
+			flat[pointer] = map[string]any{}
+			return
+		}
+		for key, child := range node {
+			flattenInto(flat, pointer+"/"+escapePathSegment(key), child)
+		}
+	case *OrderedObject:
+		if node == nil || len(node.keys) == 0 {
+			flat[pointer] = map[string]any{}
+			return
+		}
+		for _, key := range node.keys {
+			flattenInto(flat, pointer+"/"+escapePathSegment(key), node.values[key])
+		}
+	case []any:
+		if len(node) == 0 {
+			flat[pointer] = []any{}
+			return
+		}
+		for i, child := range node {
+			flattenInto(flat, arrayPath(pointer, i), child)
+		}
+	case nil, string, bool, float64, json.Number:
+		flat[pointer] = value
+	default:
+		decoded, ok := decodeJSONForm(value)
+		if !ok {
+			flat[pointer] = value
+			return
+		}
+		switch decoded.(type) {
+		case map[string]any, []any:
+			flattenInto(flat, pointer, decoded)
+		default:
+			flat[pointer] = value
+		}
+	}
+}
+
+// decodeJSONForm returns value as encoding/json would decode its encoding.
+func decodeJSONForm(value any) (any, bool) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, false
+	}
+	return decoded, true
+}
+
+// Unflatten rebuilds the document that Flatten produced flat from. A
+// container whose members are exactly the indices 0 through n-1 becomes an
+// array and any other container an object, so an object keyed "0", "1", ...
+// comes back as an array. It fails on invalid pointers and on keys that
+// conflict, such as "/a" holding a scalar alongside "/a/b".
+func Unflatten(flat map[string]any) (any, error) {
+	if value, ok := flat[""]; ok {
+		if len(flat) > 1 {
+			return nil, fmt.Errorf("flattened document holds the root %q alongside other keys", "")
+		}
+		return value, nil
+	}
+
+	pointers := make([]string, 0, len(flat))
+	for pointer := range flat {
+		pointers = append(pointers, pointer)
+	}
+	slices.Sort(pointers)
+
+	root := make(flatNode)
+	for _, pointer := range pointers {
+		parts, err := parsePath(pointer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", pointer, err)
+		}
+		node := root
+		for i, part := range parts[:len(parts)-1] {
+			child, exists := node[part]
+			if !exists {
+				child = make(flatNode)
+				node[part] = child
+			}
+			next, ok := child.(flatNode)
+			if !ok {
+				return nil, fmt.Errorf("key %q conflicts with the value at %q", pointer, segmentsPointer(parts[:i+1]))
+			}
+			node = next
+		}
+		last := parts[len(parts)-1]
+		if _, exists := node[last]; exists {
+			return nil, fmt.Errorf("key %q conflicts with nested keys", pointer)
+		}
+		node[last] = flat[pointer]
+	}
+	return root.build(), nil
+}
+
+// flatNode is a container created by Unflatten, as opposed to a leaf value
+// taken from the flattened map.
+type flatNode map[string]any
+
+// build returns the node as an array when its keys are exactly 0 through
+// n-1, and as an object otherwise.
+func (n flatNode) build() any {
+	items := make([]any, len(n))
+	isArray := len(n) > 0
+	for key := range n {
+		index, err := strconv.Atoi(key)
+		if err != nil || !isArrayIndex(key) || index >= len(n) {
+			isArray = false
+			break
+		}
+	}
+	object := make(map[string]any, len(n))
+	for key, child := range n {
+		if node, ok := child.(flatNode); ok {
+			child = node.build()
+		}
+		if isArray {
+			index, _ := strconv.Atoi(key)
+			items[index] = child
+		} else {
+			object[key] = child
+		}
+	}
+	if isArray {
+		return items
+	}
+	return object
+}
@@ -0,0 +1,87 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldRoundTripNestedDocumentGivenFlattenAndUnflatten(t *testing.T) {
+	// Arrange
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"user": {"name": "Alice", "a/b": {"~x": true}},
+		"list": [1, [2, 3], {"k": null}],
+		"empty": {},
+		"none": [],
+		"codes": {"01": "keep as object"}
+	}`), &doc))
+
+	// Act
+	flat := Flatten(doc)
+	restored, err := Unflatten(flat)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"/user/name":     "Alice",
+		"/user/a~1b/~0x": true,
+		"/list/0":        float64(1),
+		"/list/1/0":      float64(2),
+		"/list/1/1":      float64(3),
+		"/list/2/k":      nil,
+		"/empty":         map[string]any{},
+		"/none":          []any{},
+		"/codes/01":      "keep as object",
+	}, flat)
+	assert.Equal(t, doc, restored)
+}
+
+func TestShouldFlattenStructsAndScalarsGivenFlatten(t *testing.T) {
+	// Arrange
+	type Item struct {
+		SKU  string `json:"sku"`
+		Tags []int  `json:"tags"`
+	}
+	ordered := NewOrderedObject()
+	ordered.Set("z", 1)
+
+	// Act
+	fromStruct := Flatten(map[string]any{"item": Item{SKU: "a", Tags: []int{7}}, "n": 2})
+	fromOrdered := Flatten(ordered)
+	scalar := Flatten("x")
+	restored, err := Unflatten(scalar)
+
+	// Assert
+	assert.Equal(t, map[string]any{"/item/sku": "a", "/item/tags/0": float64(7), "/n": 2}, fromStruct)
+	assert.Equal(t, map[string]any{"/z": 1}, fromOrdered)
+	assert.Equal(t, map[string]any{"": "x"}, scalar)
+	require.NoError(t, err)
+	assert.Equal(t, "x", restored)
+}
+
+func TestShouldRejectConflictingKeysGivenUnflatten(t *testing.T) {
+	tests := []struct {
+		name string
+		flat map[string]any
+		want string
+	}{
+		{name: "scalar and member", flat: map[string]any{"/a": 1, "/a/b": 2}, want: `key "/a/b" conflicts with the value at "/a"`},
+		{name: "empty object and member", flat: map[string]any{"/a": map[string]any{}, "/a/b": 2}, want: "conflicts"},
+		{name: "root and member", flat: map[string]any{"": 1, "/a": 2}, want: "root"},
+		{name: "invalid pointer", flat: map[string]any{"/a//b": 1}, want: "invalid key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			doc, err := Unflatten(tt.flat)
+
+			// Assert
+			require.Error(t, err)
+			assert.Nil(t, doc)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
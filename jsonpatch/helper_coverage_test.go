@@ -556,6 +556,12 @@ func TestShouldCopyAndCompareJSONValuesGivenNestedContainers(t *testing.T) {
 					{name: "maps", a: map[string]any{"a": []any{float64(1), "x"}}, b: map[string]any{"a": []any{float64(1), "x"}}, want: true},
 					{name: "struct fallback", a: struct{ ID int }{ID: 1}, b: struct{ ID int }{ID: 1}, want: true},
 					{name: "mismatch", a: map[string]any{"a": true}, b: map[string]any{"a": false}, want: false},
+					{name: "typed and untyped slices", a: []string{"a", "b"}, b: []any{"a", "b"}, want: true},
+					{name: "nested typed slices", a: [][]string{{"a"}, {"b"}}, b: []any{[]any{"a"}, []string{"b"}}, want: true},
+					{name: "nested slice whitespace", a: []any{[]string{"a "}}, b: []any{[]any{"a"}}, want: false},
+					{name: "bytes and base64 string", a: []byte("hi"), b: "aGk=", want: true},
+					{name: "base64 string and bytes", a: "aGk=", b: []byte("hi"), want: true},
+					{name: "bytes and wrong string", a: []byte("hi"), b: "hi", want: false},
 				}
 
 				// Act / Assert
@@ -586,7 +592,7 @@ func TestShouldGenerateArrayPatchesGivenCommonArrayShapes(t *testing.T) {
 				after := []any{"a", "x", "c", "d"}
 
 				// Act
-				prefix, beforeMid, afterMid := trimCommonArrayEdges(before, after)
+				prefix, beforeMid, afterMid := newDiffConfig(nil).trimCommonArrayEdges(before, after)
 
 				// Assert
 				assert.Equal(t, 1, prefix)
@@ -602,7 +608,7 @@ func TestShouldGenerateArrayPatchesGivenCommonArrayShapes(t *testing.T) {
 				after := []any{"a", "x", "c"}
 
 				// Act
-				patches, err := arrayDiff("/items", before, after)
+				patches, err := newDiffConfig(nil).arrayDiff("/items", before, after)
 
 				// Assert
 				require.NoError(t, err)
@@ -620,7 +626,7 @@ func TestShouldGenerateArrayPatchesGivenCommonArrayShapes(t *testing.T) {
 				after := []any{"a", "c"}
 
 				// Act
-				patches, err := arrayDiff("/items", before, after)
+				patches, err := newDiffConfig(nil).arrayDiff("/items", before, after)
 
 				// Assert
 				require.NoError(t, err)
@@ -637,12 +643,13 @@ func TestShouldGenerateArrayPatchesGivenCommonArrayShapes(t *testing.T) {
 				swapAfter := []any{2, 1, 3}
 				lcsBefore := []any{"a", "b", "c", "d"}
 				lcsAfter := []any{"a", "x", "c", "d", "e"}
+				cfg := newDiffConfig(nil)
 
 				// Act
-				swapPatch, swapErr := generateArrayPatch("/items", swapBefore, swapAfter)
-				lcsPatch, lcsErr := generateArrayPatch("/items", lcsBefore, lcsAfter)
-				_, beforeErr := generateArrayPatch("/items", make(chan int), swapAfter)
-				_, afterErr := generateArrayPatch("/items", swapBefore, make(chan int))
+				swapPatch, swapErr := cfg.generateArrayPatch("/items", swapBefore, swapAfter)
+				lcsPatch, lcsErr := cfg.generateArrayPatch("/items", lcsBefore, lcsAfter)
+				_, beforeErr := cfg.generateArrayPatch("/items", make(chan int), swapAfter)
+				_, afterErr := cfg.generateArrayPatch("/items", swapBefore, make(chan int))
 
 				// Assert
 				require.NoError(t, swapErr)
@@ -661,7 +668,7 @@ func TestShouldGenerateArrayPatchesGivenCommonArrayShapes(t *testing.T) {
 				items := []any{"a", "b"}
 
 				// Act
-				patches, err := arrayDiff("/items", items, items)
+				patches, err := newDiffConfig(nil).arrayDiff("/items", items, items)
 
 				// Assert
 				require.NoError(t, err)
@@ -0,0 +1,233 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// ElementKeyer is implemented by array element types with a stable identity,
+// such as domain entities with an ID. When every element of both arrays
+// being diffed implements it (on the value or pointer receiver) and the keys
+// are unique within each array, elements are matched by ElementKey instead
+// of by value: reordering produces move operations and a changed element is
+// diffed in place rather than removed and re-added. Arrays of other elements
+// use the positional LCS diff.
+type ElementKeyer interface {
+	ElementKey() string
+}
+
+// elementKeys returns the ElementKey of each element of the slice or array
+// data, reporting false when data is not one, an element does not implement
+// ElementKeyer, or two elements share a key.
+func elementKeys(data any) ([]string, bool) {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+	keys := make([]string, v.Len())
+	seen := make(map[string]bool, v.Len())
+	for i := range keys {
+		keyer, ok := elementKeyer(v.Index(i))
+		if !ok {
+			return nil, false
+		}
+		key := keyer.ElementKey()
+		if seen[key] {
+			return nil, false
+		}
+		seen[key] = true
+		keys[i] = key
+	}
+	return keys, true
+}
+
+func elementKeyer(elem reflect.Value) (ElementKeyer, bool) {
+	if elem.Kind() == reflect.Interface {
+		elem = elem.Elem()
+	}
+	if !elem.IsValid() || (elem.Kind() == reflect.Pointer && elem.IsNil()) {
+		return nil, false
+	}
+	if keyer, ok := elem.Interface().(ElementKeyer); ok {
+		return keyer, true
+	}
+	if elem.CanAddr() {
+		keyer, ok := elem.Addr().Interface().(ElementKeyer)
+		return keyer, ok
+	}
+	return nil, false
+}
+
+// WithElementKeyField matches the elements of arrays of objects by the value
+// of their field member, as ElementKeyer does for typed elements: an element
+// that is both reordered and edited becomes a move followed by operations on
+// just its changed members, rather than a remove and an add of the whole new
+// value. It applies to an array pair only when every element on both sides
+// is an object whose field holds a string, number, or boolean, unique within
+// its array; other arrays use the positional LCS diff. ElementKeyer takes
+// precedence.
+func WithElementKeyField(field string) DiffOption {
+	return func(c *diffConfig) {
+		c.keyField = field
+	}
+}
+
+// fieldKeys returns the JSON encoding of the key field of each element,
+// reporting false when keying by field does not apply to elements.
+func (c *diffConfig) fieldKeys(elements []any) ([]string, bool) {
+	if c.keyField == "" {
+		return nil, false
+	}
+	keys := make([]string, len(elements))
+	seen := make(map[string]bool, len(elements))
+	for i, elem := range elements {
+		if jsonKind(elem) != "object" {
+			return nil, false
+		}
+		members, err := c.objectMembers(elem)
+		if err != nil {
+			return nil, false
+		}
+		switch jsonKind(members[c.keyField]) {
+		case "string", "number", "boolean":
+		default:
+			return nil, false
+		}
+		encoded, err := json.Marshal(members[c.keyField])
+		if err != nil || seen[string(encoded)] {
+			return nil, false
+		}
+		seen[string(encoded)] = true
+		keys[i] = string(encoded)
+	}
+	return keys, true
+}
+
+// diffValue converts data like convertValue, except that non-empty typed
+// slices of ElementKeyer elements keep their Go form at any depth, so the
+// array diff can still match their elements by key.
+func diffValue(data any) any {
+	switch data.(type) {
+	case nil, string, float64, float32, int, int64, int32, bool, map[string]any, []any, *OrderedObject:
+		return convertValue(data)
+	}
+	if normalized, ok := normalizeSpecialValue(data); ok {
+		return normalized
+	}
+
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		m, _ := toMapWith(data, diffValue)
+		return m
+	case reflect.Slice, reflect.Array:
+		if _, ok := elementKeys(data); ok && v.Len() > 0 {
+			return data
+		}
+		result := make([]any, v.Len())
+		for i := range result {
+			result[i] = diffValue(v.Index(i).Interface())
+		}
+		return result
+	case reflect.Map:
+		if v.Type().Key().Kind() == reflect.String {
+			result := make(map[string]any, v.Len())
+			for _, key := range v.MapKeys() {
+				result[key.String()] = diffValue(v.MapIndex(key).Interface())
+			}
+			return result
+		}
+	case reflect.Invalid, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.Chan, reflect.Func, reflect.Interface, reflect.Pointer, reflect.String, reflect.UnsafePointer:
+	}
+	return convertValue(data)
+}
+
+// keyedArrayDiff diffs arrays whose elements are identified by the given
+// keys. Elements whose key disappeared are removed first, from the end. The
+// elements on a longest common subsequence of the remaining keys stay put;
+// every other element is moved (or, if new, added) directly in front of its
+// successor in after, working from the last element to the first, which
+// leaves the keys in after's order. Elements present on both sides are then
+// diffed at their final index.
+func (c *diffConfig) keyedArrayDiff(basePath string, before, after []any, beforeKeys, afterKeys []string) []Patch {
+	inAfter := make(map[string]bool, len(afterKeys))
+	for _, key := range afterKeys {
+		inAfter[key] = true
+	}
+
+	var patches []Patch
+	for i := len(beforeKeys) - 1; i >= 0; i-- {
+		if !inAfter[beforeKeys[i]] {
+			patches = append(patches, Patch{Op: "remove", Path: arrayPath(basePath, i)})
+		}
+	}
+	removals := len(patches)
+
+	current := make([]string, 0, len(beforeKeys))
+	beforeByKey := make(map[string]any, len(beforeKeys))
+	for i, key := range beforeKeys {
+		if inAfter[key] {
+			current = append(current, key)
+			beforeByKey[key] = before[i]
+		}
+	}
+	kept := make([]string, 0, len(afterKeys))
+	for _, key := range afterKeys {
+		if _, ok := beforeByKey[key]; ok {
+			kept = append(kept, key)
+		}
+	}
+	stable := make(map[string]bool, len(kept))
+	for _, edit := range Diff(current, kept, func(a, b string) bool { return a == b }) {
+		if edit.Kind == Keep {
+			stable[current[edit.OldIndex]] = true
+		}
+	}
+
+	moves, additions := 0, 0
+	for i := len(afterKeys) - 1; i >= 0; i-- {
+		key := afterKeys[i]
+		if stable[key] {
+			continue
+		}
+		to := len(current)
+		if i+1 < len(afterKeys) {
+			to = slices.Index(current, afterKeys[i+1])
+		}
+		if from := slices.Index(current, key); from >= 0 {
+			if from < to {
+				to--
+			}
+			if from != to {
+				patches = append(patches, Patch{Op: "move", From: arrayPath(basePath, from), Path: arrayPath(basePath, to)})
+				moves++
+			}
+			current = slices.Delete(current, from, from+1)
+		} else {
+			patches = append(patches, Patch{Op: "add", Path: arrayPath(basePath, to), Value: snapshotValue(after[i])})
+			additions++
+		}
+		current = slices.Insert(current, to, key)
+	}
+
+	updated := 0
+	for i, key := range afterKeys {
+		if old, ok := beforeByKey[key]; ok && !c.deepEqualFiltered(old, after[i]) {
+			patches = c.diffMember(patches, basePath, strconv.Itoa(i), old, true, after[i])
+			updated++
+		}
+	}
+	c.recordArrayStats(len(stable), removals, additions, updated, moves)
+	return patches
+}
@@ -0,0 +1,218 @@
+package jsonpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type keyedTask struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Done  bool   `json:"done"`
+}
+
+func (t keyedTask) ElementKey() string {
+	return t.ID
+}
+
+type keyedNote struct {
+	ID   int    `json:"id"`
+	Text string `json:"text"`
+}
+
+func (n *keyedNote) ElementKey() string {
+	return fmt.Sprint(n.ID)
+}
+
+type keyedNotes struct {
+	Notes []*keyedNote `json:"notes"`
+}
+
+type keyedBoard struct {
+	Name  string      `json:"name"`
+	Tasks []keyedTask `json:"tasks"`
+}
+
+func TestShouldMoveElementsByKeyGivenElementKeyer(t *testing.T) {
+	// Arrange
+	before := keyedBoard{Tasks: []keyedTask{
+		{ID: "a", Title: "Write"}, {ID: "b", Title: "Review"}, {ID: "c", Title: "Ship"},
+	}}
+	after := keyedBoard{Tasks: []keyedTask{
+		{ID: "c", Title: "Ship"}, {ID: "a", Title: "Write"}, {ID: "b", Title: "Review"},
+	}}
+
+	// Act
+	patches, err := GeneratePatch(before, after, "")
+	mapPatches, mapErr := GeneratePatch(map[string]any{"tasks": before.Tasks}, map[string]any{"tasks": after.Tasks}, "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Patch{{Op: "move", From: "/tasks/2", Path: "/tasks/0"}}, patches)
+	require.NoError(t, mapErr)
+	assert.Equal(t, patches, mapPatches)
+	result, err := ApplyPatch(before, patches)
+	require.NoError(t, err)
+	expected, err := toMap(after)
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestShouldDiffChangedElementInPlaceGivenElementKeyer(t *testing.T) {
+	// Arrange
+	before := keyedBoard{Name: "sprint", Tasks: []keyedTask{
+		{ID: "a", Title: "Write"}, {ID: "b", Title: "Review"}, {ID: "c", Title: "Ship"},
+	}}
+	after := keyedBoard{Name: "sprint", Tasks: []keyedTask{
+		{ID: "b", Title: "Review", Done: true}, {ID: "d", Title: "Celebrate"}, {ID: "a", Title: "Write"},
+	}}
+
+	// Act
+	patches, err := GeneratePatch(before, after, "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Patch{
+		{Op: "remove", Path: "/tasks/2"},
+		{Op: "move", From: "/tasks/0", Path: "/tasks/1"},
+		{Op: "add", Path: "/tasks/1", Value: map[string]any{"id": "d", "title": "Celebrate", "done": false}},
+		{Op: "replace", Path: "/tasks/0/done", Value: true},
+	}, patches)
+	result, err := ApplyPatch(before, patches)
+	require.NoError(t, err)
+	expected, err := toMap(after)
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestShouldRoundTripEveryReorderGivenElementKeyer(t *testing.T) {
+	base := []*keyedNote{{ID: 1, Text: "one"}, {ID: 2, Text: "two"}, {ID: 3, Text: "three"}, {ID: 4, Text: "four"}}
+	for _, order := range permutations([]int{0, 1, 2, 3}) {
+		t.Run(fmt.Sprint(order), func(t *testing.T) {
+			// Arrange
+			reordered := make([]*keyedNote, 0, len(order)+1)
+			for _, i := range order[1:] {
+				reordered = append(reordered, base[i])
+			}
+			reordered = append(reordered, &keyedNote{ID: 5, Text: "five"})
+			before := keyedNotes{Notes: base}
+			after := keyedNotes{Notes: reordered}
+
+			// Act
+			patches, err := GeneratePatch(before, after, "")
+
+			// Assert
+			require.NoError(t, err)
+			for _, op := range patches {
+				assert.NotEqual(t, "replace", op.Op)
+			}
+			result, err := ApplyPatch(before, patches)
+			require.NoError(t, err)
+			expected, err := toMap(after)
+			require.NoError(t, err)
+			assert.Equal(t, expected, result)
+		})
+	}
+}
+
+func TestShouldFallBackToLCSGivenDuplicateElementKeys(t *testing.T) {
+	// Arrange
+	before := map[string]any{"tasks": []keyedTask{{ID: "a", Title: "x"}, {ID: "a", Title: "y"}}}
+	after := map[string]any{"tasks": []keyedTask{{ID: "a", Title: "y"}}}
+
+	// Act
+	patches, err := GeneratePatch(before, after, "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Patch{{Op: "remove", Path: "/tasks/0"}}, patches)
+}
+
+func permutations(values []int) [][]int {
+	if len(values) <= 1 {
+		return [][]int{append([]int(nil), values...)}
+	}
+	var result [][]int
+	for i := range values {
+		rest := append(append([]int(nil), values[:i]...), values[i+1:]...)
+		for _, perm := range permutations(rest) {
+			result = append(result, append([]int{values[i]}, perm...))
+		}
+	}
+	return result
+}
+
+func TestShouldMoveAndEditElementGivenElementKeyField(t *testing.T) {
+	// Arrange
+	before := map[string]any{"users": []any{
+		map[string]any{"id": 1, "name": "Ann", "role": "dev"},
+		map[string]any{"id": 2, "name": "Bob", "role": "ops"},
+		map[string]any{"id": 3, "name": "Cy", "role": "qa"},
+	}}
+	after := map[string]any{"users": []any{
+		map[string]any{"id": 3, "name": "Cy", "role": "lead"},
+		map[string]any{"id": 1, "name": "Ann", "role": "dev"},
+		map[string]any{"id": 2, "name": "Bob", "role": "ops"},
+	}}
+
+	// Act
+	patches, err := GeneratePatch(before, after, "", WithElementKeyField("id"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Patch{
+		{Op: "move", From: "/users/2", Path: "/users/0"},
+		{Op: "replace", Path: "/users/0/role", Value: "lead"},
+	}, patches)
+	result, err := ApplyPatch(before, patches)
+	require.NoError(t, err)
+	assert.Equal(t, after, result)
+}
+
+func TestShouldUseLCSGivenElementKeyFieldNotApplicable(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []any
+		after  []any
+	}{
+		{
+			name:   "missing key",
+			before: []any{map[string]any{"id": 1}, map[string]any{"name": "x"}},
+			after:  []any{map[string]any{"name": "x"}, map[string]any{"id": 1}},
+		},
+		{
+			name:   "duplicate key",
+			before: []any{map[string]any{"id": 1, "v": 1}, map[string]any{"id": 1, "v": 2}},
+			after:  []any{map[string]any{"id": 1, "v": 2}, map[string]any{"id": 1, "v": 3}},
+		},
+		{
+			name:   "object key",
+			before: []any{map[string]any{"id": map[string]any{"a": 1}}},
+			after:  []any{map[string]any{"id": map[string]any{"a": 2}}},
+		},
+		{
+			name:   "scalar elements",
+			before: []any{1, 2, 3},
+			after:  []any{3, 1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			before := map[string]any{"list": tt.before}
+			after := map[string]any{"list": tt.after}
+
+			// Act
+			keyed, err := GeneratePatch(before, after, "", WithElementKeyField("id"))
+			require.NoError(t, err)
+			plain, err := GeneratePatch(before, after, "")
+			require.NoError(t, err)
+
+			// Assert
+			assert.Equal(t, plain, keyed)
+		})
+	}
+}
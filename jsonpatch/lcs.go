@@ -0,0 +1,119 @@
+package jsonpatch
+
+// EditKind identifies the kind of an Edit.
+type EditKind int
+
+const (
+	// Keep leaves before[OldIndex] in place as after[NewIndex].
+	Keep EditKind = iota
+	// Delete removes before[OldIndex].
+	Delete
+	// Insert adds after[NewIndex].
+	Insert
+)
+
+func (k EditKind) String() string {
+	switch k {
+	case Keep:
+		return "keep"
+	case Delete:
+		return "delete"
+	case Insert:
+		return "insert"
+	default:
+		return "unknown"
+	}
+}
+
+// Edit is a single step of an edit script produced by Diff. OldIndex is the
+// position in before (-1 for Insert) and NewIndex the position in after (-1
+// for Delete).
+type Edit struct {
+	Kind     EditKind
+	OldIndex int
+	NewIndex int
+}
+
+// Diff computes an edit script turning before into after from a longest
+// common subsequence of the two slices, where equal decides element
+// identity. Edits are ordered by position: reading the Keep and Delete edits
+// yields before, and the Keep and Insert edits yields after. When several
+// alignments are equally long, deletions are preferred before insertions.
+//
+// Diff runs in O(len(before)*len(after)) time and calls equal once per pair
+// of elements; trim common prefixes and suffixes first for large inputs.
+func Diff[T any](before, after []T, equal func(a, b T) bool) []Edit {
+	m, n := len(before), len(after)
+
+	// Build the LCS lengths using two rows and record the direction taken at
+	// each cell: 0 = diagonal (match), 1 = skip before[i], 2 = skip after[j].
+	curr := make([]int, n+1)
+	prev := make([]int, n+1)
+	dir := make([]byte, (m+1)*(n+1))
+	for i := m - 1; i >= 0; i-- {
+		curr, prev = prev, curr
+		for j := n - 1; j >= 0; j-- {
+			switch {
+			case equal(before[i], after[j]):
+				curr[j] = prev[j+1] + 1
+				dir[i*(n+1)+j] = 0
+			case prev[j] >= curr[j+1]:
+				curr[j] = prev[j]
+				dir[i*(n+1)+j] = 1
+			default:
+				curr[j] = curr[j+1]
+				dir[i*(n+1)+j] = 2
+			}
+		}
+	}
+
+	edits := make([]Edit, 0, m+n)
+	i, j := 0, 0
+	for i < m && j < n {
+		switch dir[i*(n+1)+j] {
+		case 0:
+			edits = append(edits, Edit{Kind: Keep, OldIndex: i, NewIndex: j})
+			i++
+			j++
+		case 1:
+			edits = append(edits, Edit{Kind: Delete, OldIndex: i, NewIndex: -1})
+			i++
+		default:
+			edits = append(edits, Edit{Kind: Insert, OldIndex: -1, NewIndex: j})
+			j++
+		}
+	}
+	for ; i < m; i++ {
+		edits = append(edits, Edit{Kind: Delete, OldIndex: i, NewIndex: -1})
+	}
+	for ; j < n; j++ {
+		edits = append(edits, Edit{Kind: Insert, OldIndex: -1, NewIndex: j})
+	}
+	return edits
+}
+
+// Pair is one row of an ArrayAlignment: Before and After are the indices of
+// the aligned elements, with -1 on the side where the element is absent.
+// Before is -1 for an added element and After is -1 for a removed one.
+type Pair struct {
+	Before int
+	After  int
+}
+
+// ArrayAlignment aligns two JSON arrays for side-by-side rendering, pairing
+// each element of before with its counterpart in after from the same longest
+// common subsequence Diff computes. Rows are in display order: every index of
+// both arrays appears exactly once, and removed rows come before added rows
+// at the same position. A nil equal compares elements by JSON semantics, as
+// GeneratePatch does.
+func ArrayAlignment(before, after []any, equal func(a, b any) bool) []Pair {
+	if equal == nil {
+		equal = jsonEqual
+	}
+	edits := Diff(before, after, equal)
+	pairs := make([]Pair, len(edits))
+	for i, edit := range edits {
+		pairs[i] = Pair{Before: edit.OldIndex, After: edit.NewIndex}
+	}
+	return pairs
+}
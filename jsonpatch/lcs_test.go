@@ -0,0 +1,111 @@
+package jsonpatch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// applyEdits rebuilds after from before and the edit script.
+func applyEdits[T any](before, after []T, edits []Edit) []T {
+	result := make([]T, 0, len(after))
+	for _, edit := range edits {
+		switch edit.Kind {
+		case Keep:
+			result = append(result, before[edit.OldIndex])
+		case Insert:
+			result = append(result, after[edit.NewIndex])
+		case Delete:
+		}
+	}
+	return result
+}
+
+func TestShouldDiffIntSlicesGivenInsertionsAndDeletions(t *testing.T) {
+	// Arrange
+	before := []int{1, 2, 3, 4}
+	after := []int{1, 3, 4, 5}
+
+	// Act
+	edits := Diff(before, after, func(a, b int) bool { return a == b })
+
+	// Assert
+	assert.Equal(t, []Edit{
+		{Kind: Keep, OldIndex: 0, NewIndex: 0},
+		{Kind: Delete, OldIndex: 1, NewIndex: -1},
+		{Kind: Keep, OldIndex: 2, NewIndex: 1},
+		{Kind: Keep, OldIndex: 3, NewIndex: 2},
+		{Kind: Insert, OldIndex: -1, NewIndex: 3},
+	}, edits)
+}
+
+func TestShouldDiffStringSlicesGivenCustomEquality(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []string
+		after  []string
+		keeps  int
+	}{
+		{name: "both empty", before: nil, after: nil, keeps: 0},
+		{name: "all inserted", before: nil, after: []string{"a", "b"}, keeps: 0},
+		{name: "all deleted", before: []string{"a", "b"}, after: nil, keeps: 0},
+		{name: "case-insensitive match", before: []string{"Alpha", "beta", "Gamma"}, after: []string{"alpha", "delta", "GAMMA"}, keeps: 2},
+		{name: "reordered", before: []string{"x", "y", "z"}, after: []string{"z", "x", "y"}, keeps: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			edits := Diff(tt.before, tt.after, strings.EqualFold)
+
+			// Assert
+			keeps := 0
+			for _, edit := range edits {
+				if edit.Kind == Keep {
+					keeps++
+					assert.True(t, strings.EqualFold(tt.before[edit.OldIndex], tt.after[edit.NewIndex]))
+				}
+			}
+			assert.Equal(t, tt.keeps, keeps)
+			assert.Len(t, edits, len(tt.before)+len(tt.after)-keeps)
+			rebuilt := applyEdits(tt.before, tt.after, edits)
+			assert.Len(t, rebuilt, len(tt.after))
+			for i := range rebuilt {
+				assert.True(t, strings.EqualFold(tt.after[i], rebuilt[i]))
+			}
+		})
+	}
+}
+
+func TestShouldAlignArraysGivenEditedArray(t *testing.T) {
+	// Arrange
+	before := []any{"intro", "draft", map[string]any{"id": 1.0}, "outro"}
+	after := []any{"intro", map[string]any{"id": 1}, "summary", "outro", "appendix"}
+
+	// Act
+	pairs := ArrayAlignment(before, after, nil)
+
+	// Assert
+	assert.Equal(t, []Pair{
+		{Before: 0, After: 0},
+		{Before: 1, After: -1},
+		{Before: 2, After: 1},
+		{Before: -1, After: 2},
+		{Before: 3, After: 3},
+		{Before: -1, After: 4},
+	}, pairs)
+}
+
+func TestShouldAlignArraysGivenCustomEquality(t *testing.T) {
+	// Arrange
+	before := []any{"A", "b"}
+	after := []any{"a", "B"}
+
+	// Act
+	pairs := ArrayAlignment(before, after, func(a, b any) bool {
+		return strings.EqualFold(a.(string), b.(string))
+	})
+
+	// Assert
+	assert.Equal(t, []Pair{{Before: 0, After: 0}, {Before: 1, After: 1}}, pairs)
+}
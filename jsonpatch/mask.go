@@ -0,0 +1,110 @@
+package jsonpatch
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GeneratePatchFromMask builds a patch that sets only the fields named by a
+// protobuf-style field mask, as gRPC update APIs do: each path (dot-separated
+// JSON member names such as "profile.displayName") becomes an add of the
+// value found at that path in after, which creates the member or replaces
+// it. A path ending at an array element becomes a replace instead, since an
+// add would insert beside the existing element; apply with WithUpsert when
+// the target array may be shorter. A path absent from after becomes a
+// remove, clearing the field; apply with WithTolerant when the target may
+// not have it either. A "*" segment
+// matches every member of an object, or every element of an array, present
+// in after, and numeric segments index arrays. after is read in its JSON
+// form, so struct fields are addressed by their json names. Operations
+// follow the order of paths, with wildcard matches in key order.
+func GeneratePatchFromMask(after any, paths []string) ([]Patch, error) {
+	doc, err := jsonDocument(after)
+	if err != nil {
+		return nil, err
+	}
+	var patches []Patch
+	for _, maskPath := range paths {
+		segments := strings.Split(maskPath, ".")
+		if slices.Contains(segments, "") {
+			return nil, fmt.Errorf("invalid mask path %q: contains empty component", maskPath)
+		}
+		patches, err = maskPatches(patches, doc, "", segments, "add")
+		if err != nil {
+			return nil, fmt.Errorf("mask path %q: %w", maskPath, err)
+		}
+	}
+	return patches, nil
+}
+
+// maskPatches appends the operations for the mask segments below value,
+// which sits at pointer in after. op is the operation that sets value: add
+// for object members and replace for array elements.
+func maskPatches(patches []Patch, value any, pointer string, segments []string, op string) ([]Patch, error) {
+	if len(segments) == 0 {
+		return append(patches, Patch{Op: op, Path: pointer, Value: value}), nil
+	}
+	segment, rest := segments[0], segments[1:]
+	switch node := value.(type) {
+	case map[string]any:
+		if segment == "*" {
+			keys := make([]string, 0, len(node))
+			for key := range node {
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+			var err error
+			for _, key := range keys {
+				if patches, err = maskPatches(patches, node[key], pointer+"/"+escapePathSegment(key), rest, "add"); err != nil {
+					return nil, err
+				}
+			}
+			return patches, nil
+		}
+		child, ok := node[segment]
+		if !ok {
+			return maskRemove(patches, pointer, segments), nil
+		}
+		return maskPatches(patches, child, pointer+"/"+escapePathSegment(segment), rest, "add")
+	case []any:
+		if segment == "*" {
+			var err error
+			for i, item := range node {
+				if patches, err = maskPatches(patches, item, arrayPath(pointer, i), rest, "replace"); err != nil {
+					return nil, err
+				}
+			}
+			return patches, nil
+		}
+		index, err := strconv.Atoi(segment)
+		if err != nil || !isArrayIndex(segment) {
+			return nil, fmt.Errorf("%q is not an array index at %q", segment, pointer)
+		}
+		if index >= len(node) {
+			return maskRemove(patches, pointer, segments), nil
+		}
+		return maskPatches(patches, node[index], arrayPath(pointer, index), rest, "replace")
+	case nil:
+		return maskRemove(patches, pointer, segments), nil
+	default:
+		return nil, fmt.Errorf("cannot descend into %T at %q", value, pointer)
+	}
+}
+
+// maskRemove appends the remove clearing a mask path that after does not
+// contain; a path whose remaining segments hold a wildcard names nothing to
+// clear.
+func maskRemove(patches []Patch, pointer string, segments []string) []Patch {
+	if slices.Contains(segments, "*") {
+		return patches
+	}
+	var builder strings.Builder
+	builder.WriteString(pointer)
+	for _, segment := range segments {
+		builder.WriteByte('/')
+		builder.WriteString(escapePathSegment(segment))
+	}
+	return append(patches, Patch{Op: "remove", Path: builder.String()})
+}
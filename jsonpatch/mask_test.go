@@ -0,0 +1,141 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type maskProfile struct {
+	DisplayName string `json:"displayName"`
+	Bio         string `json:"bio"`
+}
+
+type maskUser struct {
+	ID      string      `json:"id"`
+	Email   string      `json:"email"`
+	Profile maskProfile `json:"profile"`
+}
+
+func TestShouldPatchOnlyMaskedPathsGivenTwoPathMask(t *testing.T) {
+	// Arrange
+	before := map[string]any{
+		"id":      "u1",
+		"email":   "old@example.com",
+		"profile": map[string]any{"displayName": "Old", "bio": "keep me"},
+	}
+	after := maskUser{ID: "ignored", Email: "new@example.com", Profile: maskProfile{DisplayName: "New", Bio: "ignored"}}
+
+	// Act
+	patch, err := GeneratePatchFromMask(after, []string{"email", "profile.displayName"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Patch{
+		{Op: "add", Path: "/email", Value: "new@example.com"},
+		{Op: "add", Path: "/profile/displayName", Value: "New"},
+	}, patch)
+	result, err := ApplyPatch(before, patch)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":      "u1",
+		"email":   "new@example.com",
+		"profile": map[string]any{"displayName": "New", "bio": "keep me"},
+	}, result)
+}
+
+func TestShouldExpandWildcardsAndClearMissingPathsGivenMask(t *testing.T) {
+	// Arrange
+	after := map[string]any{
+		"items": []any{
+			map[string]any{"qty": 1, "sku": "a"},
+			map[string]any{"qty": 2, "sku": "b"},
+		},
+		"labels": map[string]any{"b/c": map[string]any{"color": "red"}, "a": map[string]any{"color": "blue"}},
+		"note":   nil,
+	}
+
+	// Act
+	patch, err := GeneratePatchFromMask(after, []string{"items.*.qty", "labels.*.color", "items.1.sku", "nickname", "note.text", "gone.*.x"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Patch{
+		{Op: "add", Path: "/items/0/qty", Value: float64(1)},
+		{Op: "add", Path: "/items/1/qty", Value: float64(2)},
+		{Op: "add", Path: "/labels/a/color", Value: "blue"},
+		{Op: "add", Path: "/labels/b~1c/color", Value: "red"},
+		{Op: "add", Path: "/items/1/sku", Value: "b"},
+		{Op: "remove", Path: "/nickname"},
+		{Op: "remove", Path: "/note/text"},
+	}, patch)
+}
+
+func TestShouldReplaceArrayElementsGivenMaskEndingAtArray(t *testing.T) {
+	tests := []struct {
+		name   string
+		before map[string]any
+		after  map[string]any
+		path   string
+		want   map[string]any
+	}{
+		{
+			name:   "wildcard",
+			before: map[string]any{"tags": []any{"a", "b"}},
+			after:  map[string]any{"tags": []any{"x", "y"}},
+			path:   "tags.*",
+			want:   map[string]any{"tags": []any{"x", "y"}},
+		},
+		{
+			name:   "index",
+			before: map[string]any{"items": []any{"a", "b", "c"}},
+			after:  map[string]any{"items": []any{"a", "z", "c"}},
+			path:   "items.1",
+			want:   map[string]any{"items": []any{"a", "z", "c"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			patch, err := GeneratePatchFromMask(tt.after, []string{tt.path})
+			require.NoError(t, err)
+
+			// Act
+			result, err := ApplyPatch(tt.before, patch)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+			for _, op := range patch {
+				assert.Equal(t, "replace", op.Op)
+			}
+		})
+	}
+}
+
+func TestShouldRejectInvalidMaskPathGivenMask(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "empty segment", path: "profile..bio", want: "contains empty component"},
+		{name: "scalar parent", path: "email.domain", want: `cannot descend into string at "/email"`},
+		{name: "non-index", path: "tags.first", want: `"first" is not an array index`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			after := map[string]any{"email": "a@example.com", "tags": []any{"x"}}
+
+			// Act
+			patch, err := GeneratePatchFromMask(after, []string{tt.path})
+
+			// Assert
+			require.Error(t, err)
+			assert.Nil(t, patch)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
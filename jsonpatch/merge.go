@@ -0,0 +1,95 @@
+package jsonpatch
+
+import "strings"
+
+// Conflict describes a pair of operations from two independent patches that
+// touch overlapping locations with different effects. Path is the location
+// from A that overlaps with B.
+type Conflict struct {
+	Path string
+	A    Patch
+	B    Patch
+}
+
+// MergePatches merges two patches produced independently against the same
+// base document. Operations are kept in order, a's before b's. Two
+// operations overlap when a location one touches (its path, or its from for
+// move and copy) equals or contains a location the other touches. Overlapping
+// operations that are identical are kept once; otherwise both are dropped
+// from the result and reported as a Conflict. Appends to the same array
+// (paths ending in "/-") never conflict with each other.
+//
+// Array indices are compared literally: operations at different indices of
+// the same array are treated as independent even though an insert or remove
+// in one patch may shift the elements the other addresses.
+func MergePatches(a, b []Patch) ([]Patch, []Conflict) {
+	conflictedA := make([]bool, len(a))
+	conflictedB := make([]bool, len(b))
+	duplicateB := make([]bool, len(b))
+	var conflicts []Conflict
+
+	for i, opA := range a {
+		for j, opB := range b {
+			path, overlaps := patchesOverlap(opA, opB)
+			if !overlaps {
+				continue
+			}
+			if samePatch(opA, opB) {
+				duplicateB[j] = true
+				continue
+			}
+			conflictedA[i] = true
+			conflictedB[j] = true
+			conflicts = append(conflicts, Conflict{Path: path, A: opA, B: opB})
+		}
+	}
+
+	merged := make([]Patch, 0, len(a)+len(b))
+	for i, op := range a {
+		if !conflictedA[i] {
+			merged = append(merged, op)
+		}
+	}
+	for j, op := range b {
+		if !conflictedB[j] && !duplicateB[j] {
+			merged = append(merged, op)
+		}
+	}
+	return merged, conflicts
+}
+
+// patchesOverlap reports whether any location touched by a overlaps one
+// touched by b, returning the overlapping location of a.
+func patchesOverlap(a, b Patch) (string, bool) {
+	for _, pathA := range touchedPaths(a) {
+		for _, pathB := range touchedPaths(b) {
+			if strings.HasSuffix(pathA, "/-") && pathA == pathB {
+				continue
+			}
+			if pathContains(pathA, pathB) || pathContains(pathB, pathA) {
+				return pathA, true
+			}
+		}
+	}
+	return "", false
+}
+
+func touchedPaths(op Patch) []string {
+	if op.Op == "move" || op.Op == "copy" {
+		return []string{op.Path, op.From}
+	}
+	return []string{op.Path}
+}
+
+// pathContains reports whether the JSON Pointer outer equals inner or is
+// one of its ancestors.
+func pathContains(outer, inner string) bool {
+	if outer == inner || outer == "" {
+		return true
+	}
+	return strings.HasPrefix(inner, outer+"/")
+}
+
+func samePatch(a, b Patch) bool {
+	return a.Op == b.Op && a.Path == b.Path && a.From == b.From && jsonEqual(a.Value, b.Value)
+}
@@ -0,0 +1,56 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldConcatenatePatchesGivenNonOverlappingOps(t *testing.T) {
+	// Arrange
+	a := []Patch{
+		{Op: "replace", Path: "/name", Value: "Alice"},
+		{Op: "add", Path: "/tags/-", Value: "x"},
+	}
+	b := []Patch{
+		{Op: "replace", Path: "/email", Value: "a@example.com"},
+		{Op: "add", Path: "/tags/-", Value: "y"},
+		{Op: "replace", Path: "/name", Value: "Alice"},
+	}
+
+	// Act
+	merged, conflicts := MergePatches(a, b)
+
+	// Assert
+	assert.Empty(t, conflicts)
+	assert.Equal(t, []Patch{
+		{Op: "replace", Path: "/name", Value: "Alice"},
+		{Op: "add", Path: "/tags/-", Value: "x"},
+		{Op: "replace", Path: "/email", Value: "a@example.com"},
+		{Op: "add", Path: "/tags/-", Value: "y"},
+	}, merged)
+}
+
+func TestShouldReportConflictsGivenOverlappingOps(t *testing.T) {
+	// Arrange
+	a := []Patch{
+		{Op: "replace", Path: "/name", Value: "Alice"},
+		{Op: "remove", Path: "/address"},
+		{Op: "replace", Path: "/age", Value: 30},
+	}
+	b := []Patch{
+		{Op: "replace", Path: "/name", Value: "Alicia"},
+		{Op: "replace", Path: "/address/city", Value: "Paris"},
+		{Op: "move", From: "/nickname", Path: "/alias"},
+	}
+
+	// Act
+	merged, conflicts := MergePatches(a, b)
+
+	// Assert
+	require.Len(t, conflicts, 2)
+	assert.Equal(t, Conflict{Path: "/name", A: a[0], B: b[0]}, conflicts[0])
+	assert.Equal(t, Conflict{Path: "/address", A: a[1], B: b[1]}, conflicts[1])
+	assert.Equal(t, []Patch{a[2], b[2]}, merged)
+}
@@ -0,0 +1,73 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// GeneratePatchNDJSON diffs paired documents read from two newline-delimited
+// JSON streams. The n-th document of before is diffed against the n-th
+// document of after, and each patch is written to out as a JSON array on its
+// own line ("[]" when the pair is equal). Documents must be JSON objects;
+// WithDecodeStrict additionally rejects documents with duplicate keys.
+// It fails when either stream contains invalid JSON or when one stream ends
+// before the other; patches for the pairs before the failure have already
+// been written.
+func GeneratePatchNDJSON(before, after io.Reader, out io.Writer, opts ...DiffOption) error {
+	beforeDec := json.NewDecoder(before)
+	afterDec := json.NewDecoder(after)
+	enc := json.NewEncoder(out)
+	strict := newDiffConfig(opts).strict
+
+	for record := 1; ; record++ {
+		beforeDoc, beforeErr := decodeRecord(beforeDec, strict)
+		afterDoc, afterErr := decodeRecord(afterDec, strict)
+
+		beforeDone := errors.Is(beforeErr, io.EOF)
+		afterDone := errors.Is(afterErr, io.EOF)
+		switch {
+		case beforeDone && afterDone:
+			return nil
+		case beforeDone:
+			return fmt.Errorf("ndjson streams out of sync: before ended at record %d but after continues", record)
+		case afterDone:
+			return fmt.Errorf("ndjson streams out of sync: after ended at record %d but before continues", record)
+		case beforeErr != nil:
+			return fmt.Errorf("record %d: decode before: %w", record, beforeErr)
+		case afterErr != nil:
+			return fmt.Errorf("record %d: decode after: %w", record, afterErr)
+		}
+
+		patches, err := GeneratePatch(beforeDoc, afterDoc, "", opts...)
+		if err != nil {
+			return fmt.Errorf("record %d: %w", record, err)
+		}
+		if patches == nil {
+			patches = []Patch{}
+		}
+		if err := enc.Encode(patches); err != nil {
+			return fmt.Errorf("record %d: write patch: %w", record, err)
+		}
+	}
+}
+
+// decodeRecord reads the next document from dec, rejecting duplicate keys
+// when strict is set.
+func decodeRecord(dec *json.Decoder, strict bool) (map[string]any, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	if strict {
+		if err := checkDuplicateKeys(raw); err != nil {
+			return nil, err
+		}
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
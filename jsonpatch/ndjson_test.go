@@ -0,0 +1,58 @@
+package jsonpatch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldWriteOnePatchPerLineGivenPairedNDJSONStreams(t *testing.T) {
+	// Arrange
+	before := strings.NewReader(`{"id":1,"name":"a"}
+{"id":2,"tags":["x"]}
+{"id":3}
+`)
+	after := strings.NewReader(`{"id":1,"name":"b"}
+{"id":2,"tags":["x","y"]}
+{"id":3}
+`)
+	var out bytes.Buffer
+
+	// Act
+	err := GeneratePatchNDJSON(before, after, &out)
+
+	// Assert
+	require.NoError(t, err)
+	var lines [][]Patch
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var patches []Patch
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &patches))
+		lines = append(lines, patches)
+	}
+	assert.Equal(t, [][]Patch{
+		{{Op: "replace", Path: "/name", Value: "b"}},
+		{{Op: "add", Path: "/tags/1", Value: "y"}},
+		{},
+	}, lines)
+}
+
+func TestShouldFailGivenNDJSONStreamsOfDifferentLength(t *testing.T) {
+	// Arrange
+	before := strings.NewReader("{\"a\":1}\n{\"a\":2}\n")
+	after := strings.NewReader("{\"a\":1}\n")
+	var out bytes.Buffer
+
+	// Act
+	err := GeneratePatchNDJSON(before, after, &out)
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "after ended at record 2")
+	assert.Equal(t, "[]\n", out.String())
+}
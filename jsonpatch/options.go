@@ -0,0 +1,580 @@
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// DiffOption configures how GeneratePatch produces operations.
+type DiffOption func(*diffConfig)
+
+// diffConfig holds the settings applied while generating patches.
+type diffConfig struct {
+	annotate    func(op Patch) string
+	stats       *DiffStats
+	trimStrings bool
+	normalize   bool
+	keyOrder    bool
+	ignore      [][]string
+	maxDepth    int
+	strict      bool
+	subtree     int
+	copies      bool
+	typeChange  bool
+	foldKeys    bool
+	keyField    string
+	similarity  float64
+	epsilon     float64
+	equalFunc   func(a, b any) (equal, ok bool)
+
+	// root is the basePath passed to GeneratePatch; ignore patterns are
+	// matched against paths relative to it.
+	root string
+
+	// equality is derived from the options above; nil means exact
+	// comparison.
+	equality *valueEquality
+
+	// depth is the current object nesting level; err records the first
+	// depth violation so nested diffs can unwind.
+	depth int
+	err   error
+}
+
+func newDiffConfig(opts []DiffOption) *diffConfig {
+	cfg := &diffConfig{maxDepth: DefaultMaxDepth}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+	var stringsEqual func(a, b string) bool
+	switch {
+	case cfg.trimStrings && cfg.normalize:
+		stringsEqual = func(a, b string) bool {
+			return normalizedStringsEqual(strings.TrimSpace(a), strings.TrimSpace(b))
+		}
+	case cfg.trimStrings:
+		stringsEqual = trimmedStringsEqual
+	case cfg.normalize:
+		stringsEqual = normalizedStringsEqual
+	}
+	if stringsEqual != nil || cfg.epsilon > 0 || cfg.equalFunc != nil {
+		cfg.equality = &valueEquality{strings: stringsEqual, epsilon: cfg.epsilon, custom: cfg.equalFunc}
+	}
+	return cfg
+}
+
+// valueEquality customizes how values are compared during a diff. A nil
+// *valueEquality compares exactly.
+type valueEquality struct {
+	// strings overrides exact string comparison when non-nil.
+	strings func(a, b string) bool
+	// epsilon is the largest difference at which numbers are equal.
+	epsilon float64
+	// custom is consulted before the JSON comparison of every value pair.
+	custom func(a, b any) (equal, ok bool)
+}
+
+// DefaultMaxDepth is the default limit on how deeply GeneratePatch descends
+// into nested objects. It matches the nesting limit of encoding/json.
+const DefaultMaxDepth = 10000
+
+// ErrMaxDepthExceeded is returned (wrapped) by GeneratePatch when the
+// documents nest objects deeper than the configured maximum depth.
+var ErrMaxDepthExceeded = errors.New("jsonpatch: maximum diff depth exceeded")
+
+// WithMaxDepth limits how many levels of nested objects GeneratePatch
+// descends into before failing with ErrMaxDepthExceeded, which protects
+// servers diffing client-supplied documents. The root object is depth 1;
+// depth <= 0 disables the limit. The default is DefaultMaxDepth.
+func WithMaxDepth(depth int) DiffOption {
+	return func(c *diffConfig) {
+		c.maxDepth = depth
+	}
+}
+
+// enter descends one object level at path, reporting false once the
+// configured maximum depth is exceeded. Every call must be paired with a
+// decrement of c.depth.
+func (c *diffConfig) enter(path string) bool {
+	c.depth++
+	if c.err != nil {
+		return false
+	}
+	if c.maxDepth > 0 && c.depth > c.maxDepth {
+		c.err = fmt.Errorf("%w (%d) at %q", ErrMaxDepthExceeded, c.maxDepth, path)
+		return false
+	}
+	return true
+}
+
+// WithStrictStrings controls string comparison during diffing. Strict
+// comparison (the default) treats any difference, including leading or
+// trailing whitespace, as a change. Passing false opts into trimmed
+// comparison, where strings that differ only in surrounding whitespace are
+// considered equal and produce no operation.
+func WithStrictStrings(strict bool) DiffOption {
+	return func(c *diffConfig) {
+		c.trimStrings = !strict
+	}
+}
+
+func trimmedStringsEqual(a, b string) bool {
+	return strings.TrimSpace(a) == strings.TrimSpace(b)
+}
+
+// WithNormalizeUnicode compares string values by their Unicode NFC form, so
+// canonically equivalent text, such as "é" written as U+00E9 or as "e"
+// followed by U+0301, produces no operation when it is merely re-encoded.
+// Changed strings are still emitted exactly as they appear in the after
+// document, and object keys are compared as written. It combines with
+// WithStrictStrings(false).
+func WithNormalizeUnicode() DiffOption {
+	return func(c *diffConfig) {
+		c.normalize = true
+	}
+}
+
+// WithEpsilon treats numbers whose difference is at most epsilon as equal,
+// so floating-point noise such as 0.1+0.2 against 0.3 produces no
+// operation. It applies to numbers at any depth, including array elements
+// matched during alignment. epsilon <= 0 (the default) compares exactly.
+func WithEpsilon(epsilon float64) DiffOption {
+	return func(c *diffConfig) {
+		c.epsilon = epsilon
+	}
+}
+
+// WithEqualFunc consults fn whenever the diff compares two values, at every
+// depth, before the default JSON comparison. When ok is true, equal decides
+// whether the values differ (equal values produce no operation); when ok is
+// false the default comparison applies. Values are in their JSON form, so
+// objects are map[string]any, arrays []any, and numbers float64 or another
+// numeric type. Use it for domain equality, for example timestamps that
+// differ only in precision.
+func WithEqualFunc(fn func(a, b any) (equal, ok bool)) DiffOption {
+	return func(c *diffConfig) {
+		c.equalFunc = fn
+	}
+}
+
+func normalizedStringsEqual(a, b string) bool {
+	return a == b || norm.NFC.String(a) == norm.NFC.String(b)
+}
+
+// WithKeyOrder makes object key order significant when both sides of an
+// object are *OrderedObject values. Besides the usual value changes, the
+// patch then contains self-targeted move operations that re-append keys so
+// ApplyPatchOrdered reproduces the new order. Without this option ordered
+// objects are diffed like ordinary maps.
+func WithKeyOrder() DiffOption {
+	return func(c *diffConfig) {
+		c.keyOrder = true
+	}
+}
+
+// WithIgnorePaths suppresses operations for object members whose path
+// matches any of the given JSON Pointer patterns, at any depth. Patterns are
+// relative to the documents being diffed (the basePath passed to
+// GeneratePatch is not part of them) and match the member itself and
+// everything beneath it, so "/meta" also ignores "/meta/updatedAt". Each
+// segment may use path.Match wildcards, e.g. "/*/updatedAt". Patterns apply
+// to object members only; operations on array elements are not filtered.
+func WithIgnorePaths(patterns ...string) DiffOption {
+	return func(c *diffConfig) {
+		for _, pattern := range patterns {
+			c.ignore = append(c.ignore, strings.Split(strings.TrimPrefix(pattern, "/"), "/"))
+		}
+	}
+}
+
+// ignored reports whether the member at pointer matches an ignore pattern.
+func (c *diffConfig) ignored(pointer string) bool {
+	if len(c.ignore) == 0 {
+		return false
+	}
+	segments := strings.Split(strings.TrimPrefix(strings.TrimPrefix(pointer, c.root), "/"), "/")
+	for _, pattern := range c.ignore {
+		if matchSegments(pattern, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments reports whether segments begin with the segments of pattern.
+func matchSegments(pattern, segments []string) bool {
+	if len(segments) < len(pattern) {
+		return false
+	}
+	for i, want := range pattern {
+		if ok, err := path.Match(want, segments[i]); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// WithDecodeStrict makes entry points that decode JSON bytes
+// (GeneratePatchNDJSON) reject documents containing duplicate object keys
+// with a *DuplicateKeyError. Without it encoding/json silently keeps the
+// last value, so the diff may not reflect what the sender meant. GeneratePatch
+// itself diffs decoded values and is unaffected.
+func WithDecodeStrict() DiffOption {
+	return func(c *diffConfig) {
+		c.strict = true
+	}
+}
+
+// WithWholeSubtreeThreshold replaces a changed nested object with a single
+// replace of its new value when diffing it would take more than n
+// operations, trading patch size for simpler application and fewer merge
+// conflicts. Nested objects are considered bottom-up, so an inner object that
+// collapses counts as one operation for its parent. The root object is never
+// collapsed, and objects with members excluded by WithIgnorePaths are left as
+// nested operations so ignored values are not overwritten. n <= 0 (the
+// default) disables collapsing.
+func WithWholeSubtreeThreshold(n int) DiffOption {
+	return func(c *diffConfig) {
+		c.subtree = n
+	}
+}
+
+// collapse returns nested, or a single replace of path with after when
+// nested exceeds the whole-subtree threshold.
+func (c *diffConfig) collapse(nested []Patch, path string, after any) []Patch {
+	if c.subtree <= 0 || len(nested) <= c.subtree || c.ignoresBeneath(path) {
+		return nested
+	}
+	return []Patch{{Op: "replace", Path: path, Value: snapshotValue(after)}}
+}
+
+// ignoresBeneath reports whether an ignore pattern may match a member
+// nested below pointer.
+func (c *diffConfig) ignoresBeneath(pointer string) bool {
+	segments := strings.Split(strings.TrimPrefix(strings.TrimPrefix(pointer, c.root), "/"), "/")
+	for _, pattern := range c.ignore {
+		if len(pattern) > len(segments) && matchSegments(pattern[:len(segments)], segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithSimilarityThreshold makes array diffs replace an element in place
+// instead of removing it and adding its successor when the two are objects
+// whose share of equal members (over the union of their keys) is at least
+// threshold, e.g. 0.5. The LCS alignment otherwise treats such elements as
+// unrelated, emitting a remove and an add. Only elements left unmatched
+// between the same pair of kept elements are paired, in order. threshold <= 0
+// (the default) disables pairing.
+func WithSimilarityThreshold(threshold float64) DiffOption {
+	return func(c *diffConfig) {
+		c.similarity = threshold
+	}
+}
+
+// similar reports whether a and b are objects similar enough to be replaced
+// in place under WithSimilarityThreshold.
+func (c *diffConfig) similar(a, b any) bool {
+	if c.similarity <= 0 {
+		return false
+	}
+	aMap, ok := a.(map[string]any)
+	if !ok {
+		return false
+	}
+	bMap, ok := b.(map[string]any)
+	if !ok {
+		return false
+	}
+	union := len(bMap)
+	equal := 0
+	for key, av := range aMap {
+		bv, ok := bMap[key]
+		if !ok {
+			union++
+			continue
+		}
+		if c.deepEqualFiltered(av, bv) {
+			equal++
+		}
+	}
+	if union == 0 {
+		return false
+	}
+	return float64(equal)/float64(union) >= c.similarity
+}
+
+// WithAnnotator labels each generated operation with the human-readable
+// reason returned by fn. The reason is stored in Patch.Reason and marshaled
+// under the non-standard "reason" key; an empty string leaves the operation
+// unannotated.
+func WithAnnotator(fn func(op Patch) string) DiffOption {
+	return func(c *diffConfig) {
+		c.annotate = fn
+	}
+}
+
+func (c *diffConfig) annotatePatches(patches []Patch) {
+	if c.annotate == nil {
+		return
+	}
+	for i := range patches {
+		patches[i].Reason = c.annotate(patches[i])
+	}
+}
+
+// ApplyOption configures how ApplyPatch and related functions apply
+// operations.
+type ApplyOption func(*applyConfig)
+
+// applyConfig holds the settings used while applying patches.
+type applyConfig struct {
+	guards   bool
+	relative bool
+	tolerant bool
+	upsert   bool
+	dropped  *[]string
+}
+
+func newApplyConfig(opts []ApplyOption) *applyConfig {
+	cfg := &applyConfig{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+	return cfg
+}
+
+// Guard operations are non-standard checks accepted when WithGuards is set.
+// Like test they change nothing, but they assert only whether a path is
+// present and take no value.
+const (
+	// GuardExists fails unless a value (possibly null) exists at Path.
+	GuardExists = "exists"
+	// GuardAbsent fails if any value exists at Path.
+	GuardAbsent = "absent"
+)
+
+// WithGuards accepts the GuardExists and GuardAbsent extension operations.
+// Without it they are rejected as unsupported, keeping the default
+// behavior RFC 6902 compatible.
+func WithGuards() ApplyOption {
+	return func(c *applyConfig) {
+		c.guards = true
+	}
+}
+
+// WithTolerant makes remove and replace operations whose path does not exist
+// succeed without changing the document, for idempotent reconciliation that
+// may re-send an operation already applied. Other failures, such as a test
+// that does not hold or an add under a missing parent, are still errors. The
+// default is the strict RFC 6902 behavior.
+func WithTolerant() ApplyOption {
+	return func(c *applyConfig) {
+		c.tolerant = true
+	}
+}
+
+// WithUpsert applies a replace whose path does not exist as an add, so the
+// value is created instead of the operation failing. Combine it with
+// WithTolerant to also ignore removes of missing paths.
+func WithUpsert() ApplyOption {
+	return func(c *applyConfig) {
+		c.upsert = true
+	}
+}
+
+// missingOp returns the operation to perform for op under WithTolerant and
+// WithUpsert: op itself when its target exists (or the options do not
+// apply), "add" for an upserted replace, or "" to skip it.
+func (c *applyConfig) missingOp(op string, exists func() bool) string {
+	if (op != "remove" && op != "replace") || (!c.tolerant && !c.upsert) || exists() {
+		return op
+	}
+	switch {
+	case op == "replace" && c.upsert:
+		return "add"
+	case c.tolerant:
+		return ""
+	}
+	return op
+}
+
+// WithDroppedKeys makes ApplyPatchAndHydrate report, in *dropped, the sorted
+// top-level keys of the patched document that the target struct has no
+// field for and that encoding/json therefore discards. Keys captured by a
+// field tagged jsonpatch:"extra" are not reported. Other apply functions
+// ignore the option.
+func WithDroppedKeys(dropped *[]string) ApplyOption {
+	return func(c *applyConfig) {
+		c.dropped = dropped
+	}
+}
+
+// checkGuard evaluates an exists/absent guard given whether the path exists.
+func checkGuard(op string, parts []string, exists bool) error {
+	path := "/" + strings.Join(parts, "/")
+	switch {
+	case op == GuardExists && !exists:
+		return fmt.Errorf("guard failed: path %s does not exist", path)
+	case op == GuardAbsent && exists:
+		return fmt.Errorf("guard failed: path %s exists", path)
+	}
+	return nil
+}
+
+// WithTypeChangeAsRemoveAdd emits a remove followed by an add, instead of a
+// replace, when an object member changes JSON type (for example from a string
+// to an object or from null to a number). Consumers that validate operations
+// against a schema per type can then treat the old and new values separately.
+// Members whose value changes within the same JSON type, and array elements,
+// are still replaced.
+func WithTypeChangeAsRemoveAdd() DiffOption {
+	return func(c *diffConfig) {
+		c.typeChange = true
+	}
+}
+
+// replaceMember appends the operations setting the existing member at path to
+// afterVal: a replace, or a remove and an add when WithTypeChangeAsRemoveAdd
+// is set and the JSON type differs.
+func (c *diffConfig) replaceMember(patches []Patch, path string, beforeVal, afterVal any) []Patch {
+	if c.typeChange && jsonKind(beforeVal) != jsonKind(afterVal) {
+		return append(patches,
+			Patch{Op: "remove", Path: path},
+			Patch{Op: "add", Path: path, Value: snapshotValue(afterVal)})
+	}
+	return append(patches, Patch{Op: "replace", Path: path, Value: snapshotValue(afterVal)})
+}
+
+// jsonKind reports the JSON type v encodes as: "null", "boolean", "number",
+// "string", "array", or "object". It returns "" when v cannot be encoded.
+func jsonKind(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case *OrderedObject:
+		if val == nil {
+			return "null"
+		}
+		return "object"
+	case []byte:
+		if val == nil {
+			return "null"
+		}
+		return "string"
+	case json.Marshaler:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return ""
+		}
+		return jsonKindOf(data)
+	case encoding.TextMarshaler:
+		return "string"
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Map:
+		if rv.IsNil() {
+			return "null"
+		}
+		if rv.Kind() == reflect.Map {
+			return "object"
+		}
+		return "array"
+	case reflect.Array:
+		return "array"
+	case reflect.Struct:
+		return "object"
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return "null"
+		}
+		return jsonKind(rv.Elem().Interface())
+	case reflect.Invalid, reflect.Complex64, reflect.Complex128, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+	}
+	return ""
+}
+
+// jsonKindOf reports the JSON type of the encoded value data.
+func jsonKindOf(data []byte) string {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return ""
+	}
+	switch data[0] {
+	case 'n':
+		return "null"
+	case 't', 'f':
+		return "boolean"
+	case '"':
+		return "string"
+	case '[':
+		return "array"
+	case '{':
+		return "object"
+	}
+	return "number"
+}
+
+// WithCaseInsensitiveKeys matches object keys that differ only in case, for
+// documents from systems that treat keys case-insensitively (HTTP headers,
+// some configuration formats). A key whose casing changed but whose value did
+// not, such as "Name" becoming "name", produces no operation; when the value
+// changed too, the old key is removed and the new one added. A key is only
+// matched when exactly one key on the other side folds to it.
+func WithCaseInsensitiveKeys() DiffOption {
+	return func(c *diffConfig) {
+		c.foldKeys = true
+	}
+}
+
+// renamedKeys pairs each key of after that is missing from before with the
+// key of before that differs from it only in case, when there is exactly one
+// such key on each side. The result maps after keys to before keys.
+func renamedKeys(before, after map[string]any) map[string]string {
+	missing := map[string][]string{}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			folded := strings.ToLower(key)
+			missing[folded] = append(missing[folded], key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	removed := map[string][]string{}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			folded := strings.ToLower(key)
+			removed[folded] = append(removed[folded], key)
+		}
+	}
+	renamed := map[string]string{}
+	for folded, keys := range missing {
+		if candidates := removed[folded]; len(keys) == 1 && len(candidates) == 1 {
+			renamed[keys[0]] = candidates[0]
+		}
+	}
+	return renamed
+}
@@ -0,0 +1,491 @@
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OrderedObject is a JSON object that remembers the order of its keys. It
+// is meant for formats where key order is meaningful, such as configuration
+// files. Unmarshaling JSON into an OrderedObject keeps nested objects as
+// *OrderedObject values and arrays as []any; marshaling writes keys in order.
+//
+// The zero value is an empty object ready to use.
+type OrderedObject struct {
+	keys   []string
+	values map[string]any
+}
+
+// NewOrderedObject returns an empty OrderedObject.
+func NewOrderedObject() *OrderedObject {
+	return &OrderedObject{values: make(map[string]any)}
+}
+
+// Set inserts or updates a key. New keys are appended; existing keys keep
+// their position.
+func (o *OrderedObject) Set(key string, value any) {
+	if o.values == nil {
+		o.values = make(map[string]any)
+	}
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// Get returns the value stored under key.
+func (o *OrderedObject) Get(key string) (any, bool) {
+	value, ok := o.values[key]
+	return value, ok
+}
+
+// Delete removes key, if present.
+func (o *OrderedObject) Delete(key string) {
+	if _, exists := o.values[key]; !exists {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns a copy of the keys in order.
+func (o *OrderedObject) Keys() []string {
+	return append([]string(nil), o.keys...)
+}
+
+// Len returns the number of keys.
+func (o *OrderedObject) Len() int {
+	return len(o.keys)
+}
+
+// ToMap returns the object as a plain map[string]any, converting nested
+// ordered objects as well. Key order is lost.
+func (o *OrderedObject) ToMap() map[string]any {
+	result := make(map[string]any, len(o.keys))
+	for key, value := range o.values {
+		result[key] = unorderedValue(value)
+	}
+	return result
+}
+
+// MarshalJSON writes the object with its keys in order.
+func (o *OrderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		keyBytes, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		valueBytes, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(keyBytes)
+		buf.WriteByte(':')
+		buf.Write(valueBytes)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON object, preserving key order at every level.
+func (o *OrderedObject) UnmarshalJSON(data []byte) error {
+	value, err := decodeOrdered(data)
+	if err != nil {
+		return err
+	}
+	obj, ok := value.(*OrderedObject)
+	if !ok {
+		return fmt.Errorf("expected JSON object, got %T", value)
+	}
+	*o = *obj
+	return nil
+}
+
+// decodeOrdered decodes a single JSON value, representing objects as
+// *OrderedObject.
+func decodeOrdered(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	value, err := decodeOrderedValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err == nil {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return value, nil
+}
+
+func decodeOrderedValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch delim {
+	case '{':
+		obj := NewOrderedObject()
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyTok.(string)
+			value, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(key, value)
+		}
+		_, err := dec.Token()
+		return obj, err
+	case '[':
+		arr := []any{}
+		for dec.More() {
+			value, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err := dec.Token()
+		return arr, err
+	}
+	return nil, fmt.Errorf("unexpected delimiter %s", delim)
+}
+
+// unorderedValue converts ordered objects nested in value to plain maps.
+func unorderedValue(value any) any {
+	switch v := value.(type) {
+	case *OrderedObject:
+		if v == nil {
+			return nil
+		}
+		return v.ToMap()
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = unorderedValue(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// orderedValue converts value into the ordered representation. Plain maps
+// have no order of their own, so their keys are sorted for determinism.
+func orderedValue(value any) any {
+	switch v := value.(type) {
+	case *OrderedObject:
+		if v == nil {
+			return nil
+		}
+		cp := &OrderedObject{keys: v.Keys(), values: make(map[string]any, len(v.values))}
+		for key, item := range v.values {
+			cp.values[key] = orderedValue(item)
+		}
+		return cp
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		obj := NewOrderedObject()
+		for _, key := range keys {
+			obj.Set(key, orderedValue(v[key]))
+		}
+		return obj
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = orderedValue(item)
+		}
+		return result
+	default:
+		converted := convertValue(value)
+		switch converted.(type) {
+		case map[string]any, []any:
+			return orderedValue(converted)
+		}
+		return converted
+	}
+}
+
+// reorderPatches returns the moves that turn before's key order into
+// after's, assuming additions were appended in after's order. Under
+// ordered semantics a move of a key onto itself removes and re-adds it,
+// which re-appends it at the end; for unordered consumers such a move is a
+// no-op, so the patch stays RFC 6902 compatible. Keys forming the longest
+// prefix of after that already appears in order are left in place.
+func reorderPatches(before, after *OrderedObject, basePath string) []Patch {
+	current := make([]string, 0, len(after.keys))
+	for _, key := range before.keys {
+		if _, ok := after.values[key]; ok {
+			current = append(current, key)
+		}
+	}
+	for _, key := range after.keys {
+		if _, ok := before.values[key]; !ok {
+			current = append(current, key)
+		}
+	}
+
+	kept := 0
+	for _, key := range current {
+		if kept < len(after.keys) && after.keys[kept] == key {
+			kept++
+		}
+	}
+
+	var patches []Patch
+	for _, key := range after.keys[kept:] {
+		path := basePath + "/" + escapePathSegment(key)
+		patches = append(patches, Patch{Op: "move", From: path, Path: path})
+	}
+	return patches
+}
+
+// ApplyPatchOrdered applies patches to an ordered document and returns the
+// patched copy; original is not modified. Object key order is preserved:
+// replacing a member keeps its position, adding a new key appends it, and
+// moving a key (including onto itself) re-appends it at its destination.
+// Object values in patches that are plain maps are inserted with sorted keys.
+func ApplyPatchOrdered(original *OrderedObject, patches []Patch, opts ...ApplyOption) (*OrderedObject, error) {
+	cfg := newApplyConfig(opts)
+	var root any = NewOrderedObject()
+	if original != nil {
+		root = orderedValue(original)
+	}
+
+	for _, op := range patches {
+		var err error
+		root, err = cfg.applyOrderedOperation(root, op)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	doc, ok := root.(*OrderedObject)
+	if !ok {
+		return nil, fmt.Errorf("root value must be an object, got %T", root)
+	}
+	return doc, nil
+}
+
+func (c *applyConfig) applyOrderedOperation(root any, op Patch) (any, error) {
+	parts, err := parsePath(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	value := op.Value
+	if raw, ok := value.(json.RawMessage); ok {
+		if value, err = decodeOrdered(raw); err != nil {
+			return nil, fmt.Errorf("invalid value for %s %s: %w", op.Op, op.Path, err)
+		}
+	} else if value, err = decodeRawValue(value); err != nil {
+		return nil, fmt.Errorf("invalid value for %s %s: %w", op.Op, op.Path, err)
+	}
+	value = orderedValue(value)
+
+	switch c.missingOp(op.Op, func() bool { _, err := orderedGet(root, parts); return err == nil }) {
+	case "":
+		return root, nil
+	case "add":
+		return orderedAdd(root, parts, value)
+	case "remove":
+		root, _, err = orderedRemove(root, parts)
+		return root, err
+	case "replace":
+		return orderedReplace(root, parts, value)
+	case "move":
+		fromParts, err := c.fromParts(op)
+		if err != nil {
+			return nil, err
+		}
+		if len(fromParts) == 0 || len(parts) == 0 {
+			return nil, fmt.Errorf("move involving document root is not supported")
+		}
+		if isProperPrefix(fromParts, parts) {
+			return nil, fmt.Errorf("move failed: from path is a proper prefix of target path")
+		}
+		updated, moved, err := orderedRemove(root, fromParts)
+		if err != nil {
+			return nil, err
+		}
+		return orderedAdd(updated, parts, moved)
+	case "copy":
+		fromParts, err := c.fromParts(op)
+		if err != nil {
+			return nil, err
+		}
+		copied, err := orderedGet(root, fromParts)
+		if err != nil {
+			return nil, err
+		}
+		return orderedAdd(root, parts, orderedValue(copied))
+	case "test":
+		actual, err := orderedGet(root, parts)
+		if err != nil {
+			return nil, fmt.Errorf("test failed: %w", err)
+		}
+		if !jsonEqual(actual, value) {
+			return nil, fmt.Errorf("test failed: value at %s is %v, expected %v", strings.Join(parts, "/"), actual, value)
+		}
+		return root, nil
+	case GuardExists, GuardAbsent:
+		if !c.guards {
+			return nil, fmt.Errorf("unsupported op: %s", op.Op)
+		}
+		_, err := orderedGet(root, parts)
+		return root, checkGuard(op.Op, parts, err == nil)
+	default:
+		return nil, fmt.Errorf("unsupported op: %s", op.Op)
+	}
+}
+
+// orderedGet returns the value at parts beneath node.
+func orderedGet(node any, parts []string) (any, error) {
+	for i, part := range parts {
+		switch container := node.(type) {
+		case *OrderedObject:
+			value, ok := container.Get(part)
+			if !ok {
+				return nil, fmt.Errorf("path %s does not exist", strings.Join(parts[:i+1], "/"))
+			}
+			node = value
+		case []any:
+			idx, err := parseArrayIndex(part, len(container), false)
+			if err != nil {
+				return nil, err
+			}
+			node = container[idx]
+		default:
+			return nil, fmt.Errorf("path %s does not exist", strings.Join(parts[:i+1], "/"))
+		}
+	}
+	return node, nil
+}
+
+// orderedUpdate walks to the container holding the last segment of parts
+// and replaces it with the result of fn. Arrays are values rather than
+// references, so each updated container is written back into its parent.
+func orderedUpdate(node any, parts []string, fn func(container any, key string) (any, error)) (any, error) {
+	if len(parts) == 1 {
+		return fn(node, parts[0])
+	}
+	child, err := orderedGet(node, parts[:1])
+	if err != nil {
+		return nil, err
+	}
+	updated, err := orderedUpdate(child, parts[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+	switch container := node.(type) {
+	case *OrderedObject:
+		container.Set(parts[0], updated)
+	case []any:
+		idx, _ := strconv.Atoi(parts[0])
+		container[idx] = updated
+	}
+	return node, nil
+}
+
+func orderedAdd(root any, parts []string, value any) (any, error) {
+	if len(parts) == 0 {
+		if _, ok := value.(*OrderedObject); !ok {
+			return nil, fmt.Errorf("root value must be an object, got %T", value)
+		}
+		return value, nil
+	}
+	return orderedUpdate(root, parts, func(container any, key string) (any, error) {
+		switch c := container.(type) {
+		case *OrderedObject:
+			c.Set(key, value)
+			return c, nil
+		case []any:
+			if key == "-" {
+				return append(c, value), nil
+			}
+			idx, err := parseArrayIndex(key, len(c), true)
+			if err != nil {
+				return nil, err
+			}
+			return sliceInsert(c, idx, value), nil
+		default:
+			return nil, fmt.Errorf("path %s does not exist", strings.Join(parts, "/"))
+		}
+	})
+}
+
+func orderedRemove(root any, parts []string) (any, any, error) {
+	if len(parts) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove document root")
+	}
+	var removed any
+	updated, err := orderedUpdate(root, parts, func(container any, key string) (any, error) {
+		switch c := container.(type) {
+		case *OrderedObject:
+			value, ok := c.Get(key)
+			if !ok {
+				return nil, fmt.Errorf("path %s does not exist", strings.Join(parts, "/"))
+			}
+			removed = value
+			c.Delete(key)
+			return c, nil
+		case []any:
+			idx, err := parseArrayIndex(key, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = c[idx]
+			return append(c[:idx:idx], c[idx+1:]...), nil
+		default:
+			return nil, fmt.Errorf("path %s does not exist", strings.Join(parts, "/"))
+		}
+	})
+	return updated, removed, err
+}
+
+func orderedReplace(root any, parts []string, value any) (any, error) {
+	if len(parts) == 0 {
+		return orderedAdd(root, parts, value)
+	}
+	return orderedUpdate(root, parts, func(container any, key string) (any, error) {
+		switch c := container.(type) {
+		case *OrderedObject:
+			if _, ok := c.Get(key); !ok {
+				return nil, fmt.Errorf("path %s does not exist", strings.Join(parts, "/"))
+			}
+			c.Set(key, value)
+			return c, nil
+		case []any:
+			idx, err := parseArrayIndex(key, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			c[idx] = value
+			return c, nil
+		default:
+			return nil, fmt.Errorf("path %s does not exist", strings.Join(parts, "/"))
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseOrdered(t *testing.T, data string) *OrderedObject {
+	t.Helper()
+	var obj OrderedObject
+	require.NoError(t, json.Unmarshal([]byte(data), &obj))
+	return &obj
+}
+
+func TestShouldGenerateMovesGivenOnlyKeyOrderChanges(t *testing.T) {
+	// Arrange
+	before := parseOrdered(t, `{"name":"svc","port":80,"host":"localhost"}`)
+	after := parseOrdered(t, `{"host":"localhost","name":"svc","port":80}`)
+
+	// Act
+	patches, err := GeneratePatch(before, after, "", WithKeyOrder())
+	require.NoError(t, err)
+	result, err := ApplyPatchOrdered(before, patches)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, []Patch{
+		{Op: "move", From: "/name", Path: "/name"},
+		{Op: "move", From: "/port", Path: "/port"},
+	}, patches)
+	out, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Equal(t, `{"host":"localhost","name":"svc","port":80}`, string(out))
+	assert.Equal(t, []string{"name", "port", "host"}, before.Keys())
+}
+
+func TestShouldIgnoreKeyOrderGivenNoKeyOrderOption(t *testing.T) {
+	// Arrange
+	before := parseOrdered(t, `{"a":1,"b":{"x":1,"y":2}}`)
+	after := parseOrdered(t, `{"b":{"y":2,"x":1},"a":1}`)
+
+	// Act
+	patches, err := GeneratePatch(before, after, "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, patches)
+}
+
+func TestShouldPreserveNestedKeyOrderGivenValueAndOrderChanges(t *testing.T) {
+	// Arrange
+	before := parseOrdered(t, `{"server":{"host":"a","port":1,"tls":true},"debug":false,"tags":["x"]}`)
+	after := parseOrdered(t, `{"server":{"port":2,"host":"a","timeout":30},"tags":["x","y"],"debug":false}`)
+
+	// Act
+	patches, err := GeneratePatch(before, after, "", WithKeyOrder())
+	require.NoError(t, err)
+	result, err := ApplyPatchOrdered(before, patches)
+	require.NoError(t, err)
+
+	// Assert
+	out, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Equal(t, `{"server":{"port":2,"host":"a","timeout":30},"tags":["x","y"],"debug":false}`, string(out))
+
+	unordered, err := ApplyPatch(before, patches)
+	require.NoError(t, err)
+	assert.Equal(t, after.ToMap(), unordered)
+}
+
+func TestShouldFailOrderedApplyGivenMissingPath(t *testing.T) {
+	// Arrange
+	doc := parseOrdered(t, `{"a":1}`)
+
+	// Act
+	_, err := ApplyPatchOrdered(doc, []Patch{{Op: "remove", Path: "/b"}})
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not exist")
+}
+
+func TestShouldPreserveKeyOrderGivenAddRemoveAndReplace(t *testing.T) {
+	// Arrange
+	doc := parseOrdered(t, `{"name":"svc","port":80,"host":"localhost","log":{"level":"info","file":"out.log"}}`)
+	patches := []Patch{
+		{Op: "replace", Path: "/port", Value: 8080},
+		{Op: "remove", Path: "/host"},
+		{Op: "add", Path: "/timeout", Value: 30},
+		{Op: "add", Path: "/log/format", Value: "json"},
+		{Op: "replace", Path: "/log/level", Value: "debug"},
+	}
+
+	// Act
+	result, err := ApplyPatchOrdered(doc, patches)
+	require.NoError(t, err)
+
+	// Assert
+	out, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"svc","port":8080,"log":{"level":"debug","file":"out.log","format":"json"},"timeout":30}`, string(out))
+	assert.Equal(t, []string{"name", "port", "host", "log"}, doc.Keys())
+}
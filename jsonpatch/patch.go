@@ -2,22 +2,31 @@ package jsonpatch
 
 import (
 	"encoding"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // Patch represents a single JSON Patch operation as defined by RFC 6902.
 // The Op field is the operation (add, remove, replace, move). Path is
 // the JSON Pointer location. From is used by move operations and Value
 // holds the operation payload when applicable.
+//
+// Reason is an optional, non-standard annotation describing why the
+// operation exists (see WithAnnotator). It is omitted from the marshaled
+// form when empty and is ignored when patches are applied.
 type Patch struct {
-	Op    string `json:"op"`
-	Path  string `json:"path"`
-	From  string `json:"from,omitempty"`
-	Value any    `json:"value"`
+	Op     string `json:"op"`
+	Path   string `json:"path"`
+	From   string `json:"from,omitempty"`
+	Value  any    `json:"value"`
+	Reason string `json:"reason,omitempty"`
 }
 
 // GeneratePatch computes a list of JSON Patch operations that transform
@@ -27,76 +36,253 @@ type Patch struct {
 //
 // The function attempts to produce minimal patches for arrays using an
 // LCS-based algorithm. String comparison is exact (whitespace-sensitive).
-func GeneratePatch(before, after any, basePath string) ([]Patch, error) {
-	var patches []Patch
-	beforeMap, err := toMap(before)
+// Options (see DiffOption) tune how the patch is produced; use a Differ to
+// reuse the same options across many calls.
+func GeneratePatch(before, after any, basePath string, opts ...DiffOption) ([]Patch, error) {
+	differ := Differ{opts: opts}
+	return differ.DiffAt(before, after, basePath)
+}
+
+// GenerateBidirectionalPatch returns both the forward patch, transforming
+// before into after, and the reverse patch, transforming after back into
+// before, so callers recording a change get its undo without keeping the
+// original document around. Both are generated with the same options and
+// paths relative to the document root. Options that drop differences, such
+// as WithIgnorePaths or WithStrictStrings(false), make the reverse restore
+// only what the forward patch changed.
+func GenerateBidirectionalPatch(before, after any, opts ...DiffOption) (forward, reverse []Patch, err error) {
+	differ := Differ{opts: opts}
+	forward, err = differ.Diff(before, after)
+	if err != nil {
+		return nil, nil, err
+	}
+	reverse, err = differ.Diff(after, before)
+	if err != nil {
+		return nil, nil, err
+	}
+	return forward, reverse, nil
+}
+
+// DiffStats summarizes how array diffs were computed during patch generation.
+// It helps judge whether the LCS alignment was effective, for example to
+// decide whether a keyed diff would suit a document better.
+type DiffStats struct {
+	// Arrays is the number of array pairs that were diffed.
+	Arrays int
+	// LCSLength is the number of elements kept in place across all arrays,
+	// including trimmed common prefixes and suffixes.
+	LCSLength int
+	// Removals is the number of array remove operations emitted.
+	Removals int
+	// Additions is the number of array add operations emitted.
+	Additions int
+	// Merges is the number of aligned positions merged into in-place replace
+	// operations instead of a remove/add pair.
+	Merges int
+	// Moves is the number of array move operations emitted.
+	Moves int
+}
+
+// GeneratePatchWithStats behaves like GeneratePatch and additionally returns
+// statistics describing the array diffs performed.
+func GeneratePatchWithStats(before, after any, basePath string, opts ...DiffOption) ([]Patch, DiffStats, error) {
+	cfg := newDiffConfig(opts)
+	cfg.stats = &DiffStats{}
+	patches, err := cfg.generate(before, after, basePath)
+	if err != nil {
+		return nil, DiffStats{}, err
+	}
+	return patches, *cfg.stats, nil
+}
+
+// generate runs a complete diff rooted at basePath: the operations, then
+// copy detection and annotation when configured.
+func (c *diffConfig) generate(before, after any, basePath string) ([]Patch, error) {
+	c.root = basePath
+	patches, err := c.diff(before, after, basePath)
+	if err == nil {
+		err = c.err
+	}
 	if err != nil {
 		return nil, err
 	}
-	afterMap, err := toMap(after)
+	patches = c.detectCopies(before, after, basePath, patches)
+	c.annotatePatches(patches)
+	return patches, nil
+}
+
+func (c *diffConfig) recordArrayStats(kept, removals, additions, merges, moves int) {
+	if c.stats == nil {
+		return
+	}
+	c.stats.Arrays++
+	c.stats.LCSLength += kept
+	c.stats.Removals += removals
+	c.stats.Additions += additions
+	c.stats.Merges += merges
+	c.stats.Moves += moves
+}
+
+// diff computes the operations transforming before into after beneath basePath.
+func (c *diffConfig) diff(before, after any, basePath string) ([]Patch, error) {
+	defer func() { c.depth-- }()
+	if !c.enter(basePath) {
+		return nil, c.err
+	}
+
+	beforeMap, err := c.objectMembers(before)
+	if err != nil {
+		return nil, err
+	}
+	afterMap, err := c.objectMembers(after)
 	if err != nil {
 		return nil, err
 	}
 
-	// Process keys present in the "after" document.
-	for key, afterVal := range afterMap {
-		beforeVal, exists := beforeMap[key]
-		if !exists {
-			patches = append(patches, Patch{Op: "add", Path: basePath + "/" + escapePathSegment(key), Value: afterVal})
-			continue
-		}
-		// Nil edge case: reflect.TypeOf(nil) is nil and would panic on .Kind().
-		if beforeVal == nil || afterVal == nil {
-			if beforeVal != afterVal {
-				patches = append(patches, Patch{Op: "replace", Path: basePath + "/" + escapePathSegment(key), Value: afterVal})
+	var patches []Patch
+	orderedBefore, beforeOrdered := before.(*OrderedObject)
+	orderedAfter, afterOrdered := after.(*OrderedObject)
+	keyOrder := c.keyOrder && beforeOrdered && afterOrdered && orderedBefore != nil && orderedAfter != nil
+
+	// With case-insensitive keys, keys whose casing changed are paired up;
+	// an unchanged value needs no operation.
+	var renamed map[string]string
+	var matched map[string]bool
+	if c.foldKeys {
+		renamed = renamedKeys(beforeMap, afterMap)
+		matched = make(map[string]bool, len(renamed))
+		for afterKey, beforeKey := range renamed {
+			if c.deepEqualFiltered(beforeMap[beforeKey], afterMap[afterKey]) {
+				matched[beforeKey] = true
 			}
-			continue
 		}
-		// Fast path: skip path allocation for equal non-container types.
-		switch beforeVal.(type) {
-		case map[string]any, []any:
-			// Containers need recursion; fall through to full handling below.
-		default:
-			if deepEqualFiltered(beforeVal, afterVal) {
+	}
+	skip := func(afterKey string) bool {
+		beforeKey, ok := renamed[afterKey]
+		return ok && matched[beforeKey]
+	}
+
+	// Process keys present in the "after" document.
+	if keyOrder {
+		for _, key := range orderedAfter.keys {
+			if skip(key) {
 				continue
 			}
+			beforeVal, exists := beforeMap[key]
+			patches = c.diffMember(patches, basePath, key, beforeVal, exists, afterMap[key])
 		}
-		path := basePath + "/" + escapePathSegment(key)
-		if reflect.TypeOf(beforeVal) != reflect.TypeOf(afterVal) {
-			patches = append(patches, Patch{Op: "replace", Path: path, Value: afterVal})
-			continue
-		}
-		switch kind := reflect.TypeOf(beforeVal).Kind(); kind {
-		case reflect.Slice:
-			arrOps, _ := generateArrayPatch(path, beforeVal, afterVal)
-			patches = append(patches, arrOps...)
-		case reflect.Map, reflect.Struct:
-			nested, _ := GeneratePatch(beforeVal, afterVal, path)
-			patches = append(patches, nested...)
-		case reflect.Invalid, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
-			reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
-			reflect.Array, reflect.Chan, reflect.Func, reflect.Interface, reflect.Pointer, reflect.String, reflect.UnsafePointer:
-			if !deepEqualFiltered(beforeVal, afterVal) {
-				patches = append(patches, Patch{Op: "replace", Path: path, Value: afterVal})
+	} else {
+		for key, afterVal := range afterMap {
+			if skip(key) {
+				continue
 			}
+			beforeVal, exists := beforeMap[key]
+			patches = c.diffMember(patches, basePath, key, beforeVal, exists, afterVal)
 		}
 	}
 
 	// Process removals for keys that are in "before" but not in "after".
+	if keyOrder {
+		for _, key := range orderedBefore.keys {
+			if _, exists := afterMap[key]; !exists && !matched[key] {
+				patches = c.removeMember(patches, basePath, key)
+			}
+		}
+		for _, move := range reorderPatches(orderedBefore, orderedAfter, basePath) {
+			// A key matched only by case still has its old spelling in the
+			// document, so it cannot be moved under its new one.
+			if !skip(unescapePathSegment(strings.TrimPrefix(move.Path, basePath+"/"))) {
+				patches = append(patches, move)
+			}
+		}
+		return patches, nil
+	}
 	for key := range beforeMap {
-		if _, exists := afterMap[key]; !exists {
-			patches = append(patches, Patch{Op: "remove", Path: basePath + "/" + escapePathSegment(key)})
+		if _, exists := afterMap[key]; !exists && !matched[key] {
+			patches = c.removeMember(patches, basePath, key)
 		}
 	}
 	return patches, nil
 }
 
+// objectMembers returns the members of an object-like value. With key order
+// enabled, ordered objects expose their members directly so nested ordered
+// objects keep their order; otherwise everything is converted to plain maps.
+func (c *diffConfig) objectMembers(data any) (map[string]any, error) {
+	if c.keyOrder {
+		if obj, ok := data.(*OrderedObject); ok && obj != nil {
+			return obj.values, nil
+		}
+	}
+	return toMapWith(data, diffValue)
+}
+
+// removeMember appends the removal of an object member unless it is ignored.
+func (c *diffConfig) removeMember(patches []Patch, basePath, key string) []Patch {
+	path := basePath + "/" + escapePathSegment(key)
+	if c.ignored(path) {
+		return patches
+	}
+	return append(patches, Patch{Op: "remove", Path: path})
+}
+
+// diffMember appends the operations for a single object member to patches.
+func (c *diffConfig) diffMember(patches []Patch, basePath, key string, beforeVal any, exists bool, afterVal any) []Patch {
+	if len(c.ignore) > 0 && c.ignored(basePath+"/"+escapePathSegment(key)) {
+		return patches
+	}
+	if !exists {
+		return append(patches, Patch{Op: "add", Path: basePath + "/" + escapePathSegment(key), Value: snapshotValue(afterVal)})
+	}
+	// Nil edge case: reflect.TypeOf(nil) is nil and would panic on .Kind().
+	if beforeVal == nil || afterVal == nil {
+		if beforeVal != afterVal {
+			patches = c.replaceMember(patches, basePath+"/"+escapePathSegment(key), beforeVal, afterVal)
+		}
+		return patches
+	}
+	// Fast path: skip path allocation for equal non-container types.
+	switch beforeVal.(type) {
+	case map[string]any, []any, *OrderedObject:
+		// Containers need recursion; fall through to full handling below.
+	default:
+		if c.deepEqualFiltered(beforeVal, afterVal) {
+			return patches
+		}
+	}
+	path := basePath + "/" + escapePathSegment(key)
+	if reflect.TypeOf(beforeVal) != reflect.TypeOf(afterVal) {
+		return c.replaceMember(patches, path, beforeVal, afterVal)
+	}
+	if _, ok := beforeVal.(*OrderedObject); ok {
+		nested, _ := c.diff(beforeVal, afterVal, path)
+		return append(patches, c.collapse(nested, path, afterVal)...)
+	}
+	switch kind := reflect.TypeOf(beforeVal).Kind(); kind {
+	case reflect.Slice:
+		arrOps, _ := c.generateArrayPatch(path, beforeVal, afterVal)
+		patches = append(patches, arrOps...)
+	case reflect.Map, reflect.Struct:
+		nested, _ := c.diff(beforeVal, afterVal, path)
+		patches = append(patches, c.collapse(nested, path, afterVal)...)
+	case reflect.Invalid, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.Array, reflect.Chan, reflect.Func, reflect.Interface, reflect.Pointer, reflect.String, reflect.UnsafePointer:
+		if !c.deepEqualFiltered(beforeVal, afterVal) {
+			patches = append(patches, Patch{Op: "replace", Path: path, Value: snapshotValue(afterVal)})
+		}
+	}
+	return patches
+}
+
 // ApplyPatch applies a series of JSON Patch operations to the original
 // JSON-like object (struct or map). It returns the patched document as
 // a map[string]any. The implementation applies operations sequentially
-// and returns an error on the first failing operation.
-func ApplyPatch(original any, patches []Patch) (map[string]any, error) {
+// and returns an error on the first failing operation. Options (see
+// ApplyOption) enable non-standard extensions; by default only RFC 6902
+// operations are accepted.
+func ApplyPatch(original any, patches []Patch, opts ...ApplyOption) (map[string]any, error) {
 	originalMap, err := toMap(original)
 	if err != nil {
 		return nil, err
@@ -105,47 +291,162 @@ func ApplyPatch(original any, patches []Patch) (map[string]any, error) {
 	// Create a deep copy to ensure atomicity
 	target := deepCopy(originalMap)
 
-	// Process each patch sequentially.
-	for _, op := range patches {
-		parts, err := parsePath(op.Path)
-		if err != nil {
-			return nil, err
+	if err := newApplyConfig(opts).applyPatches(target, patches); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// DryRunPatch reports whether patches would apply cleanly to original. It
+// runs the full apply against a copy and returns the first error (or nil)
+// without producing a result; original is never modified.
+func DryRunPatch(original any, patches []Patch, opts ...ApplyOption) error {
+	originalMap, err := toMap(original)
+	if err != nil {
+		return err
+	}
+	return newApplyConfig(opts).applyPatches(deepCopy(originalMap), patches)
+}
+
+// ApplyRawPatch decodes patchJSON, a JSON Patch document such as
+// `[{"op":"add","path":"/a","value":1}]`, and applies it to original like
+// ApplyPatch. Every operation is validated before any is applied: the op must
+// be known (guard operations only with WithGuards), path is required, move
+// and copy require from, and add, replace, and test require value.
+func ApplyRawPatch(original any, patchJSON []byte, opts ...ApplyOption) (map[string]any, error) {
+	cfg := newApplyConfig(opts)
+	patches, err := cfg.decodePatchDocument(patchJSON)
+	if err != nil {
+		return nil, err
+	}
+	return ApplyPatch(original, patches, opts...)
+}
+
+// decodePatchDocument unmarshals and validates a JSON Patch document.
+func (c *applyConfig) decodePatchDocument(data []byte) ([]Patch, error) {
+	var members []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, fmt.Errorf("invalid patch document: %w", err)
+	}
+	var patches []Patch
+	if err := json.Unmarshal(data, &patches); err != nil {
+		return nil, fmt.Errorf("invalid patch document: %w", err)
+	}
+
+	for i, fields := range members {
+		op := patches[i].Op
+		if _, ok := fields["op"]; !ok {
+			return nil, fmt.Errorf("invalid patch operation %d: missing op", i)
+		}
+		if _, ok := fields["path"]; !ok {
+			return nil, fmt.Errorf("invalid patch operation %d: missing path for %s", i, op)
 		}
-		switch op.Op {
-		case "add":
-			err = applyAdd(target, parts, op.Value)
+		var required string
+		switch op {
+		case "add", "replace", "test":
+			required = "value"
+		case "move", "copy":
+			required = "from"
 		case "remove":
-			err = applyRemove(target, parts)
-		case "replace":
-			err = applyReplace(target, parts, op.Value)
-		case "move":
-			fromParts, err := parsePath(op.From)
-			if err != nil {
-				return nil, err
-			}
-			err = applyMove(target, fromParts, parts)
-			if err != nil {
-				return nil, err
+		case GuardExists, GuardAbsent:
+			if !c.guards {
+				return nil, fmt.Errorf("invalid patch operation %d: unsupported op: %s", i, op)
 			}
-		case "copy":
-			fromParts, err := parsePath(op.From)
-			if err != nil {
-				return nil, err
-			}
-			err = applyCopy(target, fromParts, parts)
-			if err != nil {
-				return nil, err
-			}
-		case "test":
-			err = applyTest(target, parts, op.Value)
 		default:
-			return nil, fmt.Errorf("unsupported op: %s", op.Op)
+			if _, ok := lookupOp(op); !ok {
+				return nil, fmt.Errorf("invalid patch operation %d: unsupported op: %s", i, op)
+			}
 		}
+		if _, ok := fields[required]; required != "" && !ok {
+			return nil, fmt.Errorf("invalid patch operation %d: missing %s for %s", i, required, op)
+		}
+	}
+	return patches, nil
+}
+
+// applyPatches applies each operation to target in order, stopping at the
+// first failure.
+func (c *applyConfig) applyPatches(target map[string]any, patches []Patch) error {
+	for _, op := range patches {
+		if err := c.applyOperation(target, op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyOperation applies a single operation to target.
+func (c *applyConfig) applyOperation(target map[string]any, op Patch) error {
+	parts, err := parsePath(op.Path)
+	if err != nil {
+		return err
+	}
+	value, err := decodeRawValue(op.Value)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s %s: %w", op.Op, op.Path, err)
+	}
+	switch c.missingOp(op.Op, func() bool { _, ok := getValue(target, parts); return ok }) {
+	case "":
+		return nil
+	case "add":
+		return applyAdd(target, parts, value)
+	case "remove":
+		return applyRemove(target, parts)
+	case "replace":
+		return applyReplace(target, parts, value)
+	case "move":
+		fromParts, err := c.fromParts(op)
+		if err != nil {
+			return err
+		}
+		return applyMove(target, fromParts, parts)
+	case "copy":
+		fromParts, err := c.fromParts(op)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		return applyCopy(target, fromParts, parts)
+	case "test":
+		return applyTest(target, parts, value)
+	case GuardExists, GuardAbsent:
+		if !c.guards {
+			return fmt.Errorf("unsupported op: %s", op.Op)
+		}
+		_, exists := getValue(target, parts)
+		return checkGuard(op.Op, parts, exists)
+	default:
+		if fn, ok := lookupOp(op.Op); ok {
+			op.Value = value
+			return fn(target, op)
+		}
+		return fmt.Errorf("unsupported op: %s", op.Op)
 	}
-	return target, nil
+}
+
+// decodeRawValue splices json.RawMessage patch values in as decoded JSON so
+// they are stored (and later marshaled) as values rather than raw bytes.
+func decodeRawValue(value any) (any, error) {
+	var raw json.RawMessage
+	switch v := value.(type) {
+	case json.RawMessage:
+		raw = v
+	case *json.RawMessage:
+		if v == nil {
+			return nil, nil
+		}
+		raw = *v
+	default:
+		return value, nil
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
 }
 
 func replaceRootObject(target map[string]any, value any) error {
@@ -166,9 +467,9 @@ func replaceRootObject(target map[string]any, value any) error {
 // ApplyPatchAndHydrate applies patches to `original` and unmarshals the
 // resulting document into `updated` (which should be a pointer). This is
 // a convenience for applying patches and then hydrating a typed value.
-func ApplyPatchAndHydrate(original, updated any, patches []Patch) error {
+func ApplyPatchAndHydrate(original, updated any, patches []Patch, opts ...ApplyOption) error {
 	// Convert original to map
-	patched, err := ApplyPatch(original, patches)
+	patched, err := ApplyPatch(original, patches, opts...)
 	if err != nil {
 		return fmt.Errorf("apply patch: %w", err)
 	}
@@ -181,12 +482,108 @@ func ApplyPatchAndHydrate(original, updated any, patches []Patch) error {
 	if err := json.Unmarshal(bytes, updated); err != nil {
 		return fmt.Errorf("unmarshal to %T: %w", updated, err)
 	}
+
+	unknown := captureUnknownKeys(patched, updated)
+	if cfg := newApplyConfig(opts); cfg.dropped != nil {
+		*cfg.dropped = unknown
+	}
+	return nil
+}
+
+// ExtraTag is the value of the jsonpatch struct tag marking a
+// map[string]any field that ApplyPatchAndHydrate fills with keys unknown to
+// the struct. Tag the field json:"-" as well so it is not itself a key.
+const ExtraTag = "extra"
+
+// captureUnknownKeys finds the top-level keys of patched that updated (a
+// pointer to a struct) does not model. They are stored in the struct's
+// jsonpatch:"extra" field when it has one; otherwise they are returned,
+// sorted, as dropped.
+func captureUnknownKeys(patched map[string]any, updated any) []string {
+	v := reflect.ValueOf(updated)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	v = v.Elem()
+
+	var names []string
+	var extra reflect.Value
+	collectFieldNames(v, &names, &extra)
+
+	var unknown []string
+	for key := range patched {
+		if !slices.ContainsFunc(names, func(name string) bool { return strings.EqualFold(name, key) }) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	slices.Sort(unknown)
+
+	if !extra.IsValid() {
+		return unknown
+	}
+	captured := make(map[string]any, len(unknown))
+	for _, key := range unknown {
+		captured[key] = patched[key]
+	}
+	extra.Set(reflect.ValueOf(captured))
 	return nil
 }
 
+// collectFieldNames records the JSON names encoding/json decodes into for
+// the struct value v, promoting embedded structs, and the settable
+// jsonpatch:"extra" field if present.
+func collectFieldNames(v reflect.Value, names *[]string, extra *reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("jsonpatch") == ExtraTag && field.Type == reflect.TypeOf(map[string]any(nil)) && v.Field(i).CanSet() {
+			*extra = v.Field(i)
+		}
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" && tag == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				fv := v.Field(i)
+				if fv.Kind() == reflect.Pointer {
+					if fv.IsNil() {
+						var unreachable reflect.Value
+						collectFieldNames(reflect.New(fieldType).Elem(), names, &unreachable)
+						continue
+					}
+					fv = fv.Elem()
+				}
+				collectFieldNames(fv, names, extra)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		*names = append(*names, name)
+	}
+}
+
 // toMap converts a struct (or already a map) to a map[string]any using reflection,
 // thus avoiding expensive JSON round-trips.
 func toMap(data any) (map[string]any, error) {
+	return toMapWith(data, convertValue)
+}
+
+// toMapWith is toMap with convert applied to each struct field value.
+func toMapWith(data any, convert func(any) any) (map[string]any, error) {
 	// If already a map
 	if m, ok := data.(map[string]any); ok {
 		return m, nil
@@ -195,6 +592,12 @@ func toMap(data any) (map[string]any, error) {
 	if m, ok := data.(*map[string]any); ok {
 		return *m, nil
 	}
+	if obj, ok := data.(*OrderedObject); ok {
+		if obj == nil {
+			return make(map[string]any), nil
+		}
+		return obj.ToMap(), nil
+	}
 
 	v := reflect.ValueOf(data)
 	if v.Kind() == reflect.Pointer {
@@ -209,15 +612,60 @@ func toMap(data any) (map[string]any, error) {
 	}
 
 	result := make(map[string]any, v.NumField())
-	structToMap(v, result)
+	structToMap(v, result, convert)
 	return result, nil
 }
 
-// structToMap populates result with the fields of the struct value v,
-// promoting anonymous (embedded) struct fields like encoding/json does.
-func structToMap(v reflect.Value, result map[string]any) {
-	t := v.Type()
-	for i := 0; i < v.NumField(); i++ {
+// structToMap populates result with the fields of the struct value v, each
+// converted with convert, promoting anonymous (embedded) struct fields like
+// encoding/json does.
+func structToMap(v reflect.Value, result map[string]any, convert func(any) any) {
+	for _, field := range cachedStructFields(v.Type()) {
+		fv := v.Field(field.index)
+		if field.embedded {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			structToMap(fv, result, convert)
+			continue
+		}
+		if (field.omitempty || field.omitNil) && fv.IsZero() {
+			continue
+		}
+		result[field.key] = convert(fv.Interface())
+	}
+}
+
+// structField describes how one struct field appears in the map form:
+// under key, or, when embedded, by promoting the fields of its struct value.
+// omitNil skips nil anonymous pointers to non-struct types.
+type structField struct {
+	index     int
+	key       string
+	omitempty bool
+	omitNil   bool
+	embedded  bool
+}
+
+// structFieldCache maps a struct reflect.Type to its []structField, so the
+// json tags of a type are parsed once rather than on every conversion.
+var structFieldCache sync.Map
+
+// cachedStructFields returns the map-form fields of the struct type t.
+func cachedStructFields(t reflect.Type) []structField {
+	if cached, ok := structFieldCache.Load(t); ok {
+		return cached.([]structField)
+	}
+	fields, _ := structFieldCache.LoadOrStore(t, buildStructFields(t))
+	return fields.([]structField)
+}
+
+func buildStructFields(t reflect.Type) []structField {
+	fields := make([]structField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 
 		// Skip unexported non-embedded fields
@@ -226,55 +674,58 @@ func structToMap(v reflect.Value, result map[string]any) {
 		}
 
 		// Promote anonymous (embedded) struct fields
+		info := structField{index: i, key: field.Name}
 		if field.Anonymous {
-			fv := v.Field(i)
-			if fv.Kind() == reflect.Pointer {
-				if fv.IsNil() {
-					continue
-				}
-				fv = fv.Elem()
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+				info.omitNil = true
 			}
-			if fv.Kind() == reflect.Struct {
-				structToMap(fv, result)
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, structField{index: i, embedded: true})
 				continue
 			}
 		}
 
 		// Use JSON tag if available
-		key := field.Name
-		omitempty := false
 		if tag := field.Tag.Get("json"); tag != "" {
-			parts := strings.Split(tag, ",")
-			if parts[0] == "-" {
+			name, opts, _ := strings.Cut(tag, ",")
+			if name == "-" {
 				continue
 			}
-			if parts[0] != "" {
-				key = parts[0]
+			if name != "" {
+				info.key = name
 			}
-			for _, p := range parts[1:] {
-				if strings.TrimSpace(p) == "omitempty" {
-					omitempty = true
+			for opts != "" {
+				var opt string
+				opt, opts, _ = strings.Cut(opts, ",")
+				if strings.TrimSpace(opt) == "omitempty" {
+					info.omitempty = true
 					break
 				}
 			}
 		}
-		fv := v.Field(i)
-		if omitempty && fv.IsZero() {
-			continue
-		}
-		result[key] = convertValue(fv.Interface())
+		fields = append(fields, info)
 	}
+	return fields
 }
 
 // convertValue recursively converts structs to maps for consistent handling
 func convertValue(data any) any {
-	switch data.(type) {
+	switch typed := data.(type) {
 	case nil:
 		return nil
 	case string, float64, int, int64, int32, bool:
 		return data
+	case float32:
+		return float32ToFloat64(typed)
 	case map[string]any, []any:
 		return data
+	case *OrderedObject:
+		if typed == nil {
+			return nil
+		}
+		return typed.ToMap()
 	}
 
 	if normalized, ok := normalizeSpecialValue(data); ok {
@@ -309,15 +760,60 @@ func convertValue(data any) any {
 			return result
 		}
 		return data
-	case reflect.Invalid, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
-		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
-		reflect.Chan, reflect.Func, reflect.Interface, reflect.Pointer, reflect.String, reflect.UnsafePointer:
+		reflect.Float32, reflect.Float64, reflect.String:
+		return normalizeScalar(v)
+	case reflect.Invalid, reflect.Complex64, reflect.Complex128,
+		reflect.Chan, reflect.Func, reflect.Interface, reflect.Pointer, reflect.UnsafePointer:
 		return data
 	}
 	return data
 }
 
+// normalizeScalar dereferences and unwraps scalar values into builtin Go
+// types, so structurally different Go types with the same JSON form (a
+// *int16 and an int64, a named string and a string) compare as equal.
+// Builtin integer types are kept as they are; JSON comparison already
+// treats them numerically.
+func normalizeScalar(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String:
+		return v.String()
+	case reflect.Float32:
+		return float32ToFloat64(float32(v.Float()))
+	case reflect.Float64:
+		return v.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type().PkgPath() == "" {
+			return v.Interface()
+		}
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Type().PkgPath() == "" {
+			return v.Interface()
+		}
+		return v.Uint()
+	case reflect.Invalid, reflect.Complex64, reflect.Complex128, reflect.Array, reflect.Chan, reflect.Func,
+		reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice, reflect.Struct, reflect.UnsafePointer:
+		return v.Interface()
+	}
+	return v.Interface()
+}
+
+// float32ToFloat64 widens f using its shortest decimal representation, which
+// is what encoding/json emits, so float32(0.1) becomes 0.1 rather than
+// 0.10000000149011612.
+func float32ToFloat64(f float32) float64 {
+	widened, err := strconv.ParseFloat(strconv.FormatFloat(float64(f), 'g', -1, 32), 64)
+	if err != nil {
+		return float64(f)
+	}
+	return widened
+}
+
 func normalizeSpecialValue(data any) (any, bool) {
 	if marshaler, ok := data.(json.Marshaler); ok {
 		encoded, err := marshaler.MarshalJSON()
@@ -396,38 +892,72 @@ func deepCopySlice(original []any) []any {
 	return cloned
 }
 
-// deepEqualFiltered compares two JSON-like values using JSON semantics.
+// deepEqualFiltered compares two JSON-like values using JSON semantics and
+// the comparison configured for the diff (exact by default).
 // Fast-paths common JSON types and falls back to reflect.DeepEqual only
 // for unexpected non-JSON values.
-func deepEqualFiltered(a, b any) bool {
-	return jsonEqual(a, b)
+func (c *diffConfig) deepEqualFiltered(a, b any) bool {
+	return jsonEqualWith(a, b, c.equality)
 }
 
+// jsonEqual compares two JSON-like values with exact string comparison.
 func jsonEqual(a, b any) bool {
+	return jsonEqualWith(a, b, nil)
+}
+
+// jsonEqualWith compares two JSON-like values. eq customizes the comparison
+// when non-nil.
+func jsonEqualWith(a, b any, eq *valueEquality) bool {
+	if obj, ok := a.(*OrderedObject); ok && obj != nil {
+		a = obj.values
+	}
+	if obj, ok := b.(*OrderedObject); ok && obj != nil {
+		b = obj.values
+	}
+	if eq != nil && eq.custom != nil {
+		if equal, ok := eq.custom(a, b); ok {
+			return equal
+		}
+	}
 	if a == nil || b == nil {
 		return a == b
 	}
 
 	if av, ok := numericValue(a); ok {
 		if bv, ok := numericValue(b); ok {
+			if eq != nil && eq.epsilon > 0 {
+				return math.Abs(av-bv) <= eq.epsilon
+			}
 			return av == bv
 		}
 	}
 
 	switch av := a.(type) {
 	case string:
-		bv, ok := b.(string)
-		return ok && av == bv
+		if bv, ok := b.(string); ok {
+			if eq != nil && eq.strings != nil {
+				return eq.strings(av, bv)
+			}
+			return av == bv
+		}
+		if bv, ok := b.([]byte); ok {
+			return av == base64.StdEncoding.EncodeToString(bv)
+		}
+		return false
 	case bool:
 		bv, ok := b.(bool)
 		return ok && av == bv
 	case []any:
 		bv, ok := b.([]any)
-		if !ok || len(av) != len(bv) {
+		if !ok {
+			equal, comparable := sliceEqual(a, b, eq)
+			return comparable && equal
+		}
+		if len(av) != len(bv) {
 			return false
 		}
 		for i := range av {
-			if !jsonEqual(av[i], bv[i]) {
+			if !jsonEqualWith(av[i], bv[i], eq) {
 				return false
 			}
 		}
@@ -439,22 +969,57 @@ func jsonEqual(a, b any) bool {
 		}
 		for key, value := range av {
 			other, ok := bv[key]
-			if !ok || !jsonEqual(value, other) {
+			if !ok || !jsonEqualWith(value, other, eq) {
 				return false
 			}
 		}
 		return true
 	default:
+		if equal, comparable := sliceEqual(a, b, eq); comparable {
+			return equal
+		}
 		return reflect.DeepEqual(a, b)
 	}
 }
 
+// sliceEqual compares typed slices and arrays element-wise using JSON
+// semantics, so []string and []any holding the same strings are equal and
+// nested elements get the same comparison as top-level values. A []byte
+// compares equal to the base64 string encoding/json would produce for it.
+// The second result is false when a and b are not both slice-like.
+func sliceEqual(a, b any, eq *valueEquality) (bool, bool) {
+	if ab, ok := a.([]byte); ok {
+		if bs, ok := b.(string); ok {
+			return base64.StdEncoding.EncodeToString(ab) == bs, true
+		}
+	}
+
+	av := reflect.ValueOf(a)
+	bv := reflect.ValueOf(b)
+	if !isSliceLike(av) || !isSliceLike(bv) {
+		return false, false
+	}
+	if av.Len() != bv.Len() {
+		return false, true
+	}
+	for i := 0; i < av.Len(); i++ {
+		if !jsonEqualWith(av.Index(i).Interface(), bv.Index(i).Interface(), eq) {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+func isSliceLike(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+}
+
 func numericValue(v any) (float64, bool) {
 	switch n := v.(type) {
 	case float64:
 		return n, true
 	case float32:
-		return float64(n), true
+		return float32ToFloat64(n), true
 	case int:
 		return float64(n), true
 	case int8:
@@ -487,7 +1052,7 @@ func numericValue(v any) (float64, bool) {
 // generateArrayPatch produces patch operations to transform one array into another.
 // It first checks for a simple swap, then uses an improved diff based on the Longest Common
 // Subsequence (LCS) to generate minimal operations.
-func generateArrayPatch(basePath string, before, after any) ([]Patch, error) {
+func (c *diffConfig) generateArrayPatch(basePath string, before, after any) ([]Patch, error) {
 	beforeSlice, err := toSlice(before)
 	if err != nil {
 		return nil, err
@@ -497,33 +1062,58 @@ func generateArrayPatch(basePath string, before, after any) ([]Patch, error) {
 		return nil, err
 	}
 
+	// Elements with an ElementKey are matched by identity.
+	if beforeKeys, ok := elementKeys(before); ok {
+		if afterKeys, ok := elementKeys(after); ok {
+			return c.keyedArrayDiff(basePath, beforeSlice, afterSlice, beforeKeys, afterKeys), nil
+		}
+	}
+	if beforeKeys, ok := c.fieldKeys(beforeSlice); ok {
+		if afterKeys, ok := c.fieldKeys(afterSlice); ok {
+			return c.keyedArrayDiff(basePath, beforeSlice, afterSlice, beforeKeys, afterKeys), nil
+		}
+	}
+
 	// Check for a simple swap: if exactly two elements differ and are swapped.
 	if len(beforeSlice) == len(afterSlice) {
 		diffIndices := make([]int, 0, 2)
 		for i := 0; i < len(beforeSlice); i++ {
-			if !deepEqualFiltered(beforeSlice[i], afterSlice[i]) {
+			if !c.deepEqualFiltered(beforeSlice[i], afterSlice[i]) {
 				diffIndices = append(diffIndices, i)
 			}
 		}
 		if len(diffIndices) == 2 {
 			i, j := diffIndices[0], diffIndices[1]
-			if deepEqualFiltered(beforeSlice[i], afterSlice[j]) && deepEqualFiltered(beforeSlice[j], afterSlice[i]) {
-				return []Patch{
-					{Op: "move", Path: arrayPath(basePath, j), From: arrayPath(basePath, i)},
-				}, nil
+			if c.deepEqualFiltered(beforeSlice[i], afterSlice[j]) && c.deepEqualFiltered(beforeSlice[j], afterSlice[i]) {
+				return c.swapPatches(basePath, len(beforeSlice), i, j), nil
 			}
 		}
 	}
 
 	// Use the improved LCS-based diff algorithm.
-	return arrayDiff(basePath, beforeSlice, afterSlice)
+	return c.arrayDiff(basePath, beforeSlice, afterSlice)
 }
 
-func arrayDiff(basePath string, beforeSlice, afterSlice []any) ([]Patch, error) {
-	prefix, beforeMid, afterMid := trimCommonArrayEdges(beforeSlice, afterSlice)
+// swapPatches returns moves exchanging the elements at i < j. RFC 6902 move
+// removes then inserts, so moving i to j also shifts the elements between
+// them left by one; for adjacent elements that is the swap, otherwise a
+// second move brings the former element j (now at j-1) back to i.
+func (c *diffConfig) swapPatches(basePath string, length, i, j int) []Patch {
+	patches := []Patch{{Op: "move", Path: arrayPath(basePath, j), From: arrayPath(basePath, i)}}
+	if j > i+1 {
+		patches = append(patches, Patch{Op: "move", Path: arrayPath(basePath, i), From: arrayPath(basePath, j-1)})
+	}
+	c.recordArrayStats(length-2, 0, 0, 0, len(patches))
+	return patches
+}
+
+func (c *diffConfig) arrayDiff(basePath string, beforeSlice, afterSlice []any) ([]Patch, error) {
+	prefix, beforeMid, afterMid := c.trimCommonArrayEdges(beforeSlice, afterSlice)
 	m, n := len(beforeMid), len(afterMid)
+	trimmed := len(beforeSlice) - m
 
 	if m == 0 && n == 0 {
+		c.recordArrayStats(trimmed, 0, 0, 0, 0)
 		return nil, nil
 	}
 
@@ -532,90 +1122,100 @@ func arrayDiff(basePath string, beforeSlice, afterSlice []any) ([]Patch, error)
 	if m == n {
 		patches := make([]Patch, 0, m)
 		for i := 0; i < m; i++ {
-			if !deepEqualFiltered(beforeMid[i], afterMid[i]) {
+			if !c.deepEqualFiltered(beforeMid[i], afterMid[i]) {
 				patches = append(patches, Patch{
 					Op:    "replace",
 					Path:  arrayPath(basePath, prefix+i),
-					Value: afterMid[i],
+					Value: snapshotValue(afterMid[i]),
 				})
 			}
 		}
+		c.recordArrayStats(trimmed+m-len(patches), 0, 0, len(patches), 0)
 		return patches, nil
 	}
 
-	// Precompute equality matrix so deepEqualFiltered is called at most m*n times.
-	eq := make([]bool, m*n)
-	for i := 0; i < m; i++ {
-		for j := 0; j < n; j++ {
-			eq[i*n+j] = deepEqualFiltered(beforeMid[i], afterMid[j])
-		}
-	}
-
-	// Build LCS DP using O(n) space (two rows).
-	curr := make([]int, n+1)
-	prev := make([]int, n+1)
-	// We also need to reconstruct the LCS path, so store directional info.
-	// direction: 0 = diagonal (match), 1 = up (skip before[i]), 2 = left (skip after[j])
-	dir := make([]byte, (m+1)*(n+1))
-	for i := m - 1; i >= 0; i-- {
-		curr, prev = prev, curr
-		for j := n - 1; j >= 0; j-- {
-			switch {
-			case eq[i*n+j]:
-				curr[j] = prev[j+1] + 1
-				dir[i*(n+1)+j] = 0
-			case prev[j] >= curr[j+1]:
-				curr[j] = prev[j]
-				dir[i*(n+1)+j] = 1
-			default:
-				curr[j] = curr[j+1]
-				dir[i*(n+1)+j] = 2
-			}
-		}
-	}
+	edits, paired := c.pairSimilar(Diff(beforeMid, afterMid, c.deepEqualFiltered), beforeMid, afterMid)
 
-	// Trace back the LCS using direction table.
-	commonBefore := make([]bool, m)
-	commonAfter := make([]bool, n)
-	i, j := 0, 0
-	for i < m && j < n {
-		switch dir[i*(n+1)+j] {
-		case 0:
-			commonBefore[i] = true
-			commonAfter[j] = true
-			i++
-			j++
-		case 1:
-			i++
-		default:
-			j++
-		}
-	}
-
-	// Generate removal patches (in descending order).
+	// Removals are emitted in descending order so earlier indices stay
+	// valid; additions follow in ascending order against the shrunk array.
+	// Paired elements stay in the array throughout, so their replaces come
+	// last, at their final positions.
 	removals := make([]Patch, 0, m)
-	for i := m - 1; i >= 0; i-- {
-		if !commonBefore[i] {
+	additions := make([]Patch, 0, n)
+	lcsLength := 0
+	for _, edit := range edits {
+		switch edit.Kind {
+		case Keep:
+			lcsLength++
+		case Delete:
 			removals = append(removals, Patch{
 				Op:   "remove",
-				Path: arrayPath(basePath, prefix+i),
+				Path: arrayPath(basePath, prefix+edit.OldIndex),
 			})
-		}
-	}
-
-	// Generate addition patches (in ascending order).
-	additions := make([]Patch, 0, n)
-	for j := 0; j < n; j++ {
-		if !commonAfter[j] {
+		case Insert:
 			additions = append(additions, Patch{
 				Op:    "add",
-				Path:  arrayPath(basePath, prefix+j),
-				Value: afterMid[j],
+				Path:  arrayPath(basePath, prefix+edit.NewIndex),
+				Value: snapshotValue(afterMid[edit.NewIndex]),
 			})
 		}
 	}
+	slices.Reverse(removals)
+	patches := append(removals, additions...)
+	for _, index := range paired {
+		patches = append(patches, Patch{
+			Op:    "replace",
+			Path:  arrayPath(basePath, prefix+index),
+			Value: snapshotValue(afterMid[index]),
+		})
+	}
 
-	return append(removals, additions...), nil
+	c.recordArrayStats(trimmed+lcsLength, len(removals), len(additions), len(paired), 0)
+	return patches, nil
+}
+
+// pairSimilar drops the Delete and Insert edits of similar elements (see
+// WithSimilarityThreshold) and returns the after indices of those elements,
+// which stay in the array and are replaced in place. Within each run of
+// edits between two Keeps the k-th deleted element is paired with the k-th
+// inserted one, which preserves the order of the remaining elements.
+func (c *diffConfig) pairSimilar(edits []Edit, before, after []any) ([]Edit, []int) {
+	if c.similarity <= 0 {
+		return edits, nil
+	}
+	var paired []int
+	out := make([]Edit, 0, len(edits))
+	for start := 0; start < len(edits); {
+		if edits[start].Kind == Keep {
+			out = append(out, edits[start])
+			start++
+			continue
+		}
+		end := start
+		var deletes, inserts []Edit
+		for ; end < len(edits) && edits[end].Kind != Keep; end++ {
+			if edits[end].Kind == Delete {
+				deletes = append(deletes, edits[end])
+			} else {
+				inserts = append(inserts, edits[end])
+			}
+		}
+		for k := range deletes {
+			if k < len(inserts) && c.similar(before[deletes[k].OldIndex], after[inserts[k].NewIndex]) {
+				paired = append(paired, inserts[k].NewIndex)
+				inserts[k].Kind = Keep
+				continue
+			}
+			out = append(out, deletes[k])
+		}
+		for _, insert := range inserts {
+			if insert.Kind == Insert {
+				out = append(out, insert)
+			}
+		}
+		start = end
+	}
+	return out, paired
 }
 
 func arrayPath(basePath string, index int) string {
@@ -628,20 +1228,20 @@ func arrayPath(basePath string, index int) string {
 	return builder.String()
 }
 
-func trimCommonArrayEdges(beforeSlice, afterSlice []any) (int, []any, []any) {
+func (c *diffConfig) trimCommonArrayEdges(beforeSlice, afterSlice []any) (int, []any, []any) {
 	maxPrefix := len(beforeSlice)
 	if len(afterSlice) < maxPrefix {
 		maxPrefix = len(afterSlice)
 	}
 
 	prefix := 0
-	for prefix < maxPrefix && deepEqualFiltered(beforeSlice[prefix], afterSlice[prefix]) {
+	for prefix < maxPrefix && c.deepEqualFiltered(beforeSlice[prefix], afterSlice[prefix]) {
 		prefix++
 	}
 
 	beforeEnd := len(beforeSlice)
 	afterEnd := len(afterSlice)
-	for beforeEnd > prefix && afterEnd > prefix && deepEqualFiltered(beforeSlice[beforeEnd-1], afterSlice[afterEnd-1]) {
+	for beforeEnd > prefix && afterEnd > prefix && c.deepEqualFiltered(beforeSlice[beforeEnd-1], afterSlice[afterEnd-1]) {
 		beforeEnd--
 		afterEnd--
 	}
@@ -800,11 +1400,118 @@ func traverseToParentWithBounds(target map[string]any, parts []string, strictBou
 
 //revive:enable:indent-error-flow
 
+// hasNestedArray reports whether the container addressed by parts is an
+// array held directly inside another array (as in /grid/1/0). The map-keyed
+// traversal addresses arrays through their parent object, so such paths are
+// handled by updateNested and getNested instead.
+func hasNestedArray(target map[string]any, parts []string) bool {
+	var node any = target
+	for i := 0; i < len(parts)-1; i++ {
+		switch container := node.(type) {
+		case map[string]any:
+			node = container[parts[i]]
+		case []any:
+			idx, err := strconv.Atoi(parts[i])
+			if err != nil || idx < 0 || idx >= len(container) {
+				return false
+			}
+			if _, ok := container[idx].([]any); ok {
+				return true
+			}
+			node = container[idx]
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// updateNested walks parts through objects and arrays and replaces the
+// array holding the final segment with the result of fn. Arrays are values
+// rather than references, so each updated array is written back into its
+// parent on the way out.
+func updateNested(target map[string]any, parts []string, fn func(container []any, idx string) ([]any, error)) error {
+	_, err := updateNestedValue(target, parts, fn)
+	return err
+}
+
+func updateNestedValue(node any, parts []string, fn func(container []any, idx string) ([]any, error)) (any, error) {
+	if len(parts) == 1 {
+		container, ok := node.([]any)
+		if !ok {
+			return nil, fmt.Errorf("expected array at index %s", parts[0])
+		}
+		return fn(container, parts[0])
+	}
+	child, ok := getNested(node, parts[:1])
+	if !ok {
+		return nil, fmt.Errorf("path %s does not exist", parts[0])
+	}
+	updated, err := updateNestedValue(child, parts[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+	switch container := node.(type) {
+	case map[string]any:
+		container[parts[0]] = updated
+	case []any:
+		idx, _ := strconv.Atoi(parts[0])
+		container[idx] = updated
+	}
+	return node, nil
+}
+
+// getNested returns the value at parts beneath node, descending through
+// objects and arrays alike.
+func getNested(node any, parts []string) (any, bool) {
+	for _, part := range parts {
+		switch container := node.(type) {
+		case map[string]any:
+			value, ok := container[part]
+			if !ok {
+				return nil, false
+			}
+			node = value
+		case []any:
+			idx, err := parseArrayIndex(part, len(container), false)
+			if err != nil {
+				return nil, false
+			}
+			node = container[idx]
+		default:
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// parseArrayIndex parses an array index; allowEnd permits index == length
+// for insertion.
+func parseArrayIndex(part string, length int, allowEnd bool) (int, error) {
+	idx, err := strconv.Atoi(part)
+	if err != nil || idx < 0 || idx > length || (idx == length && !allowEnd) {
+		return -1, fmt.Errorf("invalid index %s", part)
+	}
+	return idx, nil
+}
+
 // applyAdd applies an "add" operation at the given path with the specified value.
 func applyAdd(target map[string]any, parts []string, value any) error {
 	if len(parts) == 0 {
 		return replaceRootObject(target, value)
 	}
+	if hasNestedArray(target, parts) {
+		return updateNested(target, parts, func(container []any, idx string) ([]any, error) {
+			if idx == "-" {
+				return append(container, value), nil
+			}
+			i, err := parseArrayIndex(idx, len(container), true)
+			if err != nil {
+				return nil, err
+			}
+			return sliceInsert(container, i, value), nil
+		})
+	}
 
 	// Otherwise, traverse to the parent container with lenient bounds for add operations.
 	parent, key, isArr, idx, err := traverseToParentForAdd(target, parts)
@@ -842,6 +1549,15 @@ func applyRemove(target map[string]any, parts []string) error {
 	if len(parts) == 0 {
 		return fmt.Errorf("cannot remove document root")
 	}
+	if hasNestedArray(target, parts) {
+		return updateNested(target, parts, func(container []any, idx string) ([]any, error) {
+			i, err := parseArrayIndex(idx, len(container), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(container[:i], container[i+1:]...), nil
+		})
+	}
 
 	if key, idx, err := isTwoPartArray(target, parts); err == nil {
 		arr := target[key].([]any)
@@ -881,6 +1597,16 @@ func applyReplace(target map[string]any, parts []string, value any) error {
 	if len(parts) == 0 {
 		return replaceRootObject(target, value)
 	}
+	if hasNestedArray(target, parts) {
+		return updateNested(target, parts, func(container []any, idx string) ([]any, error) {
+			i, err := parseArrayIndex(idx, len(container), false)
+			if err != nil {
+				return nil, err
+			}
+			container[i] = value
+			return container, nil
+		})
+	}
 
 	if key, idx, err := isTwoPartArray(target, parts); err == nil {
 		arr := target[key].([]any)
@@ -942,7 +1668,12 @@ func applyMove(target map[string]any, fromParts, toParts []string) error {
 	}
 	var value any
 	// Retrieve the value from the "from" path.
-	if key, idx, err := isTwoPartArray(target, fromParts); err == nil {
+	if hasNestedArray(target, fromParts) {
+		var exists bool
+		if value, exists = getValue(target, fromParts); !exists {
+			return fmt.Errorf("path %s does not exist", strings.Join(fromParts, "/"))
+		}
+	} else if key, idx, err := isTwoPartArray(target, fromParts); err == nil {
 		value = target[key].([]any)[idx]
 	} else {
 		parent, key, isArr, idx, err := traverseToParent(target, fromParts)
@@ -982,6 +1713,9 @@ func getValue(target map[string]any, parts []string) (any, bool) {
 	if len(parts) == 0 {
 		return target, true
 	}
+	if hasNestedArray(target, parts) {
+		return getNested(target, parts)
+	}
 
 	if key, idx, err := isTwoPartArray(target, parts); err == nil {
 		arr := target[key].([]any)
@@ -1033,6 +1767,48 @@ func applyCopy(target map[string]any, fromParts, toParts []string) error {
 	return applyAdd(target, toParts, value)
 }
 
+// snapshotValue copies a value captured into a generated patch so later
+// mutations of the source document do not leak into the patch. Containers
+// are copied recursively; typed slices, maps, pointers, and structs are
+// converted to their JSON-like form. Values with custom JSON or text
+// marshaling are kept as they are.
+func snapshotValue(v any) any {
+	switch val := v.(type) {
+	case nil, string, bool, float64, int, int64, int32:
+		return v
+	case map[string]any:
+		cp := make(map[string]any, len(val))
+		for key, item := range val {
+			cp[key] = snapshotValue(item)
+		}
+		return cp
+	case []any:
+		cp := make([]any, len(val))
+		for i, item := range val {
+			cp[i] = snapshotValue(item)
+		}
+		return cp
+	case *OrderedObject:
+		return orderedValue(val)
+	case json.Marshaler, encoding.TextMarshaler:
+		return v
+	}
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Pointer, reflect.Struct:
+		converted := convertValue(v)
+		switch converted.(type) {
+		case map[string]any, []any:
+			return snapshotValue(converted)
+		}
+		return converted
+	case reflect.Invalid, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.Chan, reflect.Func, reflect.Interface, reflect.String, reflect.UnsafePointer:
+	}
+	return v
+}
+
 // deepCopyValue creates a deep copy of an arbitrary JSON-like value.
 func deepCopyValue(v any) any {
 	switch val := v.(type) {
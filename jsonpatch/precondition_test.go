@@ -0,0 +1,85 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldApplyPatchGivenPreconditionsHold(t *testing.T) {
+	// Arrange
+	doc := map[string]any{"version": 3.0, "title": "Draft"}
+	preconditions := []Patch{{Op: "test", Path: "/version", Value: 3}}
+	patches := []Patch{
+		{Op: "replace", Path: "/title", Value: "Final"},
+		{Op: "replace", Path: "/version", Value: 4},
+	}
+
+	// Act
+	result, err := ApplyWithPrecondition(doc, patches, preconditions)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"version": 4, "title": "Final"}, result)
+	assert.Equal(t, 3.0, doc["version"])
+}
+
+func TestShouldBlockUpdateGivenStalePrecondition(t *testing.T) {
+	// Arrange
+	doc := map[string]any{"version": 4.0, "title": "Edited elsewhere"}
+	preconditions := []Patch{{Op: "test", Path: "/version", Value: 3}}
+	patches := []Patch{{Op: "replace", Path: "/title", Value: "Final"}}
+
+	// Act
+	result, err := ApplyWithPrecondition(doc, patches, preconditions)
+
+	// Assert
+	require.ErrorIs(t, err, ErrPreconditionFailed)
+	assert.ErrorContains(t, err, "value at version is 4, expected 3")
+	assert.Nil(t, result)
+	assert.Equal(t, "Edited elsewhere", doc["title"])
+}
+
+func TestShouldRejectPreconditionGivenNonTestOperation(t *testing.T) {
+	tests := []struct {
+		name         string
+		precondition Patch
+		wantErr      string
+	}{
+		{
+			name:         "replace",
+			precondition: Patch{Op: "replace", Path: "/title", Value: "x"},
+			wantErr:      "invalid precondition 0: replace is not a test operation",
+		},
+		{
+			name:         "guard without option",
+			precondition: Patch{Op: GuardExists, Path: "/title"},
+			wantErr:      "invalid precondition 0: unsupported op: exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := ApplyWithPrecondition(map[string]any{"title": "a"}, nil, []Patch{tt.precondition})
+
+			// Assert
+			require.EqualError(t, err, tt.wantErr)
+			assert.NotErrorIs(t, err, ErrPreconditionFailed)
+		})
+	}
+}
+
+func TestShouldEvaluateGuardPreconditionsGivenWithGuards(t *testing.T) {
+	// Arrange
+	doc := map[string]any{"title": "a", "lock": "held"}
+	patches := []Patch{{Op: "replace", Path: "/title", Value: "b"}}
+
+	// Act
+	_, err := ApplyWithPrecondition(doc, patches, []Patch{{Op: GuardAbsent, Path: "/lock"}}, WithGuards())
+
+	// Assert
+	require.ErrorIs(t, err, ErrPreconditionFailed)
+	assert.ErrorContains(t, err, "guard failed: path /lock exists")
+}
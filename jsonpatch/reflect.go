@@ -0,0 +1,447 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TypeMismatchError reports a patch value that cannot be stored in the Go
+// value at Path, for example a string written to an int field.
+type TypeMismatchError struct {
+	Path  string
+	Value any
+	Type  reflect.Type
+}
+
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("type mismatch at %s: cannot assign %T to %s", e.Path, e.Value, e.Type)
+}
+
+// ApplyPatchReflect applies patches directly to the value target points to,
+// navigating exported struct fields (by JSON name, as encoding/json would),
+// maps with string keys, slices, and arrays by reflection instead of
+// round-tripping the whole document through JSON.
+//
+// Values are converted to the destination type: JSON numbers convert to any
+// numeric kind when they fit exactly, nil clears pointers, maps, slices, and
+// interfaces, and other values fall back to a JSON conversion of just that
+// value (so strings hydrate time.Time or uuid.UUID fields). Values that do
+// not fit return a *TypeMismatchError.
+//
+// Struct fields cannot be deleted, so remove sets a field to its zero value
+// and add on a field behaves like replace. Operations are applied in place:
+// when one fails, the preceding operations remain applied.
+func ApplyPatchReflect(target any, patches []Patch, opts ...ApplyOption) error {
+	root := reflect.ValueOf(target)
+	if root.Kind() != reflect.Pointer || root.IsNil() {
+		return fmt.Errorf("target must be a non-nil pointer, got %T", target)
+	}
+	cfg := newApplyConfig(opts)
+	for _, op := range patches {
+		if err := cfg.applyReflectOperation(root.Elem(), op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *applyConfig) applyReflectOperation(root reflect.Value, op Patch) error {
+	parts, err := parsePath(op.Path)
+	if err != nil {
+		return err
+	}
+	value, err := decodeRawValue(op.Value)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s %s: %w", op.Op, op.Path, err)
+	}
+
+	switch c.missingOp(op.Op, func() bool { _, err := reflectGet(root, parts); return err == nil }) {
+	case "":
+		return nil
+	case "add":
+		return reflectAdd(root, parts, value)
+	case "replace":
+		return reflectReplace(root, parts, value)
+	case "remove":
+		_, err := reflectRemove(root, parts)
+		return err
+	case "test":
+		current, err := reflectGet(root, parts)
+		if err != nil {
+			return err
+		}
+		if !jsonEqual(convertValue(current.Interface()), convertValue(value)) {
+			return fmt.Errorf("test failed: value at %s is %v, expected %v", op.Path, current.Interface(), value)
+		}
+		return nil
+	case "move", "copy":
+		fromParts, err := c.fromParts(op)
+		if err != nil {
+			return err
+		}
+		var moved reflect.Value
+		if op.Op == "move" {
+			moved, err = reflectRemove(root, fromParts)
+		} else {
+			moved, err = reflectGet(root, fromParts)
+		}
+		if err != nil {
+			return err
+		}
+		return reflectAdd(root, parts, convertValue(moved.Interface()))
+	case GuardExists, GuardAbsent:
+		if !c.guards {
+			return fmt.Errorf("unsupported op: %s", op.Op)
+		}
+		_, err := reflectGet(root, parts)
+		return checkGuard(op.Op, parts, err == nil)
+	default:
+		return fmt.Errorf("unsupported op: %s", op.Op)
+	}
+}
+
+// reflectGet returns the value at parts. A nil pointer along the way is a
+// missing path; reads never allocate.
+func reflectGet(root reflect.Value, parts []string) (reflect.Value, error) {
+	return reflectWalk(root, parts, false)
+}
+
+func reflectWalk(root reflect.Value, parts []string, allocate bool) (reflect.Value, error) {
+	current := root
+	for i, part := range parts {
+		next, err := reflectChild(current, part, pointerOf(parts[:i+1]), allocate)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// reflectParent returns the container holding the last segment of parts.
+// When allocate is set, nil pointers along the way are allocated so an add
+// can create the intermediate structs; otherwise they are a missing path.
+func reflectParent(root reflect.Value, parts []string, allocate bool) (reflect.Value, error) {
+	if len(parts) == 0 {
+		return reflect.Value{}, fmt.Errorf("operation on the root is not supported")
+	}
+	parent, err := reflectWalk(root, parts[:len(parts)-1], allocate)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return deref(parent, allocate), nil
+}
+
+func reflectChild(container reflect.Value, part, path string, allocate bool) (reflect.Value, error) {
+	container = deref(container, allocate)
+	switch container.Kind() {
+	case reflect.Struct:
+		if field, ok := fieldByJSONName(container, part, allocate); ok {
+			return field, nil
+		}
+	case reflect.Map:
+		if container.Type().Key().Kind() == reflect.String {
+			if item := container.MapIndex(reflect.ValueOf(part).Convert(container.Type().Key())); item.IsValid() {
+				return item, nil
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		idx, err := parseArrayIndex(part, container.Len(), false)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("path %s does not exist: %w", path, err)
+		}
+		return container.Index(idx), nil
+	case reflect.Invalid, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.Chan, reflect.Func, reflect.Interface, reflect.Pointer, reflect.String, reflect.UnsafePointer:
+	}
+	return reflect.Value{}, fmt.Errorf("path %s does not exist", path)
+}
+
+func reflectAdd(root reflect.Value, parts []string, value any) error {
+	parent, err := reflectParent(root, parts, true)
+	if err != nil {
+		return err
+	}
+	key := parts[len(parts)-1]
+	path := pointerOf(parts)
+
+	switch parent.Kind() {
+	case reflect.Map:
+		return reflectSetMapIndex(parent, key, value, path)
+	case reflect.Slice:
+		idx := parent.Len()
+		if key != "-" {
+			if idx, err = parseArrayIndex(key, parent.Len(), true); err != nil {
+				return fmt.Errorf("path %s: %w", path, err)
+			}
+		}
+		elem := reflect.New(parent.Type().Elem()).Elem()
+		if err := assignValue(elem, value, path); err != nil {
+			return err
+		}
+		if !parent.CanSet() {
+			return fmt.Errorf("path %s: slice is not settable", path)
+		}
+		grown := reflect.Append(parent, elem)
+		reflect.Copy(grown.Slice(idx+1, grown.Len()), parent.Slice(idx, parent.Len()))
+		grown.Index(idx).Set(elem)
+		parent.Set(grown)
+		return nil
+	case reflect.Invalid, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.Array,
+		reflect.Chan, reflect.Func, reflect.Interface, reflect.Pointer, reflect.String, reflect.Struct, reflect.UnsafePointer:
+	}
+	return reflectReplace(root, parts, value)
+}
+
+func reflectReplace(root reflect.Value, parts []string, value any) error {
+	parent, err := reflectParent(root, parts, false)
+	if err != nil {
+		return err
+	}
+	path := pointerOf(parts)
+	if parent.Kind() == reflect.Map {
+		if _, err := reflectChild(parent, parts[len(parts)-1], path, false); err != nil {
+			return err
+		}
+		return reflectSetMapIndex(parent, parts[len(parts)-1], value, path)
+	}
+	dst, err := reflectChild(parent, parts[len(parts)-1], path, false)
+	if err != nil {
+		return err
+	}
+	if !dst.CanSet() {
+		return fmt.Errorf("path %s is not settable", path)
+	}
+	return assignValue(dst, matchExistingNumber(dst, value), path)
+}
+
+// reflectRemove removes the value at parts and returns a copy of it.
+func reflectRemove(root reflect.Value, parts []string) (reflect.Value, error) {
+	parent, err := reflectParent(root, parts, false)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	key := parts[len(parts)-1]
+	path := pointerOf(parts)
+	current, err := reflectChild(parent, key, path, false)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	removed := reflect.New(current.Type()).Elem()
+	removed.Set(current)
+
+	switch parent.Kind() {
+	case reflect.Map:
+		parent.SetMapIndex(reflect.ValueOf(key).Convert(parent.Type().Key()), reflect.Value{})
+	case reflect.Slice:
+		if !parent.CanSet() {
+			return reflect.Value{}, fmt.Errorf("path %s: slice is not settable", path)
+		}
+		idx, _ := parseArrayIndex(key, parent.Len(), false)
+		parent.Set(reflect.AppendSlice(parent.Slice(0, idx), parent.Slice(idx+1, parent.Len())))
+	case reflect.Invalid, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.Array,
+		reflect.Chan, reflect.Func, reflect.Interface, reflect.Pointer, reflect.String, reflect.Struct, reflect.UnsafePointer:
+		if !current.CanSet() {
+			return reflect.Value{}, fmt.Errorf("path %s is not settable", path)
+		}
+		current.Set(reflect.Zero(current.Type()))
+	}
+	return removed, nil
+}
+
+func reflectSetMapIndex(m reflect.Value, key string, value any, path string) error {
+	if m.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("path %s: map key type %s is not a string", path, m.Type().Key())
+	}
+	if m.IsNil() {
+		if !m.CanSet() {
+			return fmt.Errorf("path %s: map is nil", path)
+		}
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	mapKey := reflect.ValueOf(key).Convert(m.Type().Key())
+	if existing := m.MapIndex(mapKey); existing.IsValid() {
+		value = matchExistingNumber(existing, value)
+	}
+	elem := reflect.New(m.Type().Elem()).Elem()
+	if err := assignValue(elem, value, path); err != nil {
+		return err
+	}
+	m.SetMapIndex(mapKey, elem)
+	return nil
+}
+
+// matchExistingNumber converts a numeric value to the dynamic type of the
+// number an interface-typed location (such as a map[string]any entry)
+// already holds, so replacing an int with a float64 decoded from JSON keeps
+// it an int. Values that cannot be represented exactly in that type are
+// returned unchanged, since the location accepts any type; statically typed
+// locations are converted by assignValue instead.
+func matchExistingNumber(existing reflect.Value, value any) any {
+	if value == nil || existing.Kind() != reflect.Interface {
+		return value
+	}
+	for existing.Kind() == reflect.Interface {
+		if existing.IsNil() {
+			return value
+		}
+		existing = existing.Elem()
+	}
+	src := reflect.ValueOf(value)
+	if src.Type() == existing.Type() {
+		return value
+	}
+	if converted, ok := convertNumber(src, existing.Type()); ok {
+		return converted.Interface()
+	}
+	return value
+}
+
+// deref follows pointers and interfaces. When allocate is set, nil pointers
+// that are settable are allocated so writes can reach the value beneath
+// them; otherwise a nil pointer is returned as is.
+func deref(v reflect.Value, allocate bool) reflect.Value {
+	for {
+		switch v.Kind() {
+		case reflect.Pointer:
+			if v.IsNil() {
+				if !allocate || !v.CanSet() {
+					return v
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		case reflect.Interface:
+			if v.IsNil() {
+				return v
+			}
+			v = v.Elem()
+		default:
+			return v
+		}
+	}
+}
+
+// fieldByJSONName finds the exported field encoding/json would decode the
+// key name into, searching promoted fields of embedded structs. Fields
+// promoted through a nil embedded pointer are only reachable when allocate
+// is set.
+func fieldByJSONName(v reflect.Value, name string, allocate bool) (reflect.Value, bool) {
+	var folded reflect.Value
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		tagName, _, _ := strings.Cut(tag, ",")
+		if tag == "-" || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+		if field.Anonymous && tagName == "" {
+			embedded := deref(v.Field(i), allocate)
+			if embedded.Kind() == reflect.Struct {
+				if found, ok := fieldByJSONName(embedded, name, allocate); ok {
+					return found, true
+				}
+				continue
+			}
+			if embedded.Kind() == reflect.Pointer && embedded.Type().Elem().Kind() == reflect.Struct {
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if tagName == "" {
+			tagName = field.Name
+		}
+		if tagName == name {
+			return v.Field(i), true
+		}
+		if !folded.IsValid() && strings.EqualFold(tagName, name) {
+			folded = v.Field(i)
+		}
+	}
+	return folded, folded.IsValid()
+}
+
+// assignValue stores value in dst, converting it to dst's type.
+func assignValue(dst reflect.Value, value any, path string) error {
+	if value == nil {
+		switch dst.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		case reflect.Invalid, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.Array,
+			reflect.Chan, reflect.Func, reflect.String, reflect.Struct, reflect.UnsafePointer:
+		}
+		return &TypeMismatchError{Path: path, Value: value, Type: dst.Type()}
+	}
+
+	src := reflect.ValueOf(value)
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+	if converted, ok := convertNumber(src, dst.Type()); ok {
+		dst.Set(converted)
+		return nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return &TypeMismatchError{Path: path, Value: value, Type: dst.Type()}
+	}
+	decoded := reflect.New(dst.Type())
+	if err := json.Unmarshal(encoded, decoded.Interface()); err != nil {
+		return &TypeMismatchError{Path: path, Value: value, Type: dst.Type()}
+	}
+	dst.Set(decoded.Elem())
+	return nil
+}
+
+// convertNumber converts a numeric src to the numeric type t when the value
+// is represented exactly.
+func convertNumber(src reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	if !isNumericKind(src.Kind()) || !isNumericKind(t.Kind()) {
+		return reflect.Value{}, false
+	}
+	converted := src.Convert(t)
+	if !converted.Convert(src.Type()).Equal(src) {
+		return reflect.Value{}, false
+	}
+	negative := (src.CanInt() && src.Int() < 0) || (src.CanFloat() && src.Float() < 0)
+	if negative && converted.CanUint() {
+		return reflect.Value{}, false
+	}
+	return converted, true
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Invalid, reflect.Bool, reflect.Complex64, reflect.Complex128, reflect.Array, reflect.Chan, reflect.Func,
+		reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice, reflect.String, reflect.Struct, reflect.UnsafePointer:
+	}
+	return false
+}
+
+func pointerOf(parts []string) string {
+	escaped := make([]string, len(parts))
+	for i, part := range parts {
+		escaped[i] = escapePathSegment(part)
+	}
+	return "/" + strings.Join(escaped, "/")
+}
@@ -0,0 +1,183 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reflectAudit struct {
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type reflectAddress struct {
+	City string `json:"city"`
+}
+
+type reflectPerson struct {
+	reflectAudit
+	Name    string            `json:"name"`
+	Age     int               `json:"age"`
+	Score   uint8             `json:"score"`
+	Tags    []string          `json:"tags"`
+	Labels  map[string]string `json:"labels"`
+	Address *reflectAddress   `json:"address,omitempty"`
+	secret  string
+}
+
+func TestShouldApplyReplaceToStructFieldGivenApplyPatchReflect(t *testing.T) {
+	// Arrange
+	person := &reflectPerson{Name: "Alice", Age: 30}
+
+	// Act
+	err := ApplyPatchReflect(person, []Patch{{Op: "replace", Path: "/age", Value: 31}})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 31, person.Age)
+	assert.Equal(t, "Alice", person.Name)
+}
+
+func TestShouldApplyOperationsInPlaceGivenApplyPatchReflect(t *testing.T) {
+	// Arrange
+	person := &reflectPerson{Name: "Alice", Tags: []string{"a", "c"}, Labels: map[string]string{"team": "core"}}
+	var patches []Patch
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"op":"test","path":"/name","value":"Alice"},
+		{"op":"replace","path":"/score","value":42},
+		{"op":"add","path":"/tags/1","value":"b"},
+		{"op":"add","path":"/tags/-","value":"d"},
+		{"op":"remove","path":"/tags/0"},
+		{"op":"add","path":"/labels/tier","value":"gold"},
+		{"op":"remove","path":"/labels/team"},
+		{"op":"add","path":"/address/city","value":"Oslo"},
+		{"op":"replace","path":"/updatedAt","value":"2024-01-02T03:04:05Z"},
+		{"op":"copy","from":"/address/city","path":"/name"}
+	]`), &patches))
+
+	// Act
+	err := ApplyPatchReflect(person, patches)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Oslo", person.Name)
+	assert.Equal(t, uint8(42), person.Score)
+	assert.Equal(t, []string{"b", "c", "d"}, person.Tags)
+	assert.Equal(t, map[string]string{"tier": "gold"}, person.Labels)
+	require.NotNil(t, person.Address)
+	assert.Equal(t, "Oslo", person.Address.City)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), person.UpdatedAt)
+}
+
+func TestShouldReturnErrorGivenInvalidApplyPatchReflect(t *testing.T) {
+	tests := []struct {
+		name     string
+		patch    Patch
+		mismatch bool
+	}{
+		{name: "string into int", patch: Patch{Op: "replace", Path: "/age", Value: "old"}, mismatch: true},
+		{name: "fraction into int", patch: Patch{Op: "replace", Path: "/age", Value: 30.5}, mismatch: true},
+		{name: "negative into uint", patch: Patch{Op: "replace", Path: "/score", Value: -1}, mismatch: true},
+		{name: "overflow", patch: Patch{Op: "replace", Path: "/score", Value: 300}, mismatch: true},
+		{name: "null into int", patch: Patch{Op: "replace", Path: "/age", Value: nil}, mismatch: true},
+		{name: "unknown field", patch: Patch{Op: "replace", Path: "/nickname", Value: "Al"}},
+		{name: "unexported field", patch: Patch{Op: "replace", Path: "/secret", Value: "x"}},
+		{name: "index out of range", patch: Patch{Op: "replace", Path: "/tags/5", Value: "x"}},
+		{name: "failed test", patch: Patch{Op: "test", Path: "/name", Value: "Bob"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			person := &reflectPerson{Name: "Alice", Age: 30, secret: "s"}
+
+			// Act
+			err := ApplyPatchReflect(person, []Patch{tt.patch})
+
+			// Assert
+			require.Error(t, err)
+			var mismatch *TypeMismatchError
+			assert.Equal(t, tt.mismatch, errors.As(err, &mismatch))
+			assert.Equal(t, 30, person.Age)
+			assert.Equal(t, "s", person.secret)
+		})
+	}
+}
+
+func TestShouldNotAllocateNilPointerGivenReadOnlyApplyPatchReflect(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   Patch
+		wantErr bool
+	}{
+		{name: "test", patch: Patch{Op: "test", Path: "/address/city", Value: ""}, wantErr: true},
+		{name: "exists guard", patch: Patch{Op: GuardExists, Path: "/address/city"}, wantErr: true},
+		{name: "absent guard", patch: Patch{Op: GuardAbsent, Path: "/address/city"}},
+		{name: "replace", patch: Patch{Op: "replace", Path: "/address/city", Value: "Oslo"}, wantErr: true},
+		{name: "remove", patch: Patch{Op: "remove", Path: "/address/city"}, wantErr: true},
+		{name: "copy from", patch: Patch{Op: "copy", From: "/address/city", Path: "/name"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			person := &reflectPerson{Name: "Alice"}
+
+			// Act
+			err := ApplyPatchReflect(person, []Patch{tt.patch}, WithGuards())
+
+			// Assert
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Nil(t, person.Address)
+		})
+	}
+}
+
+func TestShouldRejectNonPointerTargetGivenApplyPatchReflect(t *testing.T) {
+	// Act
+	err := ApplyPatchReflect(reflectPerson{}, []Patch{{Op: "replace", Path: "/age", Value: 1}})
+
+	// Assert
+	assert.ErrorContains(t, err, "non-nil pointer")
+}
+
+func TestShouldCoerceFloatPatchValueToExistingNumberGivenApplyPatchReflect(t *testing.T) {
+	// Arrange
+	type metrics struct {
+		Count  int            `json:"count"`
+		Limits map[string]any `json:"limits"`
+		Values []any          `json:"values"`
+		Extra  any            `json:"extra"`
+	}
+	target := &metrics{
+		Count:  1,
+		Limits: map[string]any{"max": 10, "ratio": float32(0.5)},
+		Values: []any{int64(7)},
+		Extra:  uint8(1),
+	}
+	var patches []Patch
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"op":"replace","path":"/count","value":2},
+		{"op":"replace","path":"/limits/max","value":20},
+		{"op":"add","path":"/limits/ratio","value":0.25},
+		{"op":"replace","path":"/values/0","value":8},
+		{"op":"replace","path":"/extra","value":2.5}
+	]`), &patches))
+	require.IsType(t, float64(0), patches[0].Value)
+
+	// Act
+	err := ApplyPatchReflect(target, patches)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, target.Count)
+	assert.Equal(t, map[string]any{"max": 20, "ratio": float32(0.25)}, target.Limits)
+	assert.Equal(t, []any{int64(8)}, target.Values)
+	assert.Equal(t, 2.5, target.Extra)
+}
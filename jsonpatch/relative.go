@@ -0,0 +1,90 @@
+package jsonpatch
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// WithRelativePointers lets the from member of move and copy operations be a
+// Relative JSON Pointer (draft-handrews-relative-json-pointer), resolved
+// against the operation's path: "1/foo" with path "/a/b" refers to "/a/foo",
+// and "0-1" with path "/items/3" refers to "/items/2". Values starting with
+// "/" (and the empty root pointer) are still read as absolute pointers. The
+// "#" key-name form is rejected, as it does not address a value.
+func WithRelativePointers() ApplyOption {
+	return func(c *applyConfig) {
+		c.relative = true
+	}
+}
+
+// fromParts parses the from member of op, resolving relative pointers when
+// enabled.
+func (c *applyConfig) fromParts(op Patch) ([]string, error) {
+	if c.relative && op.From != "" && !strings.HasPrefix(op.From, "/") {
+		return resolveRelativePointer(op.Path, op.From)
+	}
+	return parsePath(op.From)
+}
+
+// resolveRelativePointer resolves the Relative JSON Pointer rel against the
+// JSON Pointer base and returns the path segments it addresses.
+func resolveRelativePointer(base, rel string) ([]string, error) {
+	digits := 0
+	for digits < len(rel) && rel[digits] >= '0' && rel[digits] <= '9' {
+		digits++
+	}
+	if digits == 0 || (rel[0] == '0' && digits > 1) {
+		return nil, fmt.Errorf("invalid relative JSON pointer %q", rel)
+	}
+	up, err := strconv.Atoi(rel[:digits])
+	if err != nil {
+		return nil, fmt.Errorf("invalid relative JSON pointer %q: %w", rel, err)
+	}
+	rest := rel[digits:]
+
+	delta := 0
+	if rest != "" && (rest[0] == '+' || rest[0] == '-') {
+		end := 1
+		for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+			end++
+		}
+		if delta, err = strconv.Atoi(rest[:end]); err != nil {
+			return nil, fmt.Errorf("invalid index adjustment in relative JSON pointer %q", rel)
+		}
+		rest = rest[end:]
+	}
+	if rest == "#" {
+		return nil, fmt.Errorf("relative JSON pointer %q names a key, not a value", rel)
+	}
+	if rest != "" && rest[0] != '/' {
+		return nil, fmt.Errorf("invalid relative JSON pointer %q", rel)
+	}
+
+	baseParts, err := parsePath(base)
+	if err != nil {
+		return nil, err
+	}
+	if up > len(baseParts) {
+		return nil, fmt.Errorf("relative JSON pointer %q climbs above the root of %q", rel, base)
+	}
+	parts := slices.Clone(baseParts[:len(baseParts)-up])
+
+	if delta != 0 {
+		if len(parts) == 0 {
+			return nil, fmt.Errorf("relative JSON pointer %q adjusts the index of the root", rel)
+		}
+		idx, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil || idx+delta < 0 {
+			return nil, fmt.Errorf("relative JSON pointer %q adjusts a non-index segment or below zero", rel)
+		}
+		parts[len(parts)-1] = strconv.Itoa(idx + delta)
+	}
+
+	suffix, err := parsePath(rest)
+	if err != nil {
+		return nil, err
+	}
+	return append(parts, suffix...), nil
+}
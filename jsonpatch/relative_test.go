@@ -0,0 +1,72 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldCopyFromRelativePointerGivenWithRelativePointers(t *testing.T) {
+	// Arrange
+	doc := map[string]any{
+		"user": map[string]any{"name": "Alice", "billing": map[string]any{}},
+	}
+	patches := []Patch{{Op: "copy", From: "2/name", Path: "/user/billing/name"}}
+
+	// Act
+	result, err := ApplyPatch(doc, patches, WithRelativePointers())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "Alice"}, result["user"].(map[string]any)["billing"])
+}
+
+func TestShouldRejectRelativeFromGivenDefaultOptions(t *testing.T) {
+	// Arrange
+	doc := map[string]any{"a": 1.0}
+	patches := []Patch{{Op: "copy", From: "0/a", Path: "/b"}}
+
+	// Act
+	_, err := ApplyPatch(doc, patches)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestShouldResolveRelativePointers(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		rel     string
+		want    []string
+		wantErr string
+	}{
+		{name: "same location", base: "/a/b", rel: "0", want: []string{"a", "b"}},
+		{name: "sibling", base: "/a/b", rel: "1/c", want: []string{"a", "c"}},
+		{name: "root", base: "/a/b", rel: "2", want: []string{}},
+		{name: "escaped suffix", base: "/a", rel: "1/x~1y", want: []string{"x/y"}},
+		{name: "previous element", base: "/items/3", rel: "0-1", want: []string{"items", "2"}},
+		{name: "next element field", base: "/items/0/name", rel: "1+2/name", want: []string{"items", "2", "name"}},
+		{name: "climbs above root", base: "/a", rel: "2/b", wantErr: "climbs above the root"},
+		{name: "key name form", base: "/a/b", rel: "1#", wantErr: "names a key"},
+		{name: "leading zero", base: "/a", rel: "01/b", wantErr: "invalid relative JSON pointer"},
+		{name: "missing prefix", base: "/a", rel: "b", wantErr: "invalid relative JSON pointer"},
+		{name: "index below zero", base: "/items/0", rel: "0-1", wantErr: "below zero"},
+		{name: "index of member", base: "/a/b", rel: "0+1", wantErr: "non-index segment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			parts, err := resolveRelativePointer(tt.base, tt.rel)
+
+			// Assert
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, parts)
+		})
+	}
+}
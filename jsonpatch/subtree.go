@@ -0,0 +1,62 @@
+package jsonpatch
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSubtreeNotFound is returned (wrapped) by GenerateSubtreePatch when the
+// pointer does not resolve in one of the documents.
+var ErrSubtreeNotFound = errors.New("jsonpatch: subtree not found")
+
+// GenerateSubtreePatch diffs only the value at pointer in before and after,
+// leaving the rest of both documents unexamined. The pointer must resolve in
+// both documents, otherwise the error wraps ErrSubtreeNotFound. Operation
+// paths are absolute, rooted at pointer (e.g. "/user/preferences/theme"), so
+// the patch applies to the full document. The subtree may be an object, an
+// array, or a scalar; options behave as in GeneratePatch, with ignore
+// patterns matched against the absolute paths.
+func GenerateSubtreePatch(before, after any, pointer string, opts ...DiffOption) ([]Patch, error) {
+	if pointer == "" {
+		return GeneratePatch(before, after, "", opts...)
+	}
+	parts, err := parsePath(pointer)
+	if err != nil {
+		return nil, err
+	}
+	beforeVal, err := subtreeValue(before, parts, pointer, "before")
+	if err != nil {
+		return nil, err
+	}
+	afterVal, err := subtreeValue(after, parts, pointer, "after")
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := newDiffConfig(opts)
+	parent := ""
+	if len(parts) > 1 {
+		parent = pointerOf(parts[:len(parts)-1])
+	}
+	patches := cfg.diffMember(nil, parent, parts[len(parts)-1], beforeVal, true, afterVal)
+	if cfg.err != nil {
+		return nil, cfg.err
+	}
+	patches = cfg.detectCopies(beforeVal, afterVal, pointerOf(parts), patches)
+	cfg.annotatePatches(patches)
+	return patches, nil
+}
+
+// subtreeValue resolves parts in doc, naming side in the error when the
+// pointer is missing.
+func subtreeValue(doc any, parts []string, pointer, side string) (any, error) {
+	root, err := toMap(doc)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := getNested(root, parts)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q in %s document", ErrSubtreeNotFound, pointer, side)
+	}
+	return value, nil
+}
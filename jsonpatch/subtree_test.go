@@ -0,0 +1,123 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldDiffOnlySubtreeGivenPointer(t *testing.T) {
+	// Arrange
+	before := map[string]any{
+		"user": map[string]any{
+			"name":        "Ada",
+			"preferences": map[string]any{"theme": "light", "lang": "en"},
+		},
+		"audit": map[string]any{"updatedAt": "2024-01-01"},
+	}
+	after := map[string]any{
+		"user": map[string]any{
+			"name":        "Ada Lovelace",
+			"preferences": map[string]any{"theme": "dark", "tz": "UTC"},
+		},
+		"audit": map[string]any{"updatedAt": "2024-02-01"},
+	}
+
+	// Act
+	patches, err := GenerateSubtreePatch(before, after, "/user/preferences")
+
+	// Assert
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Patch{
+		{Op: "replace", Path: "/user/preferences/theme", Value: "dark"},
+		{Op: "add", Path: "/user/preferences/tz", Value: "UTC"},
+		{Op: "remove", Path: "/user/preferences/lang"},
+	}, patches)
+	result, err := ApplyPatch(before, patches)
+	require.NoError(t, err)
+	assert.Equal(t, after["user"].(map[string]any)["preferences"], result["user"].(map[string]any)["preferences"])
+	assert.Equal(t, "Ada", result["user"].(map[string]any)["name"])
+}
+
+func TestShouldDiffNonObjectSubtreeGivenPointer(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   map[string]any
+		after    map[string]any
+		pointer  string
+		expected []Patch
+	}{
+		{
+			name:     "array",
+			before:   map[string]any{"user": map[string]any{"tags": []any{"a", "b"}}},
+			after:    map[string]any{"user": map[string]any{"tags": []any{"a", "b", "c"}}},
+			pointer:  "/user/tags",
+			expected: []Patch{{Op: "add", Path: "/user/tags/2", Value: "c"}},
+		},
+		{
+			name:     "scalar",
+			before:   map[string]any{"user": map[string]any{"name": "Ada"}},
+			after:    map[string]any{"user": map[string]any{"name": "Grace"}},
+			pointer:  "/user/name",
+			expected: []Patch{{Op: "replace", Path: "/user/name", Value: "Grace"}},
+		},
+		{
+			name:     "unchanged",
+			before:   map[string]any{"user": map[string]any{"name": "Ada"}, "n": 1},
+			after:    map[string]any{"user": map[string]any{"name": "Ada"}, "n": 2},
+			pointer:  "/user",
+			expected: nil,
+		},
+		{
+			name:     "escaped key",
+			before:   map[string]any{"a/b": map[string]any{"x": 1}},
+			after:    map[string]any{"a/b": map[string]any{"x": 2}},
+			pointer:  "/a~1b",
+			expected: []Patch{{Op: "replace", Path: "/a~1b/x", Value: 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			patches, err := GenerateSubtreePatch(tt.before, tt.after, tt.pointer)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, patches)
+		})
+	}
+}
+
+func TestShouldReturnErrorGivenSubtreePointerMissing(t *testing.T) {
+	tests := []struct {
+		name   string
+		before map[string]any
+		after  map[string]any
+		side   string
+	}{
+		{
+			name:   "missing before",
+			before: map[string]any{"user": map[string]any{}},
+			after:  map[string]any{"user": map[string]any{"preferences": map[string]any{}}},
+			side:   "before",
+		},
+		{
+			name:   "missing after",
+			before: map[string]any{"user": map[string]any{"preferences": map[string]any{}}},
+			after:  map[string]any{"user": map[string]any{}},
+			side:   "after",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			patches, err := GenerateSubtreePatch(tt.before, tt.after, "/user/preferences")
+
+			// Assert
+			require.ErrorIs(t, err, ErrSubtreeNotFound)
+			assert.Contains(t, err.Error(), tt.side)
+			assert.Nil(t, patches)
+		})
+	}
+}
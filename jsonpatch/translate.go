@@ -0,0 +1,181 @@
+package jsonpatch
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Translator receives the operations of a patch as storage-level updates, so
+// a backend can build its own update statement (a MongoDB $set/$unset
+// document, SQL JSON_SET calls, and so on). Paths are unescaped JSON Pointer
+// segments; the empty slice is the document root. Values are decoded JSON,
+// with json.RawMessage payloads already unmarshaled.
+type Translator interface {
+	// Set writes value at path, creating or overwriting it.
+	Set(path []string, value any) error
+	// Unset deletes the object member at path.
+	Unset(path []string) error
+	// ArrayInsert inserts value into the array at path before index,
+	// shifting later elements; index -1 appends.
+	ArrayInsert(path []string, index int, value any) error
+	// ArrayRemove deletes the element at index from the array at path,
+	// shifting later elements.
+	ArrayRemove(path []string, index int) error
+	// Move relocates the value at from to path.
+	Move(from, path []string) error
+	// Copy duplicates the value at from to path.
+	Copy(from, path []string) error
+	// Test asserts that the value at path equals value.
+	Test(path []string, value any) error
+}
+
+// TranslatePatch hands each operation of patches to translator in order,
+// stopping at the first error.
+//
+// The document is not consulted, so array positions are recognized by the
+// last path segment alone: add at "-" or a decimal index becomes ArrayInsert
+// and remove at a decimal index becomes ArrayRemove. A numeric object key is
+// therefore treated as an array index. replace always becomes Set. Guard and
+// custom operations are rejected.
+func TranslatePatch(patches []Patch, translator Translator) error {
+	for _, op := range patches {
+		if err := translateOperation(op, translator); err != nil {
+			return fmt.Errorf("translate %s %s: %w", op.Op, op.Path, err)
+		}
+	}
+	return nil
+}
+
+func translateOperation(op Patch, translator Translator) error {
+	parts, err := parsePath(op.Path)
+	if err != nil {
+		return err
+	}
+	value, err := decodeRawValue(op.Value)
+	if err != nil {
+		return fmt.Errorf("invalid value: %w", err)
+	}
+	switch op.Op {
+	case "add":
+		if parent, index, ok := arrayPosition(parts, true); ok {
+			return translator.ArrayInsert(parent, index, value)
+		}
+		return translator.Set(parts, value)
+	case "remove":
+		if parent, index, ok := arrayPosition(parts, false); ok {
+			return translator.ArrayRemove(parent, index)
+		}
+		return translator.Unset(parts)
+	case "replace":
+		return translator.Set(parts, value)
+	case "move", "copy":
+		fromParts, err := parsePath(op.From)
+		if err != nil {
+			return fmt.Errorf("invalid from: %w", err)
+		}
+		if op.Op == "move" {
+			return translator.Move(fromParts, parts)
+		}
+		return translator.Copy(fromParts, parts)
+	case "test":
+		return translator.Test(parts, value)
+	default:
+		return fmt.Errorf("unsupported op: %s", op.Op)
+	}
+}
+
+// arrayPosition splits parts into the parent path and element index when the
+// last segment addresses an array element. allowEnd accepts "-", reported as
+// index -1.
+func arrayPosition(parts []string, allowEnd bool) ([]string, int, bool) {
+	if len(parts) == 0 {
+		return nil, 0, false
+	}
+	last := parts[len(parts)-1]
+	parent := parts[:len(parts)-1]
+	if last == "-" {
+		return parent, -1, allowEnd
+	}
+	if !isArrayIndex(last) {
+		return nil, 0, false
+	}
+	index, err := strconv.Atoi(last)
+	if err != nil {
+		return nil, 0, false
+	}
+	return parent, index, true
+}
+
+// isArrayIndex reports whether segment is an RFC 6901 array index: "0" or a
+// decimal number without a leading zero.
+func isArrayIndex(segment string) bool {
+	if segment == "" || (len(segment) > 1 && segment[0] == '0') {
+		return false
+	}
+	for _, r := range segment {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// TranslatorCall is one method call captured by a RecordingTranslator.
+// Method is the Translator method name; From, Index, and Value are set only
+// for methods that take them (Index is -1 for an append).
+type TranslatorCall struct {
+	Method string
+	Path   []string
+	From   []string
+	Index  int
+	Value  any
+}
+
+// RecordingTranslator is a Translator that performs no updates and records
+// every call, for tests and for inspecting how a patch translates.
+type RecordingTranslator struct {
+	Calls []TranslatorCall
+}
+
+func (r *RecordingTranslator) record(call TranslatorCall) error {
+	call.Path = slices.Clone(call.Path)
+	call.From = slices.Clone(call.From)
+	r.Calls = append(r.Calls, call)
+	return nil
+}
+
+// Set records a Set call.
+func (r *RecordingTranslator) Set(path []string, value any) error {
+	return r.record(TranslatorCall{Method: "Set", Path: path, Value: value})
+}
+
+// Unset records an Unset call.
+func (r *RecordingTranslator) Unset(path []string) error {
+	return r.record(TranslatorCall{Method: "Unset", Path: path})
+}
+
+// ArrayInsert records an ArrayInsert call.
+func (r *RecordingTranslator) ArrayInsert(path []string, index int, value any) error {
+	return r.record(TranslatorCall{Method: "ArrayInsert", Path: path, Index: index, Value: value})
+}
+
+// ArrayRemove records an ArrayRemove call.
+func (r *RecordingTranslator) ArrayRemove(path []string, index int) error {
+	return r.record(TranslatorCall{Method: "ArrayRemove", Path: path, Index: index})
+}
+
+// Move records a Move call.
+func (r *RecordingTranslator) Move(from, path []string) error {
+	return r.record(TranslatorCall{Method: "Move", Path: path, From: from})
+}
+
+// Copy records a Copy call.
+func (r *RecordingTranslator) Copy(from, path []string) error {
+	return r.record(TranslatorCall{Method: "Copy", Path: path, From: from})
+}
+
+// Test records a Test call.
+func (r *RecordingTranslator) Test(path []string, value any) error {
+	return r.record(TranslatorCall{Method: "Test", Path: path, Value: value})
+}
@@ -0,0 +1,104 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldRecordTranslatorCallsGivenMixedPatch(t *testing.T) {
+	// Arrange
+	patches := []Patch{
+		{Op: "test", Path: "/version", Value: 3},
+		{Op: "replace", Path: "/name", Value: "Bob"},
+		{Op: "add", Path: "/profile/a~1b", Value: json.RawMessage(`{"x":1}`)},
+		{Op: "remove", Path: "/nickname"},
+		{Op: "add", Path: "/tags/1", Value: "new"},
+		{Op: "add", Path: "/tags/-", Value: "last"},
+		{Op: "remove", Path: "/tags/0"},
+		{Op: "move", From: "/old", Path: "/new"},
+		{Op: "copy", From: "/name", Path: "/display"},
+	}
+	recorder := &RecordingTranslator{}
+
+	// Act
+	err := TranslatePatch(patches, recorder)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []TranslatorCall{
+		{Method: "Test", Path: []string{"version"}, Value: 3},
+		{Method: "Set", Path: []string{"name"}, Value: "Bob"},
+		{Method: "Set", Path: []string{"profile", "a/b"}, Value: map[string]any{"x": float64(1)}},
+		{Method: "Unset", Path: []string{"nickname"}},
+		{Method: "ArrayInsert", Path: []string{"tags"}, Index: 1, Value: "new"},
+		{Method: "ArrayInsert", Path: []string{"tags"}, Index: -1, Value: "last"},
+		{Method: "ArrayRemove", Path: []string{"tags"}, Index: 0},
+		{Method: "Move", Path: []string{"new"}, From: []string{"old"}},
+		{Method: "Copy", Path: []string{"display"}, From: []string{"name"}},
+	}, recorder.Calls)
+}
+
+func TestShouldTreatLeadingZeroSegmentAsKeyGivenTranslation(t *testing.T) {
+	// Arrange
+	recorder := &RecordingTranslator{}
+
+	// Act
+	err := TranslatePatch([]Patch{{Op: "remove", Path: "/codes/01"}}, recorder)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []TranslatorCall{{Method: "Unset", Path: []string{"codes", "01"}}}, recorder.Calls)
+}
+
+type failingTranslator struct {
+	RecordingTranslator
+}
+
+var errTranslate = errors.New("backend rejected update")
+
+func (f *failingTranslator) Unset([]string) error { return errTranslate }
+
+func TestShouldStopTranslationGivenTranslatorOrOpError(t *testing.T) {
+	tests := []struct {
+		name    string
+		patches []Patch
+		want    string
+		calls   int
+	}{
+		{
+			name:    "translator error",
+			patches: []Patch{{Op: "replace", Path: "/a", Value: 1}, {Op: "remove", Path: "/b"}, {Op: "replace", Path: "/c", Value: 2}},
+			want:    "translate remove /b: backend rejected update",
+			calls:   1,
+		},
+		{
+			name:    "unsupported op",
+			patches: []Patch{{Op: GuardExists, Path: "/a"}},
+			want:    "unsupported op: exists",
+		},
+		{
+			name:    "invalid from",
+			patches: []Patch{{Op: "move", From: "/a//b", Path: "/c"}},
+			want:    "invalid from",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			translator := &failingTranslator{}
+
+			// Act
+			err := TranslatePatch(tt.patches, translator)
+
+			// Assert
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+			assert.Len(t, translator.Calls, tt.calls)
+		})
+	}
+	assert.ErrorIs(t, TranslatePatch([]Patch{{Op: "remove", Path: "/b"}}, &failingTranslator{}), errTranslate)
+}
@@ -0,0 +1,65 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrPatchVerification is returned (wrapped) by GenerateVerifiedPatch when
+// the generated patch does not turn before into after.
+var ErrPatchVerification = errors.New("jsonpatch: patch does not reconstruct after")
+
+// GenerateVerifiedPatch generates the patch transforming before into after,
+// like GeneratePatch with an empty basePath, then applies it to a copy of
+// before and fails with an error wrapping ErrPatchVerification unless the
+// result equals after. It is a safety net for callers that store or forward
+// patches, catching array index-shift mistakes before they corrupt a
+// document. Both documents are compared in their JSON form. Options that
+// intentionally drop differences, such as WithIgnorePaths or
+// WithStrictStrings(false), make verification fail whenever a dropped
+// difference is present.
+func GenerateVerifiedPatch(before, after any, opts ...DiffOption) ([]Patch, error) {
+	patches, err := GeneratePatch(before, after, "", opts...)
+	if err != nil {
+		return nil, err
+	}
+	beforeDoc, err := jsonDocument(before)
+	if err != nil {
+		return nil, fmt.Errorf("before: %w", err)
+	}
+	afterDoc, err := jsonDocument(after)
+	if err != nil {
+		return nil, fmt.Errorf("after: %w", err)
+	}
+	result, err := ApplyPatch(beforeDoc, patches)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPatchVerification, err)
+	}
+	if !jsonEqual(result, afterDoc) {
+		residual, _ := GeneratePatch(result, afterDoc, "")
+		if len(residual) > 0 {
+			return nil, fmt.Errorf("%w: result differs at %s", ErrPatchVerification, residual[0].Path)
+		}
+		return nil, ErrPatchVerification
+	}
+	return patches, nil
+}
+
+// jsonDocument returns the JSON object form of v, as produced by
+// encoding/json, so Go-specific values (typed slices, marshalers) compare by
+// their wire representation.
+func jsonDocument(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = make(map[string]any)
+	}
+	return doc, nil
+}
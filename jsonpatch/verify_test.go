@@ -0,0 +1,119 @@
+package jsonpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldReturnVerifiedPatchGivenArrayReordersAndDeletions(t *testing.T) {
+	tests := []struct {
+		name   string
+		before map[string]any
+		after  map[string]any
+		opts   []DiffOption
+	}{
+		{
+			name:   "reverse",
+			before: map[string]any{"list": []any{1, 2, 3, 4, 5}},
+			after:  map[string]any{"list": []any{5, 4, 3, 2, 1}},
+		},
+		{
+			name:   "delete head and tail with insert",
+			before: map[string]any{"list": []any{"a", "b", "c", "d", "e"}},
+			after:  map[string]any{"list": []any{"b", "x", "d"}},
+		},
+		{
+			name:   "swap distant elements",
+			before: map[string]any{"list": []any{"a", "b", "c", "d"}},
+			after:  map[string]any{"list": []any{"d", "b", "c", "a"}},
+		},
+		{
+			name:   "duplicates removed",
+			before: map[string]any{"list": []any{1, 1, 2, 2, 1}},
+			after:  map[string]any{"list": []any{2, 1}},
+		},
+		{
+			name: "nested arrays of objects",
+			before: map[string]any{"rows": []any{
+				map[string]any{"id": 1, "cells": []any{1, 2}},
+				map[string]any{"id": 2, "cells": []any{3}},
+				map[string]any{"id": 3, "cells": []any{}},
+			}},
+			after: map[string]any{"rows": []any{
+				map[string]any{"id": 3, "cells": []any{9}},
+				map[string]any{"id": 1, "cells": []any{2, 1}},
+			}},
+		},
+		{
+			name: "keyed move with edit",
+			before: map[string]any{"rows": []any{
+				map[string]any{"id": 1, "v": "a"}, map[string]any{"id": 2, "v": "b"}, map[string]any{"id": 3, "v": "c"},
+			}},
+			after: map[string]any{"rows": []any{
+				map[string]any{"id": 3, "v": "C"}, map[string]any{"id": 1, "v": "a"},
+			}},
+			opts: []DiffOption{WithElementKeyField("id")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			patches, err := GenerateVerifiedPatch(tt.before, tt.after, tt.opts...)
+
+			// Assert
+			require.NoError(t, err)
+			result, err := ApplyPatch(tt.before, patches)
+			require.NoError(t, err)
+			assert.True(t, jsonEqual(tt.after, result), "result %v", result)
+		})
+	}
+}
+
+func TestShouldVerifyEveryPermutationGivenArrayReorder(t *testing.T) {
+	base := []any{"a", "b", "c", "d"}
+	for _, order := range permutations([]int{0, 1, 2, 3}) {
+		t.Run(fmt.Sprint(order), func(t *testing.T) {
+			// Arrange
+			reordered := make([]any, 0, len(order))
+			for _, i := range order[1:] {
+				reordered = append(reordered, base[i])
+			}
+
+			// Act
+			_, err := GenerateVerifiedPatch(map[string]any{"list": base}, map[string]any{"list": reordered})
+
+			// Assert
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestShouldFailVerificationGivenIgnoredDifference(t *testing.T) {
+	// Arrange
+	before := map[string]any{"name": "a", "meta": map[string]any{"rev": 1}}
+	after := map[string]any{"name": "b", "meta": map[string]any{"rev": 2}}
+
+	// Act
+	patches, err := GenerateVerifiedPatch(before, after, WithIgnorePaths("/meta"))
+
+	// Assert
+	require.ErrorIs(t, err, ErrPatchVerification)
+	assert.Contains(t, err.Error(), "differs at /meta/rev")
+	assert.Nil(t, patches)
+}
+
+func TestShouldVerifyPatchGivenStructDocuments(t *testing.T) {
+	// Arrange
+	before := keyedBoard{Name: "sprint", Tasks: []keyedTask{{ID: "a", Title: "Write"}, {ID: "b", Title: "Review"}}}
+	after := keyedBoard{Name: "sprint 2", Tasks: []keyedTask{{ID: "b", Title: "Review", Done: true}, {ID: "c", Title: "Ship"}}}
+
+	// Act
+	patches, err := GenerateVerifiedPatch(before, after)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEmpty(t, patches)
+}
@@ -3,37 +3,177 @@
 //
 // # Target draft
 //
-// Generated schemas are compatible with JSON Schema draft 2019-09. The package
+// Generated schemas are compatible with JSON Schema draft 2020-12. The package
 // does not set "$schema" on output; callers may add it (e.g.
-// https://json-schema.org/draft/2019-09/schema) when validators require it.
+// https://json-schema.org/draft/2020-12/schema) when validators require it,
+// or target an earlier draft with a Generator (see below).
 //
 // # Generation and validation
 //
-// Use GenerateSchema or Builder to produce a schema from a Go type. Use Validate
+// Use GenerateSchema or Builder to produce a schema from a Go type
+// (GenerateSchemaStrict additionally rejects chan, func, and complex fields,
+// which GenerateSchema maps to a placeholder string schema), or
+// InferSchema to derive a starter schema from an example JSON document.
+// GenerateSchemaYAML encodes the same schema as YAML with sorted keys. Use Validate
 // to check decoded JSON (map[string]any, []any, float64, string, bool, nil)
 // against a schema. Validation returns nil when valid, or *ErrValidation with
 // path and message for each failure. Supported validation keywords: type
 // (including nullable), required, properties, items, additionalProperties, enum,
 // const, minLength, maxLength, pattern, minimum, maximum, multipleOf,
 // exclusiveMinimum, exclusiveMaximum, minItems, maxItems, uniqueItems,
-// minProperties, maxProperties, patternProperties, contains,
-// $ref (same-document #/$defs/X and #/components/schemas/X, with unresolved
-// refs reported as validation errors), allOf, anyOf, oneOf, not, and
-// if/then/else.
+// minProperties, maxProperties, patternProperties, propertyNames, contains, minContains,
+// maxContains, prefixItems, $ref (same-document #/$defs/X, #/components/schemas/X, and #Anchor, with unresolved
+// refs reported as validation errors), allOf, anyOf, oneOf, not,
+// if/then/else, and unevaluatedProperties (members are evaluated by
+// properties, patternProperties, and additionalProperties of the schema, its
+// $ref and allOf subschemas, the anyOf/oneOf branches that match, and the
+// if/then/else branch taken). Go functions registered with RegisterValidator for a struct
+// field run alongside these keywords; generated schemas reference them through
+// the x-validator extension keyword.
+// ValidateExamples checks each examples entry against the schema holding it
+// and reports failures as *InvalidExampleError values. ValidateSchemaRefs
+// reports each same-document $ref that does not resolve (for example a $ref
+// tag naming a missing definition) as a *DanglingRefError.
 //
 // # Keywords
 //
 // Emitted keywords include: type, properties, required, items, additionalProperties,
 // $ref, format, minimum, maximum, minLength, maxLength, pattern, minItems, maxItems,
-// uniqueItems, enum, title, description, default, and struct-tag-driven keywords
-// such as const, examples, $defs, if/then/else, minProperties, maxProperties,
-// exclusiveMinimum, exclusiveMaximum, patternProperties, contains. References
-// use #/components/schemas/ when using SchemaWithComponents.
+// uniqueItems, enum, title, description, default (parsed as the field's schema
+// type, so default:"5" on an int field emits 5), $comment (from the comment tag;
+// annotation only, ignored by Validate), examples (always an array; a tag
+// that is not a JSON array is a single example typed like default), and
+// struct-tag-driven keywords
+// such as const, $defs, if/then/else, minProperties, maxProperties,
+// exclusiveMinimum, exclusiveMaximum, patternProperties, contains. Array fields
+// also accept minContains, maxContains, and the draft 2020-12 prefixItems tuple
+// keyword (a JSON array of schemas or a comma-separated list of type names,
+// e.g. `prefixItems:"number,number,string"`). Map fields accept a propertyNames
+// tag holding a regular expression for keys (e.g. `propertyNames:"^[a-z]+$"`);
+// an expression that does not compile is dropped, and reported as a
+// *PropertyNamesPatternError by GenerateSchemaStrict. References
+// use #/components/schemas/ when using SchemaWithComponents. Instantiated
+// generic types get component names without package paths or brackets:
+// Page[User] is stored as "Page_User". Without components, recursive struct types
+// are referenced through $anchor: the root type anchors itself, and other
+// recursive types are hoisted into $defs (e.g. {"$ref":"#TreeNode"}).
+//
+// Anonymous embedded fields tagged `json:",inline"` are flattened: a struct's
+// properties and required entries merge into the parent, and a map's value
+// schema becomes the parent's additionalProperties.
+//
+// # Enums
+//
+// The enum tag takes a comma-separated list of strings, or a JSON array such as
+// `enum:"[1,2,3]"` to keep element types and allow commas inside values.
+// EnumFor(t, values) registers the allowed values of a Go type (for example a
+// named int used as an enumeration) so every use of the type carries the enum.
+//
+// # Conditionals
+//
+// The if, then, and else tags normally apply to the field's own schema. Adding
+// ifEquals to a field lifts its then and else tags to the enclosing object,
+// keyed off that field: `ifEquals:"card" then:"{\"required\":[\"cardNumber\"]}"`
+// emits if {"properties":{"type":{"const":"card"}}} with the then branch on the
+// object. Branches accept a $ref, inline JSON, or a bare type name. Additional
+// conditionals in the same struct, including fields promoted from embedded
+// structs tagged json:",inline", are collected under allOf.
+//
+// # Request and response schemas
+//
+// The readOnly:"true" and writeOnly:"true" tags emit the matching annotations.
+// GenerateRequestSchema drops readOnly properties (such as server-assigned IDs)
+// and GenerateResponseSchema drops writeOnly properties (such as passwords),
+// at every nesting level (struct fields, slice items, map values, and
+// registered schemas alike) and from the required lists.
+//
+// # Polymorphic unions
+//
+// GenerateTSUnionSchema describes the inline format written by
+// polymorphic.MarshalPolymorphicJSONInline: a oneOf with one object schema per
+// registered struct type, titled with the discriminator and requiring a "type"
+// member whose const is the discriminator. TypeScript generators map it to a
+// discriminated union. GenerateRegistrySchema does the same for the envelope
+// format: a oneOf of {"$type", "content"} variants whose content references
+// each type's schema in $defs, with a discriminator mapping keyed by $type.
+//
+// Struct fields of a non-empty interface type are described the same way when
+// registered polymorphic types implement the interface: a oneOf over those
+// types plus a "discriminator" object ({"propertyName":"type","mapping":{...}})
+// mapping each discriminator to the Go type name. Interfaces without
+// registered implementations keep the placeholder string schema. An any
+// field (or a slice or map of any) tagged `oneOfTypes:"Person,Car"` is
+// instead a oneOf of the types registered under those names with
+// RegisterTypeName; GenerateSchemaStrict rejects names that are not
+// registered. Schemas of types with such interface fields are not
+// cached, so they reflect polymorphic types registered after earlier
+// generation.
+//
+// RegisterExternalRef maps a Go type to a reference such as "address.json", so
+// schemas using the type emit {"$ref":"address.json"} instead of describing it;
+// the type's own schema is still generated in full.
+//
+// # Builder options
+//
+// NewBuilder accepts BuilderOption values. WithFieldTitles sets each property's
+// title to its humanized Go field name ("FirstName" becomes "First Name") unless
+// a title tag is present. WithDefaultExamples copies each default tag into
+// examples, parsed per the field's Go type, when no examples tag is set.
+// WithValidateTags derives required, bounds, lengths, formats, and enums from
+// go-playground/validator validate tags (for example
+// `validate:"required,min=2,email"`) so they need not be repeated.
+// WithIncludeFields and WithExcludeFields project the
+// root object onto a subset of its properties (by JSON name), dropping removed
+// fields from required; GenerateSchema accepts the same options.
+// WithUnevaluatedProperties sets unevaluatedProperties on the root object, the
+// usual way to close a schema composed with allOf or if/then/else; nested
+// objects use the unevaluatedProperties tag, which takes the same values as
+// additionalProperties (true, false, a $ref, inline JSON, or a type name). Builders with
+// options bypass the shared schema cache.
+//
+// # Generator
+//
+// A Generator fixes schema options once for repeated use. NewGenerator accepts
+// GeneratorOption values: WithDraft sets "$schema" and converts the native
+// draft 2020-12 output (Draft201909 and Draft07 rewrite prefixItems to the
+// array form of items; Draft07 also rewrites $defs to "definitions" and
+// $anchor to a plain-name $id, and Generate fails with *DraftKeywordError for
+// keywords draft-07 cannot express), WithDefs collects named struct types in
+// the root's $defs and references them, and WithBuilderOptions passes
+// BuilderOption values through. GenerateSchema uses a Generator without
+// options.
+//
+// # Required and nullable
+//
+// The required (or binding:"required") tag adds a field to the object's required
+// list regardless of its Go type; required only means the key must be present.
+// Pointer fields additionally admit null by adding "null" to their type union.
+// Use nullable:"false" to keep a pointer field non-nullable, or nullable:"true"
+// to make a value field nullable. The optional:"true" tag marks a field as
+// both nullable and not required, overriding any required tag.
 //
 // # Registry
 //
-// RegisterSchema and the built-in type map (uuid.UUID, time.Time, url.URL, net.IP,
-// []byte, json.RawMessage, sql.Null*) are process-wide global state. Tests that
+// RegisterSchema and the built-in type map (uuid.UUID, time.Time, time.Duration,
+// url.URL, net.IP, []byte, json.RawMessage, sql.Null*) are process-wide global
+// state. Tests that
 // need a clean slate should call ClearRegistry to restore the default built-in
-// set and remove custom registrations.
+// set and remove custom registrations. Types implementing SchemaProvider supply
+// their own schema instead of being reflected; RegisterSchema takes precedence.
+// Other types implementing json.Marshaler get an open schema ({} with a
+// $comment) because their Go fields do not describe their JSON form.
+//
+// time.Duration is described as {"type":"integer","format":"int64"}, the
+// nanosecond count encoding/json writes. WithDurationAsString switches it to
+// {"type":"string","format":"duration"}, which is also used automatically for
+// json.Marshaler types named like "Duration" whose zero value marshals to a
+// string.
+//
+// Number and boolean fields tagged `json:",string"` are written by
+// encoding/json as quoted values, so their schema is a string with a pattern
+// for the encoding. Their type and value constraints move into contentSchema
+// with contentMediaType "application/json": `json:"n,string" minimum:"0"`
+// emits {"type":"string","pattern":"^-?[0-9]+$","contentMediaType":
+// "application/json","contentSchema":{"type":"integer","minimum":0}}, and
+// Validate decodes the string and checks it against contentSchema.
 package jsonschema
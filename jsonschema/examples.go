@@ -0,0 +1,123 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// InvalidExampleError reports an entry of an "examples" array that does not
+// satisfy the schema it annotates. Path is the JSON Pointer of that schema
+// within the document (for example "/properties/name"), Index the position of
+// the example, and Err the *ErrValidation describing the failure.
+type InvalidExampleError struct {
+	Path    string
+	Index   int
+	Example any
+	Err     error
+}
+
+func (e *InvalidExampleError) Error() string {
+	return fmt.Sprintf("jsonschema: example %d at %s is invalid: %v", e.Index, e.Path, e.Err)
+}
+
+func (e *InvalidExampleError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateExamples checks every "examples" array in schema against the
+// schema object that holds it, using Validate, so an example contradicting
+// its field's constraints (a string longer than maxLength, a number outside
+// its bounds) is caught before the schema is published. References resolve
+// against schema as the root. It returns nil when all examples are valid, or
+// the joined *InvalidExampleError values in document order.
+func ValidateExamples(schema map[string]any) error {
+	var errs []error
+	var path validationPath
+	walkSchemaObjects(&path, schema, func(node map[string]any) {
+		examples, _ := node[ExamplesKey].([]any)
+		for i, example := range examples {
+			if err := validateExample(schema, node, example); err != nil {
+				errs = append(errs, &InvalidExampleError{Path: examplePath(&path), Index: i, Example: example, Err: err})
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// walkSchemaObjects calls visit for every object in a schema document, in
+// document order with path pointing at the object. Keyword values that are
+// data rather than schemas (examples, default, const, enum) are not entered,
+// and the members of name maps (properties, patternProperties, $defs,
+// definitions) are walked as schemas even when a name matches a keyword.
+func walkSchemaObjects(path *validationPath, node any, visit func(map[string]any)) {
+	switch typed := node.(type) {
+	case map[string]any:
+		visit(typed)
+		for _, key := range sortedKeys(typed) {
+			switch key {
+			case ExamplesKey, DefaultKey, ConstKey, EnumKey:
+				// Values, not schemas.
+				continue
+			case PropertiesKey, PatternPropertiesKey, DefsKey, definitionsKey:
+				// Keyed by names that may collide with keywords.
+				names, _ := typed[key].(map[string]any)
+				path.push(escapeJSONPointer(key))
+				for _, name := range sortedKeys(names) {
+					path.push(escapeJSONPointer(name))
+					walkSchemaObjects(path, names[name], visit)
+					path.pop()
+				}
+				path.pop()
+				continue
+			}
+			path.push(escapeJSONPointer(key))
+			walkSchemaObjects(path, typed[key], visit)
+			path.pop()
+		}
+	case []any:
+		for i, item := range typed {
+			path.push(strconv.Itoa(i))
+			walkSchemaObjects(path, item, visit)
+			path.pop()
+		}
+	}
+}
+
+func examplePath(path *validationPath) string {
+	if location := path.String(); location != "" {
+		return location
+	}
+	return "/"
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// validateExample validates example against schema in its decoded JSON form,
+// so typed values parsed from tags (int64, uint64) check like wire data.
+func validateExample(root, schema map[string]any, example any) error {
+	data, err := json.Marshal(example)
+	if err != nil {
+		return err
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	var errs []ValidationError
+	var path validationPath
+	validateAt(root, &path, schema, decoded, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ErrValidation{Errs: errs}
+}
@@ -0,0 +1,100 @@
+package jsonschema
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type exampleAccount struct {
+	Name    string   `json:"name" maxLength:"5" examples:"Alexander"`
+	Age     int      `json:"age" minimum:"0" maximum:"150" examples:"42"`
+	Tags    []string `json:"tags" maxItems:"1" examples:"[[\"a\"],[\"b\",\"c\"]]"`
+	Default string   `json:"default" minLength:"2" examples:"x"`
+}
+
+type exampleValid struct {
+	Code  string `json:"code" pattern:"^[A-Z]{3}$" examples:"[\"USD\",\"EUR\"]"`
+	Count uint   `json:"count" examples:"7"`
+}
+
+func TestShouldEmitExamplesAsArrayGivenScalarTag(t *testing.T) {
+	// Act
+	props := GenerateSchema(reflect.TypeFor[exampleAccount]())[PropertiesKey].(map[string]any)
+
+	// Assert
+	assert.Equal(t, []any{"Alexander"}, props["name"].(map[string]any)[ExamplesKey])
+	assert.Equal(t, []any{int64(42)}, props["age"].(map[string]any)[ExamplesKey])
+	assert.Equal(t, []any{[]any{"a"}, []any{"b", "c"}}, props["tags"].(map[string]any)[ExamplesKey])
+}
+
+func TestShouldReportInvalidExamplesGivenConstraintViolations(t *testing.T) {
+	// Arrange
+	schema := GenerateSchema(reflect.TypeFor[exampleAccount]())
+
+	// Act
+	err := ValidateExamples(schema)
+
+	// Assert
+	require.Error(t, err)
+	var invalid []*InvalidExampleError
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var target *InvalidExampleError
+		require.True(t, errors.As(e, &target))
+		invalid = append(invalid, target)
+	}
+	require.Len(t, invalid, 3)
+	assert.Equal(t, "/properties/default", invalid[0].Path)
+	assert.Equal(t, "/properties/name", invalid[1].Path)
+	assert.Equal(t, 0, invalid[1].Index)
+	assert.Equal(t, "Alexander", invalid[1].Example)
+	assert.Contains(t, invalid[1].Error(), "maxLength 5")
+	assert.Equal(t, "/properties/tags", invalid[2].Path)
+	assert.Equal(t, 1, invalid[2].Index)
+	var verr *ErrValidation
+	assert.ErrorAs(t, err, &verr)
+}
+
+func TestShouldAcceptExamplesGivenValidValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema map[string]any
+	}{
+		{name: "generated", schema: GenerateSchema(reflect.TypeFor[exampleValid]())},
+		{name: "root examples", schema: map[string]any{TypeKey: "integer", ExamplesKey: []any{1, 2}}},
+		{name: "referenced", schema: map[string]any{
+			DefsKey:       map[string]any{"Code": map[string]any{TypeKey: "string", MaxLengthKey: 3}},
+			PropertiesKey: map[string]any{"code": map[string]any{RefKey: "#/$defs/Code", ExamplesKey: []any{"abc"}}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := ValidateExamples(tt.schema)
+
+			// Assert
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestShouldReportRootExampleGivenReferencedConstraint(t *testing.T) {
+	// Arrange
+	schema := map[string]any{
+		DefsKey:     map[string]any{"Short": map[string]any{TypeKey: "string", MaxLengthKey: 2}},
+		RefKey:      "#/$defs/Short",
+		ExamplesKey: []any{"ok", "too long"},
+	}
+
+	// Act
+	err := ValidateExamples(schema)
+
+	// Assert
+	var invalid *InvalidExampleError
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "/", invalid.Path)
+	assert.Equal(t, 1, invalid.Index)
+}
@@ -0,0 +1,52 @@
+package jsonschema
+
+import (
+	"reflect"
+	"sync"
+)
+
+var (
+	externalRefs   = make(map[reflect.Type]string)
+	externalRefsMu sync.RWMutex
+)
+
+// RegisterExternalRef makes schemas that use type t reference ref, typically
+// a file in a modular schema repository such as "address.json" or
+// "./common.json#/$defs/Address", instead of describing t inline or in
+// $defs. The ref is emitted as given, so it is resolved relative to the
+// referring schema's location by whichever tool loads it. Generating the
+// schema of t itself still describes t in full, which is how the referenced
+// file is produced. Validate only resolves same-document references and
+// reports external ones as unsupported.
+//
+// Like RegisterSchema, the registration is process-wide and is removed by
+// ClearRegistry. Registering an empty ref removes the mapping for t.
+func RegisterExternalRef(t reflect.Type, ref string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	externalRefsMu.Lock()
+	if ref == "" {
+		delete(externalRefs, t)
+	} else {
+		externalRefs[t] = ref
+	}
+	externalRefsMu.Unlock()
+	clearSchemaCache()
+}
+
+// externalRef returns the reference registered for t with
+// RegisterExternalRef, if any.
+func externalRef(t reflect.Type) (string, bool) {
+	externalRefsMu.RLock()
+	ref, ok := externalRefs[t]
+	externalRefsMu.RUnlock()
+	return ref, ok
+}
+
+func clearExternalRefs() {
+	externalRefsMu.Lock()
+	externalRefs = make(map[reflect.Type]string)
+	externalRefsMu.Unlock()
+}
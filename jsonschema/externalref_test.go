@@ -0,0 +1,87 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ExternalAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+}
+
+type ExternalCustomer struct {
+	Name      string            `json:"name"`
+	Home      ExternalAddress   `json:"home"`
+	Billing   *ExternalAddress  `json:"billing,omitempty"`
+	Previous  []ExternalAddress `json:"previous"`
+	Unrelated struct {
+		Note string `json:"note"`
+	} `json:"unrelated"`
+}
+
+func TestShouldEmitExternalRefGivenRegisteredType(t *testing.T) {
+	// Arrange
+	t.Cleanup(ClearRegistry)
+	_ = GenerateSchema(reflect.TypeOf(ExternalCustomer{}))
+	RegisterExternalRef(reflect.TypeOf(&ExternalAddress{}), "address.json")
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(ExternalCustomer{}))
+
+	// Assert
+	props := schema[PropertiesKey].(map[string]any)
+	assert.Equal(t, map[string]any{RefKey: "address.json"}, props["home"])
+	assert.Equal(t, map[string]any{RefKey: "address.json"}, props["previous"].(map[string]any)[ItemsKey])
+	billing := props["billing"].(map[string]any)
+	assert.Contains(t, billing[AnyOfKey], map[string]any{RefKey: "address.json"})
+	assert.NotContains(t, schema, DefsKey)
+}
+
+func TestShouldEmitExternalRefGivenComponentsAndDefs(t *testing.T) {
+	// Arrange
+	t.Cleanup(ClearRegistry)
+	RegisterExternalRef(reflect.TypeOf(ExternalAddress{}), "./common.json#/$defs/Address")
+
+	// Act
+	root, components := GenerateSchemaWithComponents(reflect.TypeOf(ExternalCustomer{}))
+	withDefs, err := NewGenerator(WithDefs()).Generate(reflect.TypeOf(ExternalCustomer{}))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "./common.json#/$defs/Address", root[PropertiesKey].(map[string]any)["home"].(map[string]any)[RefKey])
+	assert.NotContains(t, components, "ExternalAddress")
+	assert.Equal(t, "./common.json#/$defs/Address", withDefs[PropertiesKey].(map[string]any)["home"].(map[string]any)[RefKey])
+}
+
+func TestShouldDescribeRegisteredTypeInFullGivenItIsTheRoot(t *testing.T) {
+	// Arrange
+	t.Cleanup(ClearRegistry)
+	RegisterExternalRef(reflect.TypeOf(ExternalAddress{}), "address.json")
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(ExternalAddress{}))
+
+	// Assert
+	require.NotContains(t, schema, RefKey)
+	assert.Equal(t, TypeObject, schema[TypeKey])
+	assert.Contains(t, schema[PropertiesKey], "street")
+}
+
+func TestShouldInlineTypeAgainGivenExternalRefRemoved(t *testing.T) {
+	// Arrange
+	t.Cleanup(ClearRegistry)
+	RegisterExternalRef(reflect.TypeOf(ExternalAddress{}), "address.json")
+	RegisterExternalRef(reflect.TypeOf(ExternalAddress{}), "")
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(ExternalCustomer{}))
+
+	// Assert
+	home := schema[PropertiesKey].(map[string]any)["home"].(map[string]any)
+	assert.NotContains(t, home, RefKey)
+	assert.Equal(t, TypeObject, home[TypeKey])
+}
@@ -0,0 +1,260 @@
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Draft identifies the JSON Schema dialect a Generator targets by its
+// meta-schema URI.
+type Draft string
+
+const (
+	// Draft07 is JSON Schema draft-07. Generated "$defs" become
+	// "definitions", "$anchor" becomes a plain-name "$id" ("#Name"),
+	// "prefixItems" becomes the array form of "items" as in Draft201909, and
+	// "dependentRequired" becomes "dependencies". Keywords draft-07 has no
+	// equivalent for (unevaluatedProperties, contentSchema, minContains,
+	// maxContains) make Generate fail with a *DraftKeywordError.
+	Draft07 Draft = "http://json-schema.org/draft-07/schema#"
+	// Draft201909 is JSON Schema draft 2019-09. Generated "prefixItems"
+	// become the array form of "items", with the schema for the remaining
+	// items moved to "additionalItems".
+	Draft201909 Draft = "https://json-schema.org/draft/2019-09/schema"
+	// Draft202012 is JSON Schema draft 2020-12, the dialect the package
+	// generates natively.
+	Draft202012 Draft = "https://json-schema.org/draft/2020-12/schema"
+)
+
+// Keywords of earlier drafts that the generator does not emit natively.
+const (
+	definitionsKey       = "definitions"
+	additionalItemsKey   = "additionalItems"
+	dependenciesKey      = "dependencies"
+	dependentRequiredKey = "dependentRequired"
+	dependentSchemasKey  = "dependentSchemas"
+	unevaluatedItemsKey  = "unevaluatedItems"
+)
+
+// DraftKeywordError reports a keyword that the draft a Generator targets has
+// no equivalent for. Path is the JSON Pointer of the schema object holding
+// the keyword, in the converted document.
+type DraftKeywordError struct {
+	Draft   Draft
+	Path    string
+	Keyword string
+}
+
+func (e *DraftKeywordError) Error() string {
+	return fmt.Sprintf("jsonschema: %s at %s has no equivalent in %s", e.Keyword, e.Path, e.Draft)
+}
+
+// GeneratorOption configures a Generator.
+type GeneratorOption func(*Generator)
+
+// Generator produces schemas with a fixed configuration, for callers that
+// generate many schemas the same way. Unlike Builder it holds no state
+// between calls and is safe for concurrent use.
+type Generator struct {
+	draft   Draft
+	defs    bool
+	builder []BuilderOption
+}
+
+// NewGenerator returns a Generator configured by opts. Without options it
+// behaves like GenerateSchema.
+func NewGenerator(opts ...GeneratorOption) *Generator {
+	g := &Generator{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(g)
+		}
+	}
+	return g
+}
+
+// WithDraft sets "$schema" to the draft's URI and rewrites dialect-specific
+// keywords for it (see Draft07). By default no "$schema" is emitted.
+func WithDraft(draft Draft) GeneratorOption {
+	return func(g *Generator) {
+		g.draft = draft
+	}
+}
+
+// WithDefs places every named struct type in the root's "$defs" (or
+// "definitions" for Draft07) and references it from each use, instead of
+// inlining it, so types used more than once are described once.
+func WithDefs() GeneratorOption {
+	return func(g *Generator) {
+		g.defs = true
+	}
+}
+
+// WithBuilderOptions passes opts to the Builder used for each schema, for
+// example WithFieldTitles.
+func WithBuilderOptions(opts ...BuilderOption) GeneratorOption {
+	return func(g *Generator) {
+		g.builder = append(g.builder, opts...)
+	}
+}
+
+// Generate returns the schema for t, converted to the configured draft. Like
+// GenerateSchemaStrict it fails when t has fields that cannot be represented
+// in JSON or carry invalid tags; it also fails when the schema uses keywords
+// the draft cannot express, joining one *DraftKeywordError per keyword in
+// document order.
+func (g *Generator) Generate(t reflect.Type) (map[string]any, error) {
+	if err := checkSchema(t); err != nil {
+		return nil, err
+	}
+	schema := g.generate(t)
+	switch g.draft {
+	case Draft07:
+		if err := convertToDraft07(schema); err != nil {
+			return nil, err
+		}
+	case Draft201909:
+		convertToDraft201909(schema)
+	case Draft202012:
+		// The native dialect; nothing to convert.
+	}
+	if g.draft != "" {
+		schema[SchemaKey] = string(g.draft)
+	}
+	return schema, nil
+}
+
+// generate returns the schema for t in the native dialect.
+func (g *Generator) generate(t reflect.Type) map[string]any {
+	builder := NewBuilder(g.builder...)
+	var schema map[string]any
+	if g.defs {
+		root, components := builder.SchemaWithComponents(t)
+		schema = cloneSchemaMap(root)
+		if len(components) > 0 {
+			defs, _ := schema[DefsKey].(map[string]any)
+			if defs == nil {
+				defs = make(map[string]any, len(components))
+			}
+			for name, component := range components {
+				defs[name] = cloneSchemaValue(component)
+			}
+			schema[DefsKey] = defs
+		}
+		rewriteRefPrefix(schema, "#/components/schemas/", "#/$defs/")
+	} else {
+		schema = builder.Schema(t)
+	}
+	return schema
+}
+
+// cloneSchemaValue deep-copies a schema node of any JSON-like type.
+func cloneSchemaValue(value any) any {
+	wrapped := cloneSchemaMap(map[string]any{"v": value})
+	return wrapped["v"]
+}
+
+// rewriteRefPrefix replaces the prefix from with to in every $ref of schema.
+func rewriteRefPrefix(schema any, from, to string) {
+	switch node := schema.(type) {
+	case map[string]any:
+		if ref, ok := node[RefKey].(string); ok && strings.HasPrefix(ref, from) {
+			node[RefKey] = to + strings.TrimPrefix(ref, from)
+		}
+		for _, value := range node {
+			rewriteRefPrefix(value, from, to)
+		}
+	case []any:
+		for _, item := range node {
+			rewriteRefPrefix(item, from, to)
+		}
+	}
+}
+
+// convertToDraft201909 rewrites the draft 2020-12 keywords the generator
+// emits into their draft 2019-09 equivalents.
+func convertToDraft201909(schema map[string]any) {
+	var path validationPath
+	walkSchemaObjects(&path, schema, convertPrefixItems)
+}
+
+// convertPrefixItems turns "prefixItems" into the array form of "items",
+// moving the schema for the remaining items to "additionalItems".
+func convertPrefixItems(node map[string]any) {
+	prefixItems, ok := node[PrefixItemsKey]
+	if !ok {
+		return
+	}
+	delete(node, PrefixItemsKey)
+	if items, ok := node[ItemsKey]; ok {
+		node[additionalItemsKey] = items
+	}
+	node[ItemsKey] = prefixItems
+}
+
+// convertToDraft07 rewrites the draft 2020-12 keywords the generator emits
+// into their draft-07 equivalents, reporting those it has none for.
+func convertToDraft07(schema map[string]any) error {
+	rewriteRefPrefix(schema, "#/$defs/", "#/"+definitionsKey+"/")
+	var errs []error
+	var path validationPath
+	walkSchemaObjects(&path, schema, func(node map[string]any) {
+		convertPrefixItems(node)
+		if defs, ok := node[DefsKey]; ok {
+			delete(node, DefsKey)
+			node[definitionsKey] = defs
+		}
+		if anchor, ok := node[AnchorKey].(string); ok {
+			if _, hasID := node[IDKey]; !hasID {
+				delete(node, AnchorKey)
+				node[IDKey] = "#" + anchor
+			}
+		}
+		for _, keyword := range []string{dependentRequiredKey, dependentSchemasKey} {
+			if !mergeDependencies(node, keyword) {
+				errs = append(errs, &DraftKeywordError{Draft: Draft07, Path: examplePath(&path), Keyword: keyword})
+			}
+		}
+		for _, keyword := range []string{UnevaluatedPropertiesKey, unevaluatedItemsKey, ContentSchemaKey, MinContainsKey, MaxContainsKey} {
+			if _, ok := node[keyword]; ok {
+				errs = append(errs, &DraftKeywordError{Draft: Draft07, Path: examplePath(&path), Keyword: keyword})
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// mergeDependencies moves the members of keyword (dependentRequired or
+// dependentSchemas) into the draft-07 "dependencies" keyword, which holds
+// both forms. It reports false when the keyword is not an object or a
+// member is already present.
+func mergeDependencies(node map[string]any, keyword string) bool {
+	value, ok := node[keyword]
+	if !ok {
+		return true
+	}
+	members, ok := value.(map[string]any)
+	if !ok {
+		return false
+	}
+	dependencies, _ := node[dependenciesKey].(map[string]any)
+	if dependencies == nil {
+		if _, exists := node[dependenciesKey]; exists {
+			return false
+		}
+		dependencies = make(map[string]any, len(members))
+	}
+	for name := range members {
+		if _, exists := dependencies[name]; exists {
+			return false
+		}
+	}
+	for name, member := range members {
+		dependencies[name] = member
+	}
+	node[dependenciesKey] = dependencies
+	delete(node, keyword)
+	return true
+}
@@ -0,0 +1,190 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type GeneratorAddress struct {
+	Street string `json:"street" required:"true"`
+	City   string `json:"city"`
+}
+
+type GeneratorOrder struct {
+	ID       string           `json:"id" required:"true"`
+	Billing  GeneratorAddress `json:"billing"`
+	Shipping GeneratorAddress `json:"shipping"`
+}
+
+type GeneratorNode struct {
+	Name     string           `json:"name"`
+	Children []*GeneratorNode `json:"children,omitempty"`
+}
+
+func TestShouldGenerateDraft07DefinitionsGivenConfiguredGenerator(t *testing.T) {
+	// Arrange
+	generator := NewGenerator(WithDraft(Draft07), WithDefs())
+
+	// Act
+	schema, err := generator.Generate(reflect.TypeOf(GeneratorOrder{}))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, string(Draft07), schema[SchemaKey])
+	assert.NotContains(t, schema, DefsKey)
+	definitions, ok := schema[definitionsKey].(map[string]any)
+	require.True(t, ok, "expected definitions, got %v", schema)
+	assert.Contains(t, definitions, "GeneratorAddress")
+
+	properties := schema[PropertiesKey].(map[string]any)
+	assert.Equal(t, "#/definitions/GeneratorAddress", properties["billing"].(map[string]any)[RefKey])
+	assert.Equal(t, "#/definitions/GeneratorAddress", properties["shipping"].(map[string]any)[RefKey])
+
+	require.NoError(t, Validate(schema, map[string]any{
+		"id":       "o-1",
+		"billing":  map[string]any{"street": "1 Main St"},
+		"shipping": map[string]any{"street": "2 Side St", "city": "Springfield"},
+	}))
+	assert.Error(t, Validate(schema, map[string]any{
+		"id":      "o-1",
+		"billing": map[string]any{"city": "Springfield"},
+	}))
+}
+
+func TestShouldReuseConfigurationGivenGeneratorCalledRepeatedly(t *testing.T) {
+	// Arrange
+	generator := NewGenerator(WithDefs(), WithBuilderOptions(WithFieldTitles()))
+
+	// Act
+	first, firstErr := generator.Generate(reflect.TypeOf(GeneratorOrder{}))
+	first[TitleKey] = "mutated"
+	second, secondErr := generator.Generate(reflect.TypeOf(GeneratorOrder{}))
+
+	// Assert
+	require.NoError(t, firstErr)
+	require.NoError(t, secondErr)
+	assert.NotContains(t, second, SchemaKey)
+	assert.NotContains(t, second, TitleKey)
+	defs, ok := second[DefsKey].(map[string]any)
+	require.True(t, ok, "expected $defs, got %v", second)
+	address := defs["GeneratorAddress"].(map[string]any)
+	street := address[PropertiesKey].(map[string]any)["street"].(map[string]any)
+	assert.Equal(t, "Street", street[TitleKey])
+	assert.Equal(t, "#/$defs/GeneratorAddress", second[PropertiesKey].(map[string]any)["billing"].(map[string]any)[RefKey])
+}
+
+func TestShouldMatchGenerateSchemaGivenDefaultGenerator(t *testing.T) {
+	// Arrange
+	typ := reflect.TypeOf(GeneratorOrder{})
+
+	// Act
+	generated, err := NewGenerator().Generate(typ)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, GenerateSchema(typ), generated)
+}
+
+func TestShouldConvertAnchorsToIDsGivenDraft07RecursiveType(t *testing.T) {
+	// Arrange
+	generator := NewGenerator(WithDraft(Draft07))
+
+	// Act
+	schema, err := generator.Generate(reflect.TypeOf(GeneratorNode{}))
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotContains(t, schema, AnchorKey)
+	assert.Equal(t, "#GeneratorNode", schema[IDKey])
+	require.NoError(t, Validate(schema, map[string]any{
+		"name":     "root",
+		"children": []any{map[string]any{"name": "leaf"}},
+	}))
+	assert.Error(t, Validate(schema, map[string]any{
+		"name":     "root",
+		"children": []any{map[string]any{"name": 1}},
+	}))
+}
+
+type GeneratorTuple struct {
+	Point []int `json:"point" prefixItems:"string,string"`
+}
+
+func TestShouldConvertPrefixItemsGivenEarlierDraft(t *testing.T) {
+	tests := []struct {
+		name   string
+		draft  Draft
+		native bool
+	}{
+		{name: "draft-07", draft: Draft07},
+		{name: "draft 2019-09", draft: Draft201909},
+		{name: "draft 2020-12", draft: Draft202012, native: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			generator := NewGenerator(WithDraft(tt.draft))
+
+			// Act
+			schema, err := generator.Generate(reflect.TypeOf(GeneratorTuple{}))
+
+			// Assert
+			require.NoError(t, err)
+			point := schema[PropertiesKey].(map[string]any)["point"].(map[string]any)
+			tuple := []any{map[string]any{TypeKey: TypeString}, map[string]any{TypeKey: TypeString}}
+			if tt.native {
+				assert.Equal(t, tuple, point[PrefixItemsKey])
+				assert.Equal(t, map[string]any{TypeKey: TypeInteger}, point[ItemsKey])
+			} else {
+				assert.NotContains(t, point, PrefixItemsKey)
+				assert.Equal(t, tuple, point[ItemsKey])
+				assert.Equal(t, map[string]any{TypeKey: TypeInteger}, point[additionalItemsKey])
+			}
+			assert.NoError(t, Validate(schema, map[string]any{"point": []any{"a", "b", 3}}))
+			assert.Error(t, Validate(schema, map[string]any{"point": []any{1}}))
+			assert.Error(t, Validate(schema, map[string]any{"point": []any{"a", "b", "c"}}))
+		})
+	}
+}
+
+func TestShouldReportKeywordsWithoutEquivalentGivenDraft07(t *testing.T) {
+	// Arrange
+	type Scores struct {
+		Values []int `json:"values" contains:"{\"type\":\"integer\"}" minContains:"1"`
+		Total  int64 `json:"total,string"`
+	}
+	generator := NewGenerator(WithDraft(Draft07), WithBuilderOptions(WithUnevaluatedProperties(false)))
+
+	// Act
+	schema, err := generator.Generate(reflect.TypeOf(Scores{}))
+
+	// Assert
+	assert.Nil(t, schema)
+	var keywordErr *DraftKeywordError
+	require.ErrorAs(t, err, &keywordErr)
+	assert.Equal(t, Draft07, keywordErr.Draft)
+	assert.ErrorContains(t, err, "unevaluatedProperties at /")
+	assert.ErrorContains(t, err, "contentSchema at /properties/total")
+	assert.ErrorContains(t, err, "minContains at /properties/values")
+}
+
+func TestShouldMoveDependentRequiredToDependenciesGivenDraft07(t *testing.T) {
+	// Arrange
+	schema := map[string]any{
+		TypeKey:              TypeObject,
+		dependentRequiredKey: map[string]any{"card": []any{"billing"}},
+		DefaultKey:           map[string]any{dependentRequiredKey: "data, not a schema"},
+	}
+
+	// Act
+	err := convertToDraft07(schema)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"card": []any{"billing"}}, schema[dependenciesKey])
+	assert.NotContains(t, schema, dependentRequiredKey)
+	assert.Equal(t, map[string]any{dependentRequiredKey: "data, not a schema"}, schema[DefaultKey])
+}
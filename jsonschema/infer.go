@@ -0,0 +1,177 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// InferSchema produces a starter JSON Schema from an example JSON document.
+// Types are inferred from the values present: every key of an object is
+// listed as required, whole numbers are inferred as integers, and array
+// element schemas are merged into a single items schema. Objects found in
+// the same array are merged (properties are unioned and only keys present
+// in every element stay required); otherwise distinct element types are
+// combined with anyOf.
+func InferSchema(data []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("infer schema: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("infer schema: unexpected data after JSON document")
+	}
+	return inferValueSchema(doc), nil
+}
+
+func inferValueSchema(value any) map[string]any {
+	switch v := value.(type) {
+	case nil:
+		return map[string]any{TypeKey: "null"}
+	case bool:
+		return map[string]any{TypeKey: TypeBoolean}
+	case json.Number:
+		if _, err := v.Int64(); err == nil && !strings.ContainsAny(v.String(), ".eE") {
+			return map[string]any{TypeKey: TypeInteger}
+		}
+		return map[string]any{TypeKey: TypeNumber}
+	case string:
+		return map[string]any{TypeKey: TypeString}
+	case map[string]any:
+		properties := make(map[string]any, len(v))
+		required := make([]string, 0, len(v))
+		for key, item := range v {
+			properties[key] = inferValueSchema(item)
+			required = append(required, key)
+		}
+		sort.Strings(required)
+		schema := map[string]any{TypeKey: TypeObject, PropertiesKey: properties}
+		if len(required) > 0 {
+			schema[RequiredKey] = required
+		}
+		return schema
+	case []any:
+		schema := map[string]any{TypeKey: TypeArray}
+		if items := inferItemsSchema(v); items != nil {
+			schema[ItemsKey] = items
+		}
+		return schema
+	default:
+		return map[string]any{}
+	}
+}
+
+func inferItemsSchema(items []any) map[string]any {
+	var variants []map[string]any
+	for _, item := range items {
+		variants = mergeInferredVariant(variants, inferValueSchema(item))
+	}
+
+	switch len(variants) {
+	case 0:
+		return nil
+	case 1:
+		return variants[0]
+	}
+
+	anyOf := make([]any, len(variants))
+	for i, variant := range variants {
+		anyOf[i] = variant
+	}
+	return map[string]any{AnyOfKey: anyOf}
+}
+
+// mergeInferredVariant folds schema into the list of distinct variants,
+// merging objects with objects and widening integers to numbers.
+func mergeInferredVariant(variants []map[string]any, schema map[string]any) []map[string]any {
+	for i, existing := range variants {
+		if merged, ok := mergeInferredSchemas(existing, schema); ok {
+			variants[i] = merged
+			return variants
+		}
+	}
+	return append(variants, schema)
+}
+
+func mergeInferredSchemas(a, b map[string]any) (map[string]any, bool) {
+	aType, _ := a[TypeKey].(string)
+	bType, _ := b[TypeKey].(string)
+
+	switch {
+	case aType == TypeObject && bType == TypeObject:
+		return mergeInferredObjects(a, b), true
+	case aType == TypeArray && bType == TypeArray:
+		aItems, _ := a[ItemsKey].(map[string]any)
+		bItems, _ := b[ItemsKey].(map[string]any)
+		switch {
+		case aItems == nil:
+			return b, true
+		case bItems == nil:
+			return a, true
+		}
+		if merged, ok := mergeInferredSchemas(aItems, bItems); ok {
+			return map[string]any{TypeKey: TypeArray, ItemsKey: merged}, true
+		}
+		return nil, false
+	case isInferredNumeric(aType) && isInferredNumeric(bType):
+		if aType == bType {
+			return a, true
+		}
+		return map[string]any{TypeKey: TypeNumber}, true
+	case aType != "" && aType == bType:
+		return a, true
+	}
+	return nil, false
+}
+
+func isInferredNumeric(t string) bool {
+	return t == TypeInteger || t == TypeNumber
+}
+
+func mergeInferredObjects(a, b map[string]any) map[string]any {
+	aProps, _ := a[PropertiesKey].(map[string]any)
+	bProps, _ := b[PropertiesKey].(map[string]any)
+
+	properties := make(map[string]any, len(aProps)+len(bProps))
+	for key, schema := range aProps {
+		properties[key] = schema
+	}
+	for key, schema := range bProps {
+		existing, ok := properties[key].(map[string]any)
+		if !ok {
+			properties[key] = schema
+			continue
+		}
+		if merged, ok := mergeInferredSchemas(existing, schema.(map[string]any)); ok {
+			properties[key] = merged
+			continue
+		}
+		properties[key] = map[string]any{AnyOfKey: []any{existing, schema}}
+	}
+
+	bRequired := make(map[string]bool)
+	if req, ok := b[RequiredKey].([]string); ok {
+		for _, key := range req {
+			bRequired[key] = true
+		}
+	}
+	var required []string
+	if req, ok := a[RequiredKey].([]string); ok {
+		for _, key := range req {
+			if bRequired[key] {
+				required = append(required, key)
+			}
+		}
+	}
+
+	merged := map[string]any{TypeKey: TypeObject, PropertiesKey: properties}
+	if len(required) > 0 {
+		merged[RequiredKey] = required
+	}
+	return merged
+}
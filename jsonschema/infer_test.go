@@ -0,0 +1,98 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldInferSchemaGivenNestedObjectWithArray(t *testing.T) {
+	// Arrange
+	doc := []byte(`{
+		"name": "Alice",
+		"age": 30,
+		"score": 9.5,
+		"active": true,
+		"nickname": null,
+		"address": {"city": "NYC", "zip": "10001"},
+		"orders": [
+			{"id": 1, "total": 10},
+			{"id": 2, "total": 12.5, "coupon": "SAVE"}
+		]
+	}`)
+
+	// Act
+	schema, err := InferSchema(doc)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":     map[string]any{"type": "string"},
+			"age":      map[string]any{"type": "integer"},
+			"score":    map[string]any{"type": "number"},
+			"active":   map[string]any{"type": "boolean"},
+			"nickname": map[string]any{"type": "null"},
+			"address": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"city": map[string]any{"type": "string"},
+					"zip":  map[string]any{"type": "string"},
+				},
+				"required": []string{"city", "zip"},
+			},
+			"orders": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"id":     map[string]any{"type": "integer"},
+						"total":  map[string]any{"type": "number"},
+						"coupon": map[string]any{"type": "string"},
+					},
+					"required": []string{"id", "total"},
+				},
+			},
+		},
+		"required": []string{"active", "address", "age", "name", "nickname", "orders", "score"},
+	}, schema)
+
+	var decoded any
+	require.NoError(t, json.Unmarshal(doc, &decoded))
+	assert.NoError(t, Validate(schema, decoded))
+}
+
+func TestShouldInferAnyOfGivenHeterogeneousArray(t *testing.T) {
+	// Act
+	schema, err := InferSchema([]byte(`{"values":[1,"two",3,{"x":true}],"empty":[]}`))
+
+	// Assert
+	require.NoError(t, err)
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"type": "array",
+		"items": map[string]any{
+			"anyOf": []any{
+				map[string]any{"type": "integer"},
+				map[string]any{"type": "string"},
+				map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"x": map[string]any{"type": "boolean"}},
+					"required":   []string{"x"},
+				},
+			},
+		},
+	}, props["values"])
+	assert.Equal(t, map[string]any{"type": "array"}, props["empty"])
+}
+
+func TestShouldReturnErrorWhenInferringSchemaFromInvalidJSON(t *testing.T) {
+	// Act
+	_, err := InferSchema([]byte(`{"name":`))
+
+	// Assert
+	require.Error(t, err)
+}
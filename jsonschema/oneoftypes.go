@@ -0,0 +1,147 @@
+package jsonschema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	namedTypes   = make(map[string]reflect.Type)
+	namedTypesMu sync.RWMutex
+)
+
+// RegisterTypeName registers t under name for the oneOfTypes tag, which
+// describes a field of type any as one of several concrete types:
+//
+//	RegisterTypeName("Person", reflect.TypeOf(Person{}))
+//	RegisterTypeName("Car", reflect.TypeOf(Car{}))
+//
+//	type Garage struct {
+//		Occupant any `json:"occupant" oneOfTypes:"Person,Car"`
+//	}
+//
+// Like RegisterSchema, the registration is process-wide and is removed by
+// ClearRegistry. Registering a nil type removes the name.
+func RegisterTypeName(name string, t reflect.Type) {
+	namedTypesMu.Lock()
+	if t == nil {
+		delete(namedTypes, name)
+	} else {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		namedTypes[name] = t
+	}
+	namedTypesMu.Unlock()
+	clearSchemaCache()
+}
+
+func lookupTypeName(name string) (reflect.Type, bool) {
+	namedTypesMu.RLock()
+	t, ok := namedTypes[name]
+	namedTypesMu.RUnlock()
+	return t, ok
+}
+
+func clearNamedTypes() {
+	namedTypesMu.Lock()
+	namedTypes = make(map[string]reflect.Type)
+	namedTypesMu.Unlock()
+}
+
+// oneOfTypesSchema describes a field tagged oneOfTypes whose type is an
+// interface, or a slice, array, or map of one, as a oneOf of the schemas of
+// the named types in tag order. It reports false for fields without the tag
+// or of another type. Names not registered with RegisterTypeName are skipped
+// and listed in a "$comment"; when none is registered the union matches no
+// value. GenerateSchemaStrict reports such names as errors.
+func (b *Builder) oneOfTypesSchema(field reflect.StructField, useRef bool) (map[string]any, bool) {
+	tag := field.Tag.Get(OneOfTypesTag)
+	if tag == "" {
+		return nil, false
+	}
+	wrap := oneOfTypesWrap(field.Type)
+	if wrap == nil {
+		return nil, false
+	}
+
+	var variants []any
+	var unknown []string
+	for _, name := range oneOfTypesNames(tag) {
+		variant, ok := lookupTypeName(name)
+		if !ok {
+			unknown = append(unknown, strconv.Quote(name))
+			continue
+		}
+		variants = append(variants, b.variantSchema(variant, useRef))
+	}
+	union := map[string]any{}
+	if len(variants) > 0 {
+		union[OneOfKey] = variants
+	} else {
+		union[NotKey] = map[string]any{}
+	}
+	if len(unknown) > 0 {
+		union[CommentKey] = fmt.Sprintf("oneOfTypes: %s not registered with RegisterTypeName", strings.Join(unknown, ", "))
+	}
+	return wrap(union), true
+}
+
+// oneOfTypesWrap returns the function placing a oneOfTypes union in the
+// schema of a field of type t: the union itself for an interface, and its
+// items or additionalProperties for a slice, array, or map of one. It
+// returns nil for types the tag does not apply to.
+func oneOfTypesWrap(t reflect.Type) func(map[string]any) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		return func(union map[string]any) map[string]any { return union }
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() != reflect.Interface {
+			return nil
+		}
+		return func(union map[string]any) map[string]any {
+			return map[string]any{TypeKey: TypeArray, ItemsKey: union}
+		}
+	case reflect.Map:
+		if t.Elem().Kind() != reflect.Interface {
+			return nil
+		}
+		return func(union map[string]any) map[string]any {
+			return map[string]any{TypeKey: TypeObject, AdditionalPropertiesKey: union}
+		}
+	case reflect.Invalid, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr, reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.Chan, reflect.Func, reflect.Pointer, reflect.String, reflect.Struct, reflect.UnsafePointer:
+	}
+	return nil
+}
+
+// oneOfTypesNames splits a oneOfTypes tag into its trimmed type names.
+func oneOfTypesNames(tag string) []string {
+	names := strings.Split(tag, ",")
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+	}
+	return names
+}
+
+// variantSchema returns the schema of a oneOfTypes alternative: a reference
+// to its component when generating components, as for struct fields, and
+// the inline schema otherwise.
+func (b *Builder) variantSchema(t reflect.Type, useRef bool) map[string]any {
+	if !useRef || t.Name() == "" || !isEligibleForRef(t) {
+		return b.schemaInternal(t, useRef)
+	}
+	refName := componentName(t)
+	if _, exists := b.components[refName]; !exists {
+		b.components[refName] = b.schemaInternal(t, useRef)
+	}
+	return map[string]any{RefKey: "#/components/schemas/" + refName}
+}
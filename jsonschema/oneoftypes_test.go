@@ -0,0 +1,125 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type unionPerson struct {
+	Name string `json:"name" required:"true"`
+}
+
+type unionCar struct {
+	Make string `json:"make" required:"true"`
+}
+
+type unionGarage struct {
+	Occupant any            `json:"occupant" oneOfTypes:"Person, Car" description:"Who or what is parked"`
+	History  []any          `json:"history" oneOfTypes:"Person,Car"`
+	ByBay    map[string]any `json:"byBay" oneOfTypes:"Car"`
+	Notes    any            `json:"notes"`
+}
+
+func registerUnionNames(t *testing.T) {
+	t.Helper()
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterTypeName("Person", reflect.TypeOf(unionPerson{}))
+	RegisterTypeName("Car", reflect.TypeOf(&unionCar{}))
+}
+
+func TestShouldEmitOneOfGivenAnyFieldWithRegisteredAlternatives(t *testing.T) {
+	// Arrange
+	registerUnionNames(t)
+	person := GenerateSchema(reflect.TypeOf(unionPerson{}))
+	car := GenerateSchema(reflect.TypeOf(unionCar{}))
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(unionGarage{}))
+
+	// Assert
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"oneOf":       []any{person, car},
+		"description": "Who or what is parked",
+	}, props["occupant"])
+	assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"oneOf": []any{person, car}}}, props["history"])
+	assert.Equal(t, map[string]any{"type": "object", "additionalProperties": map[string]any{"oneOf": []any{car}}}, props["byBay"])
+	assert.NotContains(t, props["notes"], "oneOf")
+	assert.NoError(t, Validate(schema, map[string]any{"occupant": map[string]any{"make": "Volvo"}}))
+	assert.Error(t, Validate(schema, map[string]any{"occupant": "nobody"}))
+}
+
+func TestShouldReferenceComponentsGivenOneOfTypesWithComponents(t *testing.T) {
+	// Arrange
+	registerUnionNames(t)
+
+	// Act
+	schema, components := GenerateSchemaWithComponents(reflect.TypeOf(unionGarage{}))
+
+	// Assert
+	occupant := schema["properties"].(map[string]any)["occupant"].(map[string]any)
+	require.Len(t, occupant["oneOf"], 2)
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/unionPerson"}, occupant["oneOf"].([]any)[0])
+	assert.Contains(t, components, "unionCar")
+}
+
+func TestShouldSkipUnregisteredOneOfTypesNameGivenUnknownName(t *testing.T) {
+	// Arrange
+	registerUnionNames(t)
+	type Slot struct {
+		Value  any   `json:"value" oneOfTypes:"Boat"`
+		Mixed  any   `json:"mixed" oneOfTypes:"Car,Boat,Plane"`
+		Values []any `json:"values" oneOfTypes:"Boat"`
+	}
+	car := GenerateSchema(reflect.TypeOf(unionCar{}))
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(Slot{}))
+
+	// Assert
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"not":      map[string]any{},
+		"$comment": `oneOfTypes: "Boat" not registered with RegisterTypeName`,
+	}, props["value"])
+	assert.Equal(t, map[string]any{
+		"oneOf":    []any{car},
+		"$comment": `oneOfTypes: "Boat", "Plane" not registered with RegisterTypeName`,
+	}, props["mixed"])
+	assert.Equal(t, map[string]any{
+		"type": "array",
+		"items": map[string]any{
+			"not":      map[string]any{},
+			"$comment": `oneOfTypes: "Boat" not registered with RegisterTypeName`,
+		},
+	}, props["values"])
+	assert.Error(t, Validate(schema, map[string]any{"value": 42}))
+}
+
+func TestShouldReturnUnknownTypeNameErrorGivenStrictGeneration(t *testing.T) {
+	// Arrange
+	registerUnionNames(t)
+	type Slot struct {
+		Mixed  any            `json:"mixed" oneOfTypes:"Car,Boat"`
+		ByName map[string]any `json:"byName" oneOfTypes:"Plane"`
+	}
+	typ := reflect.TypeOf(Slot{})
+
+	// Act
+	schema, err := GenerateSchemaStrict(typ)
+	generated, genErr := NewGenerator().Generate(typ)
+
+	// Assert
+	assert.Nil(t, schema)
+	assert.Nil(t, generated)
+	assert.EqualError(t, err, `jsonschema: field mixed lists oneOfTypes name "Boat" not registered with RegisterTypeName`+"\n"+
+		`jsonschema: field byName lists oneOfTypes name "Plane" not registered with RegisterTypeName`)
+	var nameErr *UnknownTypeNameError
+	require.ErrorAs(t, genErr, &nameErr)
+	assert.Equal(t, "mixed", nameErr.Path)
+	assert.Equal(t, "Boat", nameErr.Name)
+}
@@ -0,0 +1,44 @@
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DanglingRefError reports a same-document "$ref" that does not resolve.
+// Path is the JSON Pointer of the schema object holding the reference (for
+// example "/properties/owner") and Ref the reference as written.
+type DanglingRefError struct {
+	Path string
+	Ref  string
+}
+
+func (e *DanglingRefError) Error() string {
+	return fmt.Sprintf("jsonschema: $ref %q at %s does not resolve", e.Ref, e.Path)
+}
+
+// ValidateSchemaRefs checks that every same-document "$ref" in schema
+// (#/$defs/X, #/definitions/X, #/components/schemas/X, or a #Anchor)
+// resolves against schema as the root, catching $ref, then, and similar tags
+// that name a definition the document does not contain. References to other
+// documents, such as those registered with RegisterExternalRef, are not
+// checked. Schemas from SchemaWithComponents must be checked with their
+// components placed under "components/schemas". It returns nil when all
+// references resolve, or the joined *DanglingRefError values in document
+// order.
+//
+// Definitions are held in maps, so encoding/json (and GenerateSchemaYAML)
+// always emit them sorted by name.
+func ValidateSchemaRefs(schema map[string]any) error {
+	var errs []error
+	var path validationPath
+	walkSchemaObjects(&path, schema, func(node map[string]any) {
+		if ref, ok := node[RefKey].(string); ok && strings.HasPrefix(ref, "#") {
+			if _, err := resolveRef(schema, ref); err != nil {
+				errs = append(errs, &DanglingRefError{Path: examplePath(&path), Ref: ref})
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
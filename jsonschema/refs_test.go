@@ -0,0 +1,91 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldReportDanglingRefGivenRefTagToMissingDefinition(t *testing.T) {
+	// Arrange
+	type Order struct {
+		Owner   string `json:"owner" $ref:"#/$defs/Customer"`
+		Address string `json:"address" $ref:"#/$defs/Address"`
+	}
+	schema := GenerateSchema(reflect.TypeOf(Order{}))
+	schema["$defs"] = map[string]any{"Address": map[string]any{"type": "object"}}
+
+	// Act
+	err := ValidateSchemaRefs(schema)
+
+	// Assert
+	require.Error(t, err)
+	var dangling *DanglingRefError
+	require.ErrorAs(t, err, &dangling)
+	assert.Equal(t, "/properties/owner", dangling.Path)
+	assert.Equal(t, "#/$defs/Customer", dangling.Ref)
+	assert.NotContains(t, err.Error(), "Address")
+}
+
+func TestShouldAcceptResolvedRefsGivenGeneratedSchemas(t *testing.T) {
+	// Arrange
+	type Tag struct {
+		Name string `json:"name"`
+	}
+	type Zone struct {
+		ID string `json:"id"`
+	}
+	type Node struct {
+		Zone     Zone    `json:"zone"`
+		Tags     []Tag   `json:"tags"`
+		Children []*Node `json:"children"`
+		Site     string  `json:"site" $ref:"site.json"`
+	}
+	components, err := NewGenerator(WithDefs()).Generate(reflect.TypeOf(Node{}))
+	require.NoError(t, err)
+
+	// Act
+	defsErr := ValidateSchemaRefs(components)
+	inlineErr := ValidateSchemaRefs(GenerateSchema(reflect.TypeOf(Node{})))
+
+	// Assert
+	assert.NoError(t, defsErr)
+	assert.NoError(t, inlineErr)
+	data, err := json.Marshal(components)
+	require.NoError(t, err)
+	tagAt := strings.Index(string(data), `"Tag":`)
+	zoneAt := strings.Index(string(data), `"Zone":`)
+	require.Positive(t, tagAt)
+	assert.Less(t, tagAt, zoneAt)
+}
+
+func TestShouldReportDanglingRefGivenPropertyNamedLikeKeyword(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		path   string
+	}{
+		{name: "properties", schema: `{"properties":{"default":{"$ref":"#/$defs/Missing"}}}`, path: "/properties/default"},
+		{name: "patternProperties", schema: `{"patternProperties":{"enum":{"$ref":"#/$defs/Missing"}}}`, path: "/patternProperties/enum"},
+		{name: "$defs", schema: `{"$defs":{"const":{"$ref":"#/$defs/Missing"}}}`, path: "/$defs/const"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var schema map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tt.schema), &schema))
+
+			// Act
+			err := ValidateSchemaRefs(schema)
+
+			// Assert
+			var dangling *DanglingRefError
+			require.ErrorAs(t, err, &dangling)
+			assert.Equal(t, tt.path, dangling.Path)
+		})
+	}
+}
@@ -123,6 +123,35 @@ func BenchmarkGenerateSchema_TaggedHotPath(b *testing.B) {
 	}
 }
 
+func BenchmarkGenerateSchema_Uncached(b *testing.B) {
+	benchmarkSetup(b)
+	typ := reflect.TypeOf(benchNestedStruct{})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = NewBuilder().schemaInternal(typ, false)
+	}
+}
+
+func BenchmarkGenerateSchemaCached_NestedStruct(b *testing.B) {
+	typ := reflect.TypeOf(benchNestedStruct{})
+	b.Run("cold", func(b *testing.B) {
+		benchmarkSetup(b)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			clearSchemaCache()
+			_ = GenerateSchemaCached(typ)
+		}
+	})
+	b.Run("warm", func(b *testing.B) {
+		benchmarkSetup(b)
+		_ = GenerateSchemaCached(typ)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = GenerateSchemaCached(typ)
+		}
+	})
+}
+
 // ---------- Validate benchmarks ----------
 
 func BenchmarkValidate_SimpleStruct(b *testing.B) {
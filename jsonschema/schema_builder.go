@@ -1,5 +1,5 @@
 // Package jsonschema provides helpers to generate JSON Schema documents
-// from Go types. Generated schemas are compatible with JSON Schema draft 2019-09.
+// from Go types. Generated schemas are compatible with JSON Schema draft 2020-12.
 // It supports building a root schema and collecting reusable component schemas
 // for nested types.
 package jsonschema
@@ -7,6 +7,7 @@ package jsonschema
 import (
 	"reflect"
 	"strings"
+	"unicode"
 
 	"github.com/fgrzl/json/polymorphic"
 )
@@ -23,6 +24,97 @@ import (
 type Builder struct {
 	components                 map[string]any
 	usesCustomRegisteredSchema bool
+	usesPolymorphicRegistry    bool
+	fieldTitles                bool
+	defaultExamples            bool
+	validateTags               bool
+	durationString             bool
+	unevaluatedProperties      *bool
+	includeFields              map[string]bool
+	excludeFields              map[string]bool
+
+	// Recursion tracking for inline schemas: types being generated, types
+	// found to refer to themselves, and hoisted definitions keyed by anchor.
+	rootType   reflect.Type
+	inProgress map[reflect.Type]bool
+	recursive  map[reflect.Type]bool
+	anchors    map[reflect.Type]string
+	defs       map[string]any
+
+	// interfaces being expanded into oneOf unions, to stop cycles that
+	// struct tracking does not cover.
+	interfaces map[reflect.Type]bool
+}
+
+// BuilderOption configures optional Builder behavior.
+type BuilderOption func(*Builder)
+
+// WithFieldTitles sets each property's title to its humanized Go field name
+// (for example "FirstName" becomes "First Name") when the field has no
+// explicit title tag. Useful for developer-facing schemas; off by default.
+func WithFieldTitles() BuilderOption {
+	return func(b *Builder) {
+		b.fieldTitles = true
+	}
+}
+
+// WithDefaultExamples echoes each field's default tag into examples when the
+// field has no explicit examples tag. The example is parsed according to the
+// field's Go type, so default:"5" on an int field yields "examples": [5];
+// values that do not parse are kept as strings.
+func WithDefaultExamples() BuilderOption {
+	return func(b *Builder) {
+		b.defaultExamples = true
+	}
+}
+
+// WithDurationAsString describes time.Duration as {"type":"string",
+// "format":"duration"}, for APIs that serialize durations as strings such as
+// "5s". By default a Duration is {"type":"integer","format":"int64"}, matching
+// encoding/json's nanosecond count. A schema registered for time.Duration with
+// RegisterSchema takes precedence.
+func WithDurationAsString() BuilderOption {
+	return func(b *Builder) {
+		b.durationString = true
+	}
+}
+
+// WithUnevaluatedProperties sets unevaluatedProperties on the root object
+// schema. With allowed false, a draft 2019-09 or later validator rejects
+// members that no properties, allOf, anyOf, oneOf, or if/then/else branch
+// describes, which additionalProperties cannot do for composed schemas.
+// Nested objects use the unevaluatedProperties tag instead.
+func WithUnevaluatedProperties(allowed bool) BuilderOption {
+	return func(b *Builder) {
+		b.unevaluatedProperties = &allowed
+	}
+}
+
+// WithIncludeFields projects the root object onto the named properties (JSON
+// names): every other property is dropped from properties and required.
+// Nested objects are not filtered.
+func WithIncludeFields(names ...string) BuilderOption {
+	return func(b *Builder) {
+		b.includeFields = fieldSet(b.includeFields, names)
+	}
+}
+
+// WithExcludeFields drops the named properties (JSON names) from the root
+// object's properties and required. Nested objects are not filtered.
+func WithExcludeFields(names ...string) BuilderOption {
+	return func(b *Builder) {
+		b.excludeFields = fieldSet(b.excludeFields, names)
+	}
+}
+
+func fieldSet(set map[string]bool, names []string) map[string]bool {
+	if set == nil {
+		set = make(map[string]bool, len(names))
+	}
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
 }
 
 // NewBuilder returns a new Builder with an initialized components map.
@@ -34,9 +126,48 @@ type Builder struct {
 //
 // The returned Builder can be reused for additional generations, but
 // its internal components map will only be populated when calling
-// SchemaWithComponents.
-func NewBuilder() *Builder {
-	return &Builder{components: make(map[string]any)}
+// SchemaWithComponents. Options adjust the generated output; Builders
+// created with options bypass the shared per-type schema cache.
+func NewBuilder(opts ...BuilderOption) *Builder {
+	b := &Builder{components: make(map[string]any)}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// usesDefaults reports whether the Builder produces the default output, which
+// is the only output stored in and served from the shared schema cache.
+func (b *Builder) usesDefaults() bool {
+	return !b.fieldTitles && !b.defaultExamples && !b.validateTags && !b.durationString && b.unevaluatedProperties == nil && b.includeFields == nil && b.excludeFields == nil
+}
+
+// applyRootOptions applies the options that only affect the root schema.
+func (b *Builder) applyRootOptions(schema map[string]any) map[string]any {
+	schema = b.projectFields(schema)
+	if b.unevaluatedProperties != nil && schema[TypeKey] == TypeObject {
+		schema[UnevaluatedPropertiesKey] = *b.unevaluatedProperties
+	}
+	return schema
+}
+
+// projectFields applies the include and exclude options to the root schema.
+func (b *Builder) projectFields(schema map[string]any) map[string]any {
+	props, ok := schema[PropertiesKey].(map[string]any)
+	if !ok || (b.includeFields == nil && b.excludeFields == nil) {
+		return schema
+	}
+	removed := map[string]bool{}
+	for name := range props {
+		if (b.includeFields != nil && !b.includeFields[name]) || b.excludeFields[name] {
+			delete(props, name)
+			removed[name] = true
+		}
+	}
+	if len(removed) > 0 {
+		dropRequired(schema, removed)
+	}
+	return schema
 }
 
 // Components returns the map of collected component schemas.
@@ -58,12 +189,18 @@ func (b *Builder) Components() map[string]any {
 // reflect.Type will cause a panic in the current implementation.
 func (b *Builder) Schema(t reflect.Type) map[string]any {
 	b.usesCustomRegisteredSchema = false
+	b.usesPolymorphicRegistry = false
+	if !b.usesDefaults() {
+		return b.applyRootOptions(b.inlineSchema(t))
+	}
 	if schema, ok := getCachedSchema(t); ok {
 		return schema
 	}
 
-	schema := b.schemaInternal(t, false)
-	cacheSchema(t, schema, !b.usesCustomRegisteredSchema)
+	schema := b.inlineSchema(t)
+	if !b.usesPolymorphicRegistry {
+		cacheSchema(t, schema, !b.usesCustomRegisteredSchema)
+	}
 	return schema
 }
 
@@ -80,6 +217,11 @@ func (b *Builder) Schema(t reflect.Type) map[string]any {
 // Note: Passing a nil reflect.Type will panic.
 func (b *Builder) SchemaWithComponents(t reflect.Type) (map[string]any, map[string]any) {
 	b.usesCustomRegisteredSchema = false
+	b.usesPolymorphicRegistry = false
+	if !b.usesDefaults() {
+		b.components = make(map[string]any)
+		return b.applyRootOptions(b.schemaInternalRoot(t, true)), b.components
+	}
 	if root, components, ok := getCachedSchemaWithComponents(t); ok {
 		b.components = components
 		return root, components
@@ -87,11 +229,46 @@ func (b *Builder) SchemaWithComponents(t reflect.Type) (map[string]any, map[stri
 
 	b.components = make(map[string]any)
 	root := b.schemaInternalRoot(t, true)
-	cacheSchema(t, root, !b.usesCustomRegisteredSchema)
-	cacheSchemaWithComponents(t, root, b.components, !b.usesCustomRegisteredSchema)
+	if !b.usesPolymorphicRegistry {
+		cacheSchemaWithComponents(t, root, b.components, !b.usesCustomRegisteredSchema)
+	}
 	return root, b.components
 }
 
+// inlineSchema generates the schema for t without components. Recursive
+// struct types cannot be inlined: the root type gets an $anchor that its
+// recursive uses reference, and other recursive types are hoisted into
+// $defs with an $anchor and referenced by it.
+func (b *Builder) inlineSchema(t reflect.Type) map[string]any {
+	if t == nil {
+		panic("reflect.Type must not be nil")
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	b.rootType = t
+	b.inProgress = make(map[reflect.Type]bool)
+	b.recursive = make(map[reflect.Type]bool)
+	b.anchors = make(map[reflect.Type]string)
+	b.defs = make(map[string]any)
+	defer func() {
+		b.rootType, b.inProgress, b.recursive, b.anchors, b.defs = nil, nil, nil, nil, nil
+	}()
+
+	schema := b.schemaInternal(t, false)
+	if len(b.defs) > 0 {
+		defs, _ := schema[DefsKey].(map[string]any)
+		if defs == nil {
+			defs = make(map[string]any, len(b.defs))
+			schema[DefsKey] = defs
+		}
+		for name, def := range b.defs {
+			defs[name] = def
+		}
+	}
+	return schema
+}
+
 func (b *Builder) schemaInternalRoot(t reflect.Type, asRef bool) map[string]any {
 	if t == nil {
 		panic("reflect.Type must not be nil")
@@ -102,12 +279,18 @@ func (b *Builder) schemaInternalRoot(t reflect.Type, asRef bool) map[string]any
 		t = t.Elem()
 	}
 
+	if b.durationString && t == durationType && !isCustomRegisteredType(t) {
+		return stringDurationSchema()
+	}
 	if schema, ok := getRegisteredSchema(t); ok {
 		if isCustomRegisteredType(t) {
 			b.usesCustomRegisteredSchema = true
 		}
 		return schema
 	}
+	if _, ok := sqlNullValueType(t); ok {
+		return b.schemaInternal(t, asRef)
+	}
 
 	switch t.Kind() {
 	case reflect.Struct:
@@ -116,10 +299,11 @@ func (b *Builder) schemaInternalRoot(t reflect.Type, asRef bool) map[string]any
 		// Add root type to components if eligible for refs
 		if asRef && t.Name() != "" && isEligibleForRef(t) {
 			// If this is a circular reference, add to components
-			if b.hasSelfReference(schema, t.Name()) {
-				b.components[t.Name()] = schema
+			name := componentName(t)
+			if b.hasSelfReference(schema, name) {
+				b.components[name] = schema
 			} else if len(b.components) == 0 && b.hasOnlyPrimitiveFields(t) {
-				b.components[t.Name()] = schema
+				b.components[name] = schema
 			}
 		}
 		return schema
@@ -157,12 +341,24 @@ func (b *Builder) schemaInternal(t reflect.Type, asRef bool) map[string]any {
 		t = t.Elem()
 	}
 
+	if ref, ok := externalRef(t); ok && t != b.rootType {
+		return map[string]any{RefKey: ref}
+	}
+
+	if b.durationString && t == durationType && !isCustomRegisteredType(t) {
+		return stringDurationSchema()
+	}
 	if schema, ok := getRegisteredSchema(t); ok {
 		if isCustomRegisteredType(t) {
 			b.usesCustomRegisteredSchema = true
 		}
 		return schema
 	}
+	if valueType, ok := sqlNullValueType(t); ok {
+		schema := b.schemaInternal(valueType, asRef)
+		makeNullable(schema)
+		return schema
+	}
 
 	switch t.Kind() {
 	case reflect.Struct:
@@ -186,7 +382,12 @@ func (b *Builder) schemaInternal(t reflect.Type, asRef bool) map[string]any {
 		return map[string]any{TypeKey: TypeBoolean}
 	case reflect.String:
 		return map[string]any{TypeKey: TypeString}
-	case reflect.Invalid, reflect.Interface, reflect.Pointer, reflect.Chan, reflect.Func,
+	case reflect.Interface:
+		if schema, ok := b.interfaceSchema(t, asRef); ok {
+			return schema
+		}
+		return map[string]any{TypeKey: TypeString}
+	case reflect.Invalid, reflect.Pointer, reflect.Chan, reflect.Func,
 		reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
 		return map[string]any{TypeKey: TypeString}
 	}
@@ -194,6 +395,19 @@ func (b *Builder) schemaInternal(t reflect.Type, asRef bool) map[string]any {
 }
 
 func (b *Builder) structSchema(t reflect.Type, useRef bool) map[string]any {
+	tracked := !useRef && t.Name() != "" && b.inProgress != nil
+	if tracked {
+		if name, ok := b.anchors[t]; ok {
+			return map[string]any{RefKey: "#" + name}
+		}
+		if b.inProgress[t] {
+			b.recursive[t] = true
+			return map[string]any{RefKey: "#" + componentName(t)}
+		}
+		b.inProgress[t] = true
+		defer delete(b.inProgress, t)
+	}
+
 	schema := map[string]any{TypeKey: TypeObject}
 	properties := map[string]any{}
 	var required []string
@@ -202,23 +416,39 @@ func (b *Builder) structSchema(t reflect.Type, useRef bool) map[string]any {
 		schema[IDKey] = p.GetDiscriminator()
 	}
 
-	b.populateStructFields(t, useRef, properties, &required)
+	b.populateStructFields(t, useRef, schema, properties, &required)
 
 	schema[PropertiesKey] = properties
 	if len(required) > 0 {
 		schema[RequiredKey] = required
 	}
-
+	applyObjectConditionals(t, schema)
+
+	if tracked && b.recursive[t] {
+		name := componentName(t)
+		schema[AnchorKey] = name
+		if t != b.rootType {
+			b.defs[name] = schema
+			b.anchors[t] = name
+			return map[string]any{RefKey: "#" + name}
+		}
+	}
 	return schema
 }
 
-func (b *Builder) populateStructFields(t reflect.Type, useRef bool, properties map[string]any, required *[]string) {
+func (b *Builder) populateStructFields(t reflect.Type, useRef bool, schema, properties map[string]any, required *[]string) {
 	for i := 0; i < t.NumField(); i++ {
-		b.populateStructField(t, useRef, properties, required, t.Field(i))
+		field := t.Field(i)
+		b.populateStructField(t, useRef, schema, properties, required, field)
+		if id, ok := registeredValidatorID(t, field.Name); ok {
+			if fieldSchema, ok := properties[jsonFieldName(field)].(map[string]any); ok {
+				fieldSchema[ValidatorKey] = id
+			}
+		}
 	}
 }
 
-func (b *Builder) populateStructField(parentType reflect.Type, useRef bool, properties map[string]any, required *[]string, field reflect.StructField) {
+func (b *Builder) populateStructField(parentType reflect.Type, useRef bool, schema, properties map[string]any, required *[]string, field reflect.StructField) {
 	if field.PkgPath != "" || jsonFieldName(field) == "-" {
 		return
 	}
@@ -231,33 +461,142 @@ func (b *Builder) populateStructField(parentType reflect.Type, useRef bool, prop
 
 	ft := field.Type
 	ftKind := ft.Kind()
-	baseType, baseKind := unwrapSchemaType(ft)
+	baseType, _ := unwrapSchemaType(ft)
 
-	if field.Anonymous && baseKind == reflect.Struct {
-		jsonTag := field.Tag.Get(JSONTag)
-		if jsonTag != "" && strings.Contains(jsonTag, "inline") {
-			b.mergeEmbeddedStruct(properties, required, baseType)
+	if field.Anonymous && hasJSONOption(field, "inline") {
+		inlineType := ft
+		for inlineType.Kind() == reflect.Pointer {
+			inlineType = inlineType.Elem()
+		}
+		switch inlineType.Kind() {
+		case reflect.Struct:
+			b.mergeEmbeddedStruct(schema, properties, required, inlineType)
+			return
+		case reflect.Map:
+			b.mergeEmbeddedMap(schema, inlineType)
 			return
 		}
 	}
 
 	if useRef && baseType.Name() != "" && isEligibleForRef(baseType) {
 		b.addReferencedStructField(parentType, properties, name, ftKind, baseType, useRef)
+		if b.validateTags && applyValidateTag(field, properties[name].(map[string]any)) && !isOptionalField(field) {
+			*required = append(*required, name)
+		}
 		return
 	}
 
-	fieldSchema := b.schemaInternal(field.Type, useRef)
+	fieldSchema, ok := b.oneOfTypesSchema(field, useRef)
+	if !ok {
+		fieldSchema = b.schemaInternal(field.Type, useRef)
+	}
 	applyFieldTags(field, fieldSchema)
+	validateRequired := b.validateTags && applyValidateTag(field, fieldSchema)
+	if b.fieldTitles {
+		if _, ok := fieldSchema[TitleKey]; !ok {
+			fieldSchema[TitleKey] = humanizeFieldName(field.Name)
+		}
+	}
+	if b.defaultExamples {
+		if val := field.Tag.Get(DefaultKey); val != "" && field.Tag.Get(ExamplesKey) == "" {
+			fieldSchema[ExamplesKey] = []any{typedTagValue(field.Type, val)}
+		}
+	}
+	applyStringOption(field, fieldSchema)
+
+	// Required and nullable are independent: required only means the key
+	// must be present, while a pointer (or nullable:"true") additionally
+	// permits an explicit null unless nullable:"false" opts out. The
+	// optional tag is the exception: it implies nullable and not required.
+	if isNullableField(field) {
+		makeNullable(fieldSchema)
+	}
 
-	if field.Tag.Get(RequiredKey) == "true" || field.Tag.Get("binding") == "required" {
+	if isRequiredField(field, validateRequired) {
 		*required = append(*required, name)
 	}
 
 	properties[name] = fieldSchema
 }
 
-func (b *Builder) mergeEmbeddedStruct(properties map[string]any, required *[]string, embeddedType reflect.Type) {
+// humanizeFieldName splits a Go identifier into words at case boundaries,
+// keeping acronyms together: "FirstName" becomes "First Name" and
+// "HTTPServerID" becomes "HTTP Server ID".
+func humanizeFieldName(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// isNullableField reports whether a field's schema should admit null. Pointer
+// and optional:"true" fields are nullable by default; the nullable tag
+// overrides either way.
+func isNullableField(field reflect.StructField) bool {
+	switch field.Tag.Get(NullableTag) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return field.Type.Kind() == reflect.Pointer || isOptionalField(field)
+}
+
+// isRequiredField reports whether the field belongs in the object's required
+// list: it is tagged required (or validated as required) and not optional.
+func isRequiredField(field reflect.StructField, validateRequired bool) bool {
+	if isOptionalField(field) {
+		return false
+	}
+	return validateRequired || field.Tag.Get(RequiredKey) == "true" || field.Tag.Get("binding") == "required"
+}
+
+// isOptionalField reports whether the field carries optional:"true", which
+// makes a value field nullable and keeps it out of required.
+func isOptionalField(field reflect.StructField) bool {
+	return field.Tag.Get(OptionalTag) == "true"
+}
+
+// makeNullable adds "null" to the schema's type union. A bare $ref is
+// wrapped in anyOf with a null schema. Other schemas without a type (such
+// as the empty schema) already accept null and are left as is.
+func makeNullable(schema map[string]any) {
+	if ref, ok := schema[RefKey]; ok && schema[TypeKey] == nil {
+		delete(schema, RefKey)
+		schema[AnyOfKey] = []any{map[string]any{RefKey: ref}, map[string]any{TypeKey: "null"}}
+		return
+	}
+	switch typed := schema[TypeKey].(type) {
+	case string:
+		if typed != "null" {
+			schema[TypeKey] = []any{typed, "null"}
+		}
+	case []any:
+		for _, t := range typed {
+			if t == "null" {
+				return
+			}
+		}
+		schema[TypeKey] = append(append([]any{}, typed...), "null")
+	}
+}
+
+func (b *Builder) mergeEmbeddedStruct(schema, properties map[string]any, required *[]string, embeddedType reflect.Type) {
 	embedded := b.schemaInternal(embeddedType, false)
+	if additional, ok := embedded[AdditionalPropertiesKey]; ok {
+		if _, exists := schema[AdditionalPropertiesKey]; !exists {
+			schema[AdditionalPropertiesKey] = additional
+		}
+	}
 
 	if props, ok := embedded[PropertiesKey].(map[string]any); ok {
 		for k, v := range props {
@@ -279,8 +618,15 @@ func (b *Builder) mergeEmbeddedStruct(properties map[string]any, required *[]str
 	}
 }
 
+// mergeEmbeddedMap describes an inlined map: its entries become extra members
+// of the enclosing object, so the map's value schema is the object's
+// additionalProperties.
+func (b *Builder) mergeEmbeddedMap(schema map[string]any, mapType reflect.Type) {
+	schema[AdditionalPropertiesKey] = b.schemaInternal(mapType.Elem(), false)
+}
+
 func (b *Builder) addReferencedStructField(parentType reflect.Type, properties map[string]any, name string, ftKind reflect.Kind, baseType reflect.Type, useRef bool) {
-	refName := baseType.Name()
+	refName := componentName(baseType)
 	if baseType != parentType {
 		if _, exists := b.components[refName]; !exists {
 			b.components[refName] = b.schemaInternal(baseType, useRef)
@@ -314,6 +660,28 @@ func (b *Builder) assignReferenceProperty(properties map[string]any, name string
 	}
 }
 
+// componentName returns the component key for a named type. Instantiated
+// generic types are named like "Page[example.com/app.User]", which is not a
+// valid key, so their package paths are dropped and the type arguments are
+// joined with underscores: Page[User] becomes "Page_User" and
+// Pair[string,[]User] becomes "Pair_string_List_User".
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	if !strings.Contains(name, "[") {
+		return name
+	}
+	name = strings.ReplaceAll(name, "[]", " List ")
+	tokens := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '[' || r == ']' || r == ',' || r == '*' || r == ' '
+	})
+	for i, token := range tokens {
+		if dot := strings.LastIndex(token, "."); dot >= 0 {
+			tokens[i] = token[dot+1:]
+		}
+	}
+	return strings.Join(tokens, "_")
+}
+
 func unwrapSchemaType(t reflect.Type) (reflect.Type, reflect.Kind) {
 	for {
 		kind := t.Kind()
@@ -337,6 +705,12 @@ func isEligibleForRef(t reflect.Type) bool {
 	if t.Kind() != reflect.Struct {
 		return false
 	}
+	if _, external := externalRef(t); external {
+		return false
+	}
+	if _, ok := sqlNullValueType(t); ok {
+		return false
+	}
 
 	_, known := getRegisteredSchema(t)
 	return !known
@@ -6,6 +6,8 @@ import (
 	"net"
 	"net/url"
 	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -45,17 +47,43 @@ const (
 	ExclusiveMaximumKey     = "exclusiveMaximum"
 	PatternPropertiesKey    = "patternProperties"
 	ContainsKey             = "contains"
+	MinContainsKey          = "minContains"
+	MaxContainsKey          = "maxContains"
+	PrefixItemsKey          = "prefixItems"
+	PropertyNamesKey        = "propertyNames"
 	IfKey                   = "if"
 	ThenKey                 = "then"
 	ElseKey                 = "else"
 	DefsKey                 = "$defs"
+	AnchorKey               = "$anchor"
 	SchemaKey               = "$schema"
 	IDKey                   = "$id"
 	OneOfKey                = "oneOf"
 	AnyOfKey                = "anyOf"
 	AllOfKey                = "allOf"
 	NotKey                  = "not"
+	CommentKey              = "$comment"
+	ReadOnlyKey             = "readOnly"
+	WriteOnlyKey            = "writeOnly"
+	ValidatorKey            = "x-validator"
+	DiscriminatorKey        = "discriminator"
 	JSONTag                 = "json"
+	NullableTag             = "nullable"
+	OptionalTag             = "optional"
+	CommentTag              = "comment"
+	IfEqualsTag             = "ifEquals"
+	OneOfTypesTag           = "oneOfTypes"
+
+	// UnevaluatedPropertiesKey is the draft 2019-09 keyword rejecting members
+	// not described by any subschema, including allOf branches.
+	UnevaluatedPropertiesKey = "unevaluatedProperties"
+
+	// ContentMediaTypeKey and ContentSchemaKey describe a string holding an
+	// encoded document; numeric fields tagged `json:",string"` use them with
+	// ContentTypeJSON so their constraints apply to the decoded number.
+	ContentMediaTypeKey = "contentMediaType"
+	ContentSchemaKey    = "contentSchema"
+	ContentTypeJSON     = "application/json"
 
 	// Schema types
 	TypeArray   = "array"
@@ -75,10 +103,14 @@ func builtinSchemas() map[reflect.Type]map[string]any {
 		reflect.TypeOf([]byte{}):               {TypeKey: TypeString, FormatKey: "byte"},
 		reflect.TypeOf((*url.URL)(nil)).Elem(): {TypeKey: TypeString, FormatKey: "uri"},
 		reflect.TypeOf(net.IP{}):               {TypeKey: TypeString, FormatKey: "ipv4"},
+		durationType:                           {TypeKey: TypeInteger, FormatKey: "int64"},
 
 		// Nullable SQL types
 		reflect.TypeOf(sql.NullString{}):  {TypeKey: []any{TypeString, "null"}},
 		reflect.TypeOf(sql.NullInt64{}):   {TypeKey: []any{TypeInteger, "null"}},
+		reflect.TypeOf(sql.NullInt32{}):   {TypeKey: []any{TypeInteger, "null"}},
+		reflect.TypeOf(sql.NullInt16{}):   {TypeKey: []any{TypeInteger, "null"}},
+		reflect.TypeOf(sql.NullByte{}):    {TypeKey: []any{TypeInteger, "null"}},
 		reflect.TypeOf(sql.NullBool{}):    {TypeKey: []any{TypeBoolean, "null"}},
 		reflect.TypeOf(sql.NullFloat64{}): {TypeKey: []any{TypeNumber, "null"}},
 		reflect.TypeOf(sql.NullTime{}): {
@@ -88,6 +120,21 @@ func builtinSchemas() map[reflect.Type]map[string]any {
 	}
 }
 
+// sqlNullValueType reports whether t is an instantiation of the generic
+// sql.Null[T] and returns T. Instantiations cannot be listed in
+// builtinSchemas, so they are recognized by name and described as T's schema
+// plus null.
+func sqlNullValueType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct || t.PkgPath() != "database/sql" || !strings.HasPrefix(t.Name(), "Null[") {
+		return nil, false
+	}
+	field, ok := t.FieldByName("V")
+	if !ok {
+		return nil, false
+	}
+	return field.Type, true
+}
+
 // registeredSchemas maps Go types to their JSON Schema definitions.
 // It is process-wide global state; use ClearRegistry to reset to built-ins only.
 var registeredSchemas = builtinSchemas()
@@ -121,6 +168,85 @@ type schemaCacheEntry struct {
 	safe   bool
 }
 
+// SchemaProvider is implemented by types that know their own JSON Schema
+// better than reflection does (for example a money type serialized as a
+// decimal string). The method may use a value or pointer receiver; it is
+// called on the zero value, and the returned schema is used verbatim in
+// place of the reflected one. Schemas registered via RegisterSchema still
+// take precedence.
+type SchemaProvider interface {
+	JSONSchema() map[string]any
+}
+
+var schemaProviderType = reflect.TypeOf((*SchemaProvider)(nil)).Elem()
+
+// providedSchema returns the schema supplied by t's SchemaProvider
+// implementation, checking both t and *t.
+func providedSchema(t reflect.Type) (map[string]any, bool) {
+	if t.Kind() == reflect.Interface || t.Kind() == reflect.Pointer {
+		return nil, false
+	}
+
+	var provider SchemaProvider
+	switch {
+	case t.Implements(schemaProviderType):
+		provider, _ = reflect.New(t).Elem().Interface().(SchemaProvider)
+	case reflect.PointerTo(t).Implements(schemaProviderType):
+		provider, _ = reflect.New(t).Interface().(SchemaProvider)
+	default:
+		return nil, false
+	}
+
+	schema := provider.JSONSchema()
+	if schema == nil {
+		return nil, false
+	}
+	return cloneSchemaMap(schema), true
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// marshalerSchema returns an open schema for types implementing
+// json.Marshaler on t or *t, with a $comment noting the custom marshaling.
+// Implement SchemaProvider or use RegisterSchema to document the shape.
+// Duration types whose marshaler writes a string get the string duration
+// schema instead (see isStringDuration).
+func marshalerSchema(t reflect.Type) (map[string]any, bool) {
+	if t.Kind() == reflect.Interface || t.Kind() == reflect.Pointer {
+		return nil, false
+	}
+	if !t.Implements(jsonMarshalerType) && !reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return nil, false
+	}
+	if isStringDuration(t) {
+		return stringDurationSchema(), true
+	}
+	return map[string]any{CommentKey: "custom JSON marshaling: " + t.String()}, true
+}
+
+// durationType is time.Duration, which encoding/json writes as an integer
+// number of nanoseconds.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func stringDurationSchema() map[string]any {
+	return map[string]any{TypeKey: TypeString, FormatKey: "duration"}
+}
+
+// isStringDuration reports whether t, a json.Marshaler, is a duration type
+// serialized as a string such as "5s": its name contains "Duration" and its
+// zero value marshals to a JSON string.
+func isStringDuration(t reflect.Type) bool {
+	if !strings.Contains(t.Name(), "Duration") {
+		return false
+	}
+	marshaler, ok := reflect.New(t).Interface().(json.Marshaler)
+	if !ok {
+		return false
+	}
+	data, err := marshaler.MarshalJSON()
+	return err == nil && len(data) > 0 && data[0] == '"'
+}
+
 // rawMessageType is the reflect.Type for json.RawMessage and is used to
 // ensure RawMessage is treated as raw JSON (empty schema) rather than a
 // byte slice.
@@ -191,6 +317,17 @@ func getRegisteredSchema(t reflect.Type) (map[string]any, bool) {
 		return cloneSchemaMap(s), true
 	}
 
+	// Types that describe their own schema take precedence over reflection.
+	if s, ok := providedSchema(t); ok {
+		return s, true
+	}
+
+	// Custom marshalers control their own wire shape, so their Go fields
+	// say nothing reliable about the JSON.
+	if s, ok := marshalerSchema(t); ok {
+		return s, true
+	}
+
 	// If this is a slice/array of bytes (anonymous []byte or [N]byte),
 	// fall back to the []byte registered schema.
 	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Uint8 {
@@ -307,11 +444,12 @@ func cloneSchemaSliceWithState(items []any, state *schemaCloneState) []any {
 	return cloned
 }
 
-// GenerateSchema returns the JSON Schema for the provided reflect.Type.
-// It is a convenience wrapper around Builder.Schema.
-func GenerateSchema(t reflect.Type) map[string]any {
-	builder := NewBuilder()
-	return builder.Schema(t)
+// GenerateSchema returns the JSON Schema for the provided reflect.Type using
+// a default Generator; opts are passed to NewBuilder (for example
+// WithIncludeFields to generate a projection). Use NewGenerator to target a
+// specific draft or collect named types in $defs.
+func GenerateSchema(t reflect.Type, opts ...BuilderOption) map[string]any {
+	return NewGenerator(WithBuilderOptions(opts...)).generate(t)
 }
 
 // GenerateSchemaWithComponents returns the JSON Schema for the provided reflect.Type
@@ -321,6 +459,87 @@ func GenerateSchemaWithComponents(t reflect.Type) (map[string]any, map[string]an
 	return builder.SchemaWithComponents(t)
 }
 
+// GenerateRequestSchema returns the schema of t as a client would send it:
+// properties tagged readOnly:"true" (such as server-assigned IDs) are removed
+// at every level, along with their required entries.
+func GenerateRequestSchema(t reflect.Type) map[string]any {
+	schema := GenerateSchema(t)
+	omitFlaggedProperties(schema, ReadOnlyKey)
+	return schema
+}
+
+// GenerateResponseSchema returns the schema of t as a server would return it:
+// properties tagged writeOnly:"true" (such as passwords) are removed at every
+// level, along with their required entries.
+func GenerateResponseSchema(t reflect.Type) map[string]any {
+	schema := GenerateSchema(t)
+	omitFlaggedProperties(schema, WriteOnlyKey)
+	return schema
+}
+
+// omitFlaggedProperties removes, throughout schema, every property whose
+// schema sets flag to true and drops it from the matching required list.
+func omitFlaggedProperties(schema any, flag string) {
+	switch node := schema.(type) {
+	case map[string]any:
+		if props, ok := node[PropertiesKey].(map[string]any); ok {
+			removed := map[string]bool{}
+			for name, prop := range props {
+				if propSchema, ok := prop.(map[string]any); ok && propSchema[flag] == true {
+					delete(props, name)
+					removed[name] = true
+				}
+			}
+			if len(removed) > 0 {
+				dropRequired(node, removed)
+			}
+		}
+		for _, value := range node {
+			omitFlaggedProperties(value, flag)
+		}
+	case []any:
+		for _, item := range node {
+			omitFlaggedProperties(item, flag)
+		}
+	}
+}
+
+// dropRequired removes the removed names from schema's required list, which
+// is []string when generated and []any in registered schemas decoded from
+// JSON.
+func dropRequired(schema map[string]any, removed map[string]bool) {
+	switch required := schema[RequiredKey].(type) {
+	case []string:
+		kept := slices.DeleteFunc(slices.Clone(required), func(name string) bool { return removed[name] })
+		setRequired(schema, kept)
+	case []any:
+		kept := slices.DeleteFunc(slices.Clone(required), func(name any) bool {
+			s, _ := name.(string)
+			return removed[s]
+		})
+		setRequired(schema, kept)
+	}
+}
+
+func setRequired[T any](schema map[string]any, required []T) {
+	if len(required) == 0 {
+		delete(schema, RequiredKey)
+		return
+	}
+	schema[RequiredKey] = required
+}
+
+// GenerateSchemaCached returns the JSON Schema for the provided reflect.Type.
+// It is GenerateSchema without options, which already memoizes the result
+// per type; the name documents the caching at call sites that depend on it.
+// Each call returns an independent deep copy, so callers may mutate the
+// result freely. The cache is reset whenever the registry changes
+// (RegisterSchema or ClearRegistry); types with interface fields resolved
+// against the polymorphic registry are not cached.
+func GenerateSchemaCached(t reflect.Type) map[string]any {
+	return GenerateSchema(t)
+}
+
 func normalizeCacheType(t reflect.Type) reflect.Type {
 	for t != nil && t.Kind() == reflect.Pointer {
 		t = t.Elem()
@@ -445,11 +664,91 @@ func clearSchemaCache() {
 	schemaRawCacheMu.Unlock()
 }
 
+// stringEncodedKeys are the keywords that describe the decoded value of a
+// `json:",string"` field and move into its contentSchema.
+var stringEncodedKeys = []string{
+	TypeKey, FormatKey, MinimumKey, MaximumKey, ExclusiveMinimumKey,
+	ExclusiveMaximumKey, MultipleOfKey, EnumKey, ConstKey,
+}
+
+// applyStringOption rewrites the schema of a number or boolean field tagged
+// `json:",string"`, which encoding/json writes as a quoted value such as
+// "42": the field becomes a string with a pattern matching the encoding, and
+// its type and value constraints (minimum, maximum, enum, ...) move into
+// contentSchema under contentMediaType application/json, which Validate
+// applies to the decoded value. default and examples are quoted to match.
+func applyStringOption(field reflect.StructField, schema map[string]any) {
+	if !hasJSONOption(field, "string") {
+		return
+	}
+	t := field.Type
+	if t.Kind() == reflect.Pointer && t.Name() == "" {
+		t = t.Elem()
+	}
+	var pattern string
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		pattern = `^-?[0-9]+$`
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		pattern = `^[0-9]+$`
+	case reflect.Float32, reflect.Float64:
+		pattern = `^-?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?$`
+	case reflect.Bool:
+		pattern = `^(true|false)$`
+	case reflect.Invalid, reflect.Complex64, reflect.Complex128, reflect.Array, reflect.Chan,
+		reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice,
+		reflect.String, reflect.Struct, reflect.UnsafePointer:
+		return
+	}
+	switch schema[TypeKey] {
+	case TypeInteger, TypeNumber, TypeBoolean:
+	default:
+		// Registered and marshaler schemas describe their own encoding.
+		return
+	}
+
+	content := make(map[string]any)
+	for _, key := range stringEncodedKeys {
+		if value, ok := schema[key]; ok {
+			content[key] = value
+			delete(schema, key)
+		}
+	}
+	schema[TypeKey] = TypeString
+	schema[PatternKey] = pattern
+	schema[ContentMediaTypeKey] = ContentTypeJSON
+	schema[ContentSchemaKey] = content
+	if value, ok := schema[DefaultKey]; ok {
+		schema[DefaultKey] = quoteJSONValue(value)
+	}
+	if examples, ok := schema[ExamplesKey].([]any); ok {
+		quoted := make([]any, len(examples))
+		for i, example := range examples {
+			quoted[i] = quoteJSONValue(example)
+		}
+		schema[ExamplesKey] = quoted
+	}
+}
+
+// quoteJSONValue returns value in the quoted form encoding/json writes for
+// `json:",string"` fields.
+func quoteJSONValue(value any) any {
+	if _, ok := value.(string); ok {
+		return value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	return string(data)
+}
+
 // applyFieldTags applies struct tags to a field's JSON Schema.
 func applyFieldTags(field reflect.StructField, schema map[string]any) {
 	addNumericTags(field, schema)
 	addStringTags(field, schema)
 	addArrayTags(field, schema)
+	addMapTags(field, schema)
 	applyCommonFieldTags(field, schema)
 	applyExtensionTags(field, schema)
 	applySchemaKeywordTags(field, schema)
@@ -457,11 +756,7 @@ func applyFieldTags(field reflect.StructField, schema map[string]any) {
 
 func applyCommonFieldTags(field reflect.StructField, schema map[string]any) {
 	if val := field.Tag.Get(EnumKey); val != "" {
-		parts := strings.Split(val, ",")
-		for i := range parts {
-			parts[i] = strings.TrimSpace(parts[i])
-		}
-		schema[EnumKey] = parts
+		schema[EnumKey] = parseEnumTag(val)
 	}
 	if val := field.Tag.Get(TitleKey); val != "" {
 		schema[TitleKey] = val
@@ -469,17 +764,109 @@ func applyCommonFieldTags(field reflect.StructField, schema map[string]any) {
 	if val := field.Tag.Get(DescriptionKey); val != "" {
 		schema[DescriptionKey] = val
 	}
+	if val := field.Tag.Get(CommentTag); val != "" {
+		schema[CommentKey] = val
+	}
 	if val := field.Tag.Get(DefaultKey); val != "" {
-		schema[DefaultKey] = val
+		schema[DefaultKey] = typedDefaultValue(field.Type, schema, val)
+	}
+	if field.Tag.Get(ReadOnlyKey) == "true" {
+		schema[ReadOnlyKey] = true
+	}
+	if field.Tag.Get(WriteOnlyKey) == "true" {
+		schema[WriteOnlyKey] = true
 	}
 	if val := field.Tag.Get(AdditionalPropertiesKey); val != "" {
 		applyAdditionalPropertiesTag(field, schema, val)
 	}
+	if val := field.Tag.Get(UnevaluatedPropertiesKey); val != "" {
+		applyUnevaluatedPropertiesTag(schema, val)
+	}
 	if val := field.Tag.Get(FormatKey); val != "" {
 		schema[FormatKey] = val
 	}
 }
 
+// typedTagValue parses a tag value as the JSON value of a field of type t,
+// returning val unchanged when it does not parse.
+func typedTagValue(t reflect.Type, val string) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	trim := strings.TrimSpace(val)
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(trim); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(trim, 10, t.Bits()); err == nil {
+			return n
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, err := strconv.ParseUint(trim, 10, t.Bits()); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		// Check the range at the field's size but keep the written value:
+		// rounding to float32 would turn "0.1" into 0.10000000149011612.
+		if _, err := strconv.ParseFloat(trim, t.Bits()); err == nil {
+			f, _ := strconv.ParseFloat(trim, 64)
+			return f
+		}
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Interface:
+		var anyVal any
+		if err := json.Unmarshal([]byte(trim), &anyVal); err == nil {
+			return anyVal
+		}
+	case reflect.Invalid, reflect.Complex64, reflect.Complex128, reflect.Chan, reflect.Func,
+		reflect.Pointer, reflect.String, reflect.UnsafePointer:
+	}
+	return val
+}
+
+// typedDefaultValue parses a default tag as a value of the schema's type, so
+// an integer field's default is 5 rather than "5". Schemas without a scalar
+// type fall back to the field's Go type (see typedTagValue); values that do
+// not parse stay strings.
+func typedDefaultValue(t reflect.Type, schema map[string]any, val string) any {
+	trim := strings.TrimSpace(val)
+	switch schema[TypeKey] {
+	case TypeString:
+		return val
+	case TypeInteger:
+		if n, err := strconv.ParseInt(trim, 10, 64); err == nil {
+			return n
+		}
+	case TypeNumber:
+		if f, err := strconv.ParseFloat(trim, 64); err == nil {
+			return f
+		}
+	case TypeBoolean:
+		if b, err := strconv.ParseBool(trim); err == nil {
+			return b
+		}
+	}
+	return typedTagValue(t, val)
+}
+
+// parseEnumTag parses an enum tag. A JSON array (e.g. `[1,2,3]` or
+// `["a,b","c"]`) keeps its element types and allows commas inside values;
+// anything else is a comma-separated list of strings.
+func parseEnumTag(val string) any {
+	if trim := strings.TrimSpace(val); strings.HasPrefix(trim, "[") {
+		var values []any
+		if err := json.Unmarshal([]byte(trim), &values); err == nil {
+			return values
+		}
+	}
+	parts := strings.Split(val, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
 func applyAdditionalPropertiesTag(field reflect.StructField, schema map[string]any, val string) {
 	if val == "false" {
 		schema[AdditionalPropertiesKey] = false
@@ -509,6 +896,26 @@ func applyAdditionalPropertiesTag(field reflect.StructField, schema map[string]a
 	schema[AdditionalPropertiesKey] = map[string]any{}
 }
 
+// applyUnevaluatedPropertiesTag sets unevaluatedProperties from a tag holding
+// true, false, a $ref such as "#/$defs/Extra", or an inline JSON schema.
+func applyUnevaluatedPropertiesTag(schema map[string]any, val string) {
+	trim := strings.TrimSpace(val)
+	if b, err := strconv.ParseBool(trim); err == nil {
+		schema[UnevaluatedPropertiesKey] = b
+		return
+	}
+	if strings.HasPrefix(trim, "#") {
+		schema[UnevaluatedPropertiesKey] = map[string]any{RefKey: trim}
+		return
+	}
+	var sub map[string]any
+	if err := json.Unmarshal([]byte(trim), &sub); err == nil {
+		schema[UnevaluatedPropertiesKey] = sub
+		return
+	}
+	schema[UnevaluatedPropertiesKey] = map[string]any{TypeKey: trim}
+}
+
 func applyExtensionTags(field reflect.StructField, schema map[string]any) {
 	for key, val := range parseStructTag(string(field.Tag)) {
 		if !strings.HasPrefix(key, "x-") {
@@ -581,12 +988,152 @@ func applySchemaKeywordTags(field reflect.StructField, schema map[string]any) {
 		ExclusiveMinimumKey, ExclusiveMaximumKey, PatternPropertiesKey,
 		ContainsKey, IfKey, ThenKey, ElseKey, DefsKey, SchemaKey, IDKey,
 	} {
-		if val := field.Tag.Get(key); val != "" {
+		if (key == ThenKey || key == ElseKey) && field.Tag.Get(IfEqualsTag) != "" {
+			// Lifted to the enclosing object by applyObjectConditionals.
+			continue
+		}
+		val := field.Tag.Get(key)
+		switch {
+		case val == "":
+		case key == ExamplesKey:
+			schema[ExamplesKey] = examplesTagValue(field.Type, val)
+		default:
 			applySchemaKeywordTag(schema, key, val)
 		}
 	}
 }
 
+// examplesTagValue returns the examples array for an examples tag. A JSON
+// array lists the examples; any other value is a single example parsed per
+// the field's type, so examples:"5" on an int field emits [5].
+func examplesTagValue(t reflect.Type, val string) []any {
+	if trim := strings.TrimSpace(val); strings.HasPrefix(trim, "[") {
+		var examples []any
+		if err := json.Unmarshal([]byte(trim), &examples); err == nil {
+			return examples
+		}
+	}
+	return []any{typedTagValue(t, val)}
+}
+
+// applyObjectConditionals adds object-level if/then/else keywords for fields
+// tagged ifEquals. The condition matches when the field equals the tagged
+// value; the field's then and else tags (a $ref such as "#/components/schemas/Card",
+// inline JSON, or a bare type name) become the branches. The first conditional
+// is set on the object itself and any further ones are appended to allOf.
+func applyObjectConditionals(t reflect.Type, schema map[string]any) {
+	for _, field := range conditionalFields(t, map[reflect.Type]bool{}) {
+		val := field.Tag.Get(IfEqualsTag)
+		name := jsonFieldName(field)
+		conditional := map[string]any{
+			IfKey: map[string]any{
+				PropertiesKey: map[string]any{name: map[string]any{ConstKey: conditionalConst(field.Type, val)}},
+				RequiredKey:   []string{name},
+			},
+		}
+		if then := field.Tag.Get(ThenKey); then != "" {
+			conditional[ThenKey] = parseConditionalBranch(then)
+		}
+		if otherwise := field.Tag.Get(ElseKey); otherwise != "" {
+			conditional[ElseKey] = parseConditionalBranch(otherwise)
+		}
+
+		if _, exists := schema[IfKey]; !exists {
+			for key, value := range conditional {
+				schema[key] = value
+			}
+			continue
+		}
+		allOf, _ := schema[AllOfKey].([]any)
+		schema[AllOfKey] = append(allOf, conditional)
+	}
+}
+
+// conditionalFields returns the fields of t tagged ifEquals in declaration
+// order, including those promoted from embedded structs tagged
+// json:",inline", whose properties are merged into t's the same way. A
+// direct field hides a promoted one with the same JSON name.
+func conditionalFields(t reflect.Type, visiting map[reflect.Type]bool) []reflect.StructField {
+	if visiting[t] {
+		return nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	direct := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.PkgPath == "" && inlineStructType(field) == nil {
+			direct[jsonFieldName(field)] = true
+		}
+	}
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || jsonFieldName(field) == "-" {
+			continue
+		}
+		if embedded := inlineStructType(field); embedded != nil {
+			for _, promoted := range conditionalFields(embedded, visiting) {
+				if !direct[jsonFieldName(promoted)] {
+					fields = append(fields, promoted)
+				}
+			}
+			continue
+		}
+		if field.Tag.Get(IfEqualsTag) != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// inlineStructType returns the struct type of an embedded field tagged
+// json:",inline", or nil for any other field.
+func inlineStructType(field reflect.StructField) reflect.Type {
+	if !field.Anonymous || !hasJSONOption(field, "inline") {
+		return nil
+	}
+	t := field.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// conditionalConst converts an ifEquals tag value to the field's JSON type,
+// so `ifEquals:"2"` on an int field compares against the number 2.
+func conditionalConst(t reflect.Type, val string) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.String {
+		return val
+	}
+	var decoded any
+	if err := json.Unmarshal([]byte(val), &decoded); err == nil {
+		return decoded
+	}
+	return val
+}
+
+// parseConditionalBranch parses a then/else tag used with ifEquals.
+func parseConditionalBranch(val string) map[string]any {
+	trim := strings.TrimSpace(val)
+	if strings.HasPrefix(trim, "#") {
+		return map[string]any{RefKey: trim}
+	}
+	if strings.HasPrefix(trim, "{") {
+		var branch map[string]any
+		if err := json.Unmarshal([]byte(trim), &branch); err == nil {
+			return branch
+		}
+	}
+	return map[string]any{TypeKey: trim}
+}
+
 func applySchemaKeywordTag(schema map[string]any, key, val string) {
 	trim := strings.TrimSpace(val)
 
@@ -599,7 +1146,7 @@ func applySchemaKeywordTag(schema map[string]any, key, val string) {
 		if f, err := strconv.ParseFloat(trim, 64); err == nil {
 			schema[key] = f
 		}
-	case ExamplesKey, PatternPropertiesKey, DefsKey:
+	case PatternPropertiesKey, DefsKey:
 		if len(trim) > 0 && (trim[0] == '{' || trim[0] == '[') {
 			var anyVal any
 			if err := json.Unmarshal([]byte(trim), &anyVal); err == nil {
@@ -773,6 +1320,8 @@ func addArrayTags(field reflect.StructField, schema map[string]any) {
 	}{
 		{MinItemsKey, func(v int) { schema[MinItemsKey] = v }},
 		{MaxItemsKey, func(v int) { schema[MaxItemsKey] = v }},
+		{MinContainsKey, func(v int) { schema[MinContainsKey] = v }},
+		{MaxContainsKey, func(v int) { schema[MaxContainsKey] = v }},
 	} {
 		if val := field.Tag.Get(tag.key); val != "" {
 			if i, err := strconv.Atoi(val); err == nil {
@@ -784,6 +1333,61 @@ func addArrayTags(field reflect.StructField, schema map[string]any) {
 	if unique := field.Tag.Get(UniqueItemsKey); unique == "true" {
 		schema[UniqueItemsKey] = true
 	}
+
+	if val := field.Tag.Get(PrefixItemsKey); val != "" {
+		if prefixItems := parsePrefixItemsTag(val); len(prefixItems) > 0 {
+			schema[PrefixItemsKey] = prefixItems
+		}
+	}
+}
+
+// addMapTags applies map-specific tags to a schema. The propertyNames tag
+// is a regular expression every key must match; an expression that does not
+// compile is left out here and reported by GenerateSchemaStrict.
+func addMapTags(field reflect.StructField, schema map[string]any) {
+	ft := field.Type
+	for ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+	}
+	if ft.Kind() != reflect.Map {
+		return
+	}
+
+	if pattern := field.Tag.Get(PropertyNamesKey); pattern != "" {
+		if _, err := regexp.Compile(pattern); err == nil {
+			schema[PropertyNamesKey] = map[string]any{PatternKey: pattern}
+		}
+	}
+}
+
+// parsePrefixItemsTag parses a prefixItems tag. The tag is either a JSON
+// array of schemas (e.g. `[{"type":"string"},{"type":"integer"}]`) or a
+// comma-separated list of type names (e.g. `string,integer`) describing the
+// positional item types of a tuple.
+func parsePrefixItemsTag(val string) []any {
+	trim := strings.TrimSpace(val)
+	if strings.HasPrefix(trim, "[") {
+		var items []any
+		if err := json.Unmarshal([]byte(trim), &items); err == nil {
+			return items
+		}
+		return nil
+	}
+
+	parts := strings.Split(trim, ",")
+	items := make([]any, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil
+		}
+		if strings.HasPrefix(part, "#") {
+			items = append(items, map[string]any{RefKey: part})
+			continue
+		}
+		items = append(items, map[string]any{TypeKey: part})
+	}
+	return items
 }
 
 // jsonFieldName extracts the JSON field name from a struct field's JSON tag.
@@ -795,6 +1399,20 @@ func jsonFieldName(f reflect.StructField) string {
 	return tag
 }
 
+// hasJSONOption reports whether the field's JSON tag lists option after the
+// name, as in `json:",inline"`.
+func hasJSONOption(f reflect.StructField, option string) bool {
+	_, opts, _ := strings.Cut(f.Tag.Get(JSONTag), ",")
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if strings.TrimSpace(opt) == option {
+			return true
+		}
+	}
+	return false
+}
+
 // RegisterSchema registers a custom JSON Schema for a Go type. The registry is
 // process-wide. To restore the default built-in type set (e.g. in tests), call
 // ClearRegistry.
@@ -811,14 +1429,32 @@ func RegisterSchema(t reflect.Type, schema map[string]any) {
 	}
 }
 
+// EnumFor registers the allowed values of a Go type, typically a named
+// string or integer type used as an enumeration. The type's generated schema
+// (for example {"type":"string"}) gains "enum" set to values wherever the
+// type appears. Like RegisterSchema, the registration is process-wide and is
+// removed by ClearRegistry.
+func EnumFor(t reflect.Type, values []any) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	schema := NewBuilder().inlineSchema(t)
+	schema[EnumKey] = append([]any(nil), values...)
+	RegisterSchema(t, schema)
+}
+
 // ClearRegistry resets the type registry to the default built-in mappings and
-// removes any custom registrations made via RegisterSchema. Intended for tests
-// or process reset.
+// removes any custom registrations made via RegisterSchema, EnumFor,
+// RegisterValidator, and RegisterExternalRef. Intended for tests or process
+// reset.
 func ClearRegistry() {
 	registeredSchemasMu.Lock()
 	registeredSchemas = builtinSchemas()
 	registeredSchemasMu.Unlock()
 	clearCustomRegisteredTypes()
+	clearValidators()
+	clearExternalRefs()
+	clearNamedTypes()
 	clearSchemaCache()
 }
 
@@ -829,12 +1465,15 @@ func GenerateSchemaRawMessage(t reflect.Type) json.RawMessage {
 		return raw
 	}
 
-	schema := GenerateSchema(t)
+	builder := NewBuilder()
+	schema := builder.Schema(t)
 	raw, err := json.Marshal(schema)
 	if err != nil {
 		return nil
 	}
-	cacheSchemaRawMessage(t, raw)
+	if !builder.usesPolymorphicRegistry {
+		cacheSchemaRawMessage(t, raw)
+	}
 	return raw
 }
 
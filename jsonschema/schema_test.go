@@ -3,9 +3,11 @@ package jsonschema
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net"
 	"net/url"
 	"reflect"
+	"regexp/syntax"
 	"sync"
 	"testing"
 	"time"
@@ -48,6 +50,121 @@ func TestShouldIncludeRequiredFieldsGivenStructWithRequiredTags(t *testing.T) {
 	assertSchema(t, TestStruct{}, expected)
 }
 
+func TestShouldCombineRequiredAndNullableGivenPointerAndValueFields(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            any
+		expectedType     any
+		expectedRequired bool
+	}{
+		{
+			name: "pointer and required",
+			input: struct {
+				Field *string `json:"field" required:"true"`
+			}{},
+			expectedType:     []any{"string", "null"},
+			expectedRequired: true,
+		},
+		{
+			name: "pointer and not required",
+			input: struct {
+				Field *string `json:"field"`
+			}{},
+			expectedType:     []any{"string", "null"},
+			expectedRequired: false,
+		},
+		{
+			name: "value and required",
+			input: struct {
+				Field string `json:"field" required:"true"`
+			}{},
+			expectedType:     "string",
+			expectedRequired: true,
+		},
+		{
+			name: "pointer opted out of nullable",
+			input: struct {
+				Field *string `json:"field" required:"true" nullable:"false"`
+			}{},
+			expectedType:     "string",
+			expectedRequired: true,
+		},
+		{
+			name: "value opted into nullable",
+			input: struct {
+				Field int `json:"field" nullable:"true"`
+			}{},
+			expectedType:     []any{"integer", "null"},
+			expectedRequired: false,
+		},
+		{
+			name: "value marked optional",
+			input: struct {
+				Field string `json:"field" optional:"true"`
+			}{},
+			expectedType:     []any{"string", "null"},
+			expectedRequired: false,
+		},
+		{
+			name: "optional overrides required",
+			input: struct {
+				Field string `json:"field" required:"true" optional:"true"`
+			}{},
+			expectedType:     []any{"string", "null"},
+			expectedRequired: false,
+		},
+		{
+			name: "optional with nullable opted out",
+			input: struct {
+				Field string `json:"field" optional:"true" nullable:"false"`
+			}{},
+			expectedType:     "string",
+			expectedRequired: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			schema := GenerateSchema(reflect.TypeOf(tt.input))
+
+			// Assert
+			field := schema["properties"].(map[string]any)["field"].(map[string]any)
+			assert.Equal(t, tt.expectedType, field["type"])
+			if tt.expectedRequired {
+				assert.Equal(t, []string{"field"}, schema["required"])
+			} else {
+				assert.NotContains(t, schema, "required")
+			}
+		})
+	}
+}
+
+func TestShouldMixOptionalAndRequiredFieldsGivenOptionalTags(t *testing.T) {
+	// Arrange
+	type Profile struct {
+		ID       string `json:"id" required:"true"`
+		Email    string `json:"email" binding:"required"`
+		Nickname string `json:"nickname" optional:"true"`
+		Bio      string `json:"bio" required:"true" optional:"true"`
+		Age      int    `json:"age" validate:"required" optional:"true"`
+		Optional string `json:"flag" optional:"false"`
+	}
+
+	// Act
+	schema := NewBuilder(WithValidateTags()).Schema(reflect.TypeOf(Profile{}))
+
+	// Assert
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, []string{"id", "email"}, schema["required"])
+	assert.Equal(t, "string", props["id"].(map[string]any)["type"])
+	assert.Equal(t, []any{"string", "null"}, props["nickname"].(map[string]any)["type"])
+	assert.Equal(t, []any{"string", "null"}, props["bio"].(map[string]any)["type"])
+	assert.Equal(t, []any{"integer", "null"}, props["age"].(map[string]any)["type"])
+	assert.Equal(t, "string", props["flag"].(map[string]any)["type"])
+	require.NoError(t, Validate(schema, map[string]any{"id": "1", "email": "a@b.c", "nickname": nil}))
+}
+
 func TestShouldApplyNumericConstraintsGivenFieldsWithValidationTags(t *testing.T) {
 	type TestStruct struct {
 		Number int `json:"number" minimum:"0" maximum:"100" multipleOf:"2"`
@@ -340,6 +457,27 @@ func TestShouldReturnIndependentCachedSchemas(t *testing.T) {
 	assert.NotContains(t, second, "description")
 }
 
+func TestShouldReturnIndependentCopyGivenCachedSchemaGeneration(t *testing.T) {
+	// Arrange
+	type Cached struct {
+		Name string   `json:"name" required:"true"`
+		Tags []string `json:"tags"`
+	}
+	typ := reflect.TypeOf(Cached{})
+
+	// Act
+	first := GenerateSchemaCached(typ)
+	first["title"] = "mutated"
+	first["properties"].(map[string]any)["name"].(map[string]any)["type"] = "integer"
+	first["required"] = append(first["required"].([]string), "tags")
+	second := GenerateSchemaCached(typ)
+
+	// Assert
+	assert.NotContains(t, second, "title")
+	assert.Equal(t, "string", second["properties"].(map[string]any)["name"].(map[string]any)["type"])
+	assert.Equal(t, []string{"name"}, second["required"])
+}
+
 func TestShouldKeepTaggedTimeSchemasIsolatedAcrossFields(t *testing.T) {
 	type Example struct {
 		ExpiresAt time.Time  `json:"expires_at" description:"When the client credential expires"`
@@ -355,7 +493,7 @@ func TestShouldKeepTaggedTimeSchemasIsolatedAcrossFields(t *testing.T) {
 				"description": "When the client credential expires",
 			},
 			"revoked_at": map[string]any{
-				"type":        "string",
+				"type":        []any{"string", "null"},
 				"format":      "date-time",
 				"description": "When the client was revoked",
 			},
@@ -409,6 +547,42 @@ func TestShouldGenerateNullableSchemaGivenSQLNullTypes(t *testing.T) {
 				"type": []any{"integer", "null"},
 			},
 		},
+		{
+			name:  "sql_null_int32",
+			input: sql.NullInt32{},
+			expected: map[string]any{
+				"type": []any{"integer", "null"},
+			},
+		},
+		{
+			name:  "sql_null_int16",
+			input: sql.NullInt16{},
+			expected: map[string]any{
+				"type": []any{"integer", "null"},
+			},
+		},
+		{
+			name:  "sql_null_byte",
+			input: sql.NullByte{},
+			expected: map[string]any{
+				"type": []any{"integer", "null"},
+			},
+		},
+		{
+			name:  "sql_null_generic_string",
+			input: sql.Null[string]{},
+			expected: map[string]any{
+				"type": []any{"string", "null"},
+			},
+		},
+		{
+			name:  "sql_null_generic_time",
+			input: sql.Null[time.Time]{},
+			expected: map[string]any{
+				"type":   []any{"string", "null"},
+				"format": "date-time",
+			},
+		},
 		{
 			name:  "sql_null_bool",
 			input: sql.NullBool{},
@@ -440,6 +614,39 @@ func TestShouldGenerateNullableSchemaGivenSQLNullTypes(t *testing.T) {
 	}
 }
 
+func TestShouldGenerateNullableScalarPropertiesGivenSQLNullFields(t *testing.T) {
+	// Arrange
+	type Row struct {
+		Name    sql.NullString     `json:"name"`
+		Count   sql.NullInt64      `json:"count"`
+		Label   sql.Null[string]   `json:"label"`
+		Address sql.Null[PageUser] `json:"user"`
+		Score   *sql.Null[float64] `json:"score"`
+	}
+	typ := reflect.TypeOf(Row{})
+
+	// Act
+	schema := GenerateSchema(typ)
+	root, components := GenerateSchemaWithComponents(typ)
+
+	// Assert
+	props := schema[PropertiesKey].(map[string]any)
+	assert.Equal(t, map[string]any{"type": []any{"string", "null"}}, props["name"])
+	assert.Equal(t, map[string]any{"type": []any{"integer", "null"}}, props["count"])
+	assert.Equal(t, map[string]any{"type": []any{"string", "null"}}, props["label"])
+	assert.Equal(t, []any{"object", "null"}, props["user"].(map[string]any)["type"])
+	assert.Equal(t, map[string]any{"type": []any{"number", "null"}}, props["score"])
+	assert.NoError(t, Validate(schema, map[string]any{"name": nil, "count": 3.0, "label": "x"}))
+	assert.Error(t, Validate(schema, map[string]any{"count": "3"}))
+
+	rootProps := root[PropertiesKey].(map[string]any)
+	assert.Equal(t, map[string]any{"type": []any{"integer", "null"}}, rootProps["count"])
+	assert.Equal(t, []any{"object", "null"}, rootProps["user"].(map[string]any)["type"])
+	for name := range components {
+		assert.NotContains(t, name, "Null")
+	}
+}
+
 func TestShouldGenerateSchemaGivenPointerType(t *testing.T) {
 	// Arrange
 	type TestStruct struct {
@@ -476,6 +683,50 @@ func TestShouldApplyArrayTagsGivenArrayValidationTags(t *testing.T) {
 	})
 }
 
+func TestShouldApplyContainsCountTagsGivenArrayField(t *testing.T) {
+	// Arrange
+	type TestStruct struct {
+		Scores []int `json:"scores" contains:"{\"type\":\"integer\",\"minimum\":90}" minContains:"1" maxContains:"3"`
+	}
+
+	// Act & Assert
+	assertSchema(t, TestStruct{}, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"scores": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "integer"},
+				"contains":    map[string]any{"type": "integer", "minimum": 90.0},
+				"minContains": 1,
+				"maxContains": 3,
+			},
+		},
+	})
+}
+
+func TestShouldApplyPrefixItemsTagGivenTupleArrayField(t *testing.T) {
+	// Arrange
+	type TestStruct struct {
+		Point  []any `json:"point" prefixItems:"number,number,string"`
+		Record []any `json:"record" prefixItems:"[{\"type\":\"string\",\"format\":\"uuid\"},{\"type\":\"integer\"}]"`
+	}
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(TestStruct{}))
+
+	// Assert
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, []any{
+		map[string]any{"type": "number"},
+		map[string]any{"type": "number"},
+		map[string]any{"type": "string"},
+	}, props["point"].(map[string]any)["prefixItems"])
+	assert.Equal(t, []any{
+		map[string]any{"type": "string", "format": "uuid"},
+		map[string]any{"type": "integer"},
+	}, props["record"].(map[string]any)["prefixItems"])
+}
+
 func TestRawMessageDefaultIsEmptySchema(t *testing.T) {
 	type TestStruct struct {
 		Data json.RawMessage `json:"data"`
@@ -1502,6 +1753,67 @@ func TestShouldNotInlineAnonymousEmbeddedStructWhenNoInlineTag(t *testing.T) {
 	assertSchema(t, Sub{}, expected)
 }
 
+type InlineLabels map[string]string
+
+type InlineAudit struct {
+	CreatedBy    string `json:"createdBy" required:"true"`
+	InlineLabels `json:",inline"`
+}
+
+func TestShouldSetAdditionalPropertiesGivenInlinedMap(t *testing.T) {
+	type Resource struct {
+		InlineLabels `json:",inline"`
+
+		Name string `json:"name"`
+	}
+
+	expected := map[string]any{
+		"type":                 "object",
+		"properties":           map[string]any{"name": map[string]any{"type": "string"}},
+		"additionalProperties": map[string]any{"type": "string"},
+	}
+
+	assertSchema(t, Resource{}, expected)
+}
+
+func TestShouldFlattenInlinedStructsGivenPointerAndNestedInlineMap(t *testing.T) {
+	type Resource struct {
+		*InlineAudit `json:",inline"`
+
+		Name string `json:"name"`
+	}
+
+	expected := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"createdBy": map[string]any{"type": "string"},
+			"name":      map[string]any{"type": "string"},
+		},
+		"required":             []string{"createdBy"},
+		"additionalProperties": map[string]any{"type": "string"},
+	}
+
+	assertSchema(t, Resource{}, expected)
+	assert.NoError(t, Validate(GenerateSchema(reflect.TypeOf(Resource{})), map[string]any{
+		"createdBy": "ops", "name": "web", "team": "core",
+	}))
+}
+
+func TestShouldNotInlineGivenTagNameContainingInline(t *testing.T) {
+	type Resource struct {
+		InlineLabels `json:"inlineLabels"`
+	}
+
+	expected := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"inlineLabels": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+		},
+	}
+
+	assertSchema(t, Resource{}, expected)
+}
+
 // Tests for direct JSON Schema keyword struct tags
 func TestShouldApplyConstTag(t *testing.T) {
 	type TestStruct struct {
@@ -1519,6 +1831,24 @@ func TestShouldApplyConstTag(t *testing.T) {
 	assertSchema(t, TestStruct{}, expected)
 }
 
+func TestShouldApplyCommentTagWithoutAffectingValidation(t *testing.T) {
+	// Arrange
+	type TestStruct struct {
+		Secret string `json:"secret" comment:"internal only"`
+	}
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(TestStruct{}))
+	err := Validate(schema, map[string]any{"secret": "value"})
+
+	// Assert
+	assert.Equal(t, map[string]any{
+		"type":     "string",
+		"$comment": "internal only",
+	}, schema["properties"].(map[string]any)["secret"])
+	assert.NoError(t, err)
+}
+
 func TestShouldApplyExamplesTag(t *testing.T) {
 	type TestStruct struct {
 		Field string `json:"field" examples:"[\"a\",\"b\"]"`
@@ -1636,3 +1966,725 @@ func TestShouldApplyDefsSchemaIDTags(t *testing.T) {
 	}
 	assertSchema(t, TestStruct{}, expected)
 }
+
+type Money struct {
+	Units int64
+	Nanos int32
+}
+
+func (Money) JSONSchema() map[string]any {
+	return map[string]any{"type": "string", "pattern": `^-?\d+(\.\d{1,2})?$`}
+}
+
+type Percent float64
+
+func (*Percent) JSONSchema() map[string]any {
+	return map[string]any{"type": "number", "minimum": 0, "maximum": 100}
+}
+
+func TestShouldUseProvidedSchemaGivenSchemaProviderTypes(t *testing.T) {
+	// Arrange
+	type Invoice struct {
+		Total    Money    `json:"total"`
+		Discount *Percent `json:"discount"`
+	}
+
+	// Act
+	root, components := GenerateSchemaWithComponents(reflect.TypeOf(Invoice{}))
+	money := GenerateSchema(reflect.TypeOf(Money{}))
+
+	// Assert
+	assert.Equal(t, map[string]any{"type": "string", "pattern": `^-?\d+(\.\d{1,2})?$`}, money)
+	props := root["properties"].(map[string]any)
+	assert.Equal(t, money, props["total"])
+	assert.Equal(t, map[string]any{"type": []any{"number", "null"}, "minimum": 0, "maximum": 100}, props["discount"])
+	assert.NotContains(t, components, "Money")
+}
+
+func TestShouldApplyPropertyNamesTagGivenMapField(t *testing.T) {
+	// Arrange
+	type TestStruct struct {
+		Labels map[string]string `json:"labels" propertyNames:"^[a-z]+$"`
+	}
+	expected := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"labels": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+				"propertyNames":        map[string]any{"pattern": "^[a-z]+$"},
+			},
+		},
+	}
+
+	// Act & Assert
+	assertSchema(t, TestStruct{}, expected)
+}
+
+func TestShouldReturnPropertyNamesPatternErrorGivenInvalidPattern(t *testing.T) {
+	// Arrange
+	type Inner struct {
+		Labels map[string]string `json:"labels" propertyNames:"^[a-z+$"`
+	}
+	type TestStruct struct {
+		Inner Inner `json:"inner"`
+	}
+	typ := reflect.TypeOf(TestStruct{})
+
+	// Act
+	schema, err := GenerateSchemaStrict(typ)
+	generated, genErr := NewGenerator().Generate(typ)
+
+	// Assert
+	assert.Nil(t, schema)
+	assert.Nil(t, generated)
+	var patternErr *PropertyNamesPatternError
+	require.ErrorAs(t, err, &patternErr)
+	assert.Equal(t, "inner.labels", patternErr.Path)
+	assert.Equal(t, "^[a-z+$", patternErr.Pattern)
+	var syntaxErr *syntax.Error
+	require.ErrorAs(t, err, &syntaxErr)
+	assert.Equal(t, syntax.ErrMissingBracket, syntaxErr.Code)
+	assert.Contains(t, err.Error(), "field inner.labels has invalid propertyNames pattern")
+	require.ErrorAs(t, genErr, &patternErr)
+	assert.Equal(t, "inner.labels", patternErr.Path)
+}
+
+type Currency struct {
+	code string
+}
+
+func (c Currency) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.code)
+}
+
+type Temperature struct {
+	Celsius float64
+}
+
+func (t Temperature) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("%.1fC", t.Celsius))
+}
+
+func (Temperature) JSONSchema() map[string]any {
+	return map[string]any{"type": "string", "pattern": `^-?\d+\.\dC$`}
+}
+
+func TestShouldEmitOpenSchemaGivenJSONMarshalerType(t *testing.T) {
+	// Arrange
+	type Price struct {
+		Currency Currency    `json:"currency" description:"ISO 4217 code"`
+		Reading  Temperature `json:"reading"`
+	}
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(Price{}))
+
+	// Assert
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"$comment":    "custom JSON marshaling: jsonschema.Currency",
+		"description": "ISO 4217 code",
+	}, props["currency"])
+	assert.Equal(t, map[string]any{"type": "string", "pattern": `^-?\d+\.\dC$`}, props["reading"])
+	assert.NoError(t, Validate(schema, map[string]any{"currency": "USD", "reading": "21.5C"}))
+}
+
+func TestShouldAddHumanizedTitlesOnlyGivenFieldTitlesOption(t *testing.T) {
+	// Arrange
+	type Account struct {
+		FirstName    string `json:"firstName"`
+		HTTPServerID string `json:"serverId"`
+		Nickname     string `json:"nickname" title:"Display name"`
+	}
+	typ := reflect.TypeOf(Account{})
+
+	// Act
+	withTitles := NewBuilder(WithFieldTitles()).Schema(typ)
+	withoutTitles := NewBuilder().Schema(typ)
+
+	// Assert
+	props := withTitles["properties"].(map[string]any)
+	assert.Equal(t, "First Name", props["firstName"].(map[string]any)["title"])
+	assert.Equal(t, "HTTP Server ID", props["serverId"].(map[string]any)["title"])
+	assert.Equal(t, "Display name", props["nickname"].(map[string]any)["title"])
+
+	plain := withoutTitles["properties"].(map[string]any)
+	assert.NotContains(t, plain["firstName"], "title")
+	assert.NotContains(t, plain["serverId"], "title")
+	assert.Equal(t, "Display name", plain["nickname"].(map[string]any)["title"])
+	assert.NotContains(t, GenerateSchema(typ)["properties"].(map[string]any)["firstName"], "title")
+}
+
+func TestShouldLiftConditionalTagsToObjectGivenIfEqualsField(t *testing.T) {
+	// Arrange
+	type Payment struct {
+		Type       string `json:"type" ifEquals:"card" then:"{\"required\":[\"cardNumber\"]}" else:"#/components/schemas/CashDetails"`
+		CardNumber string `json:"cardNumber,omitempty"`
+		Amount     int    `json:"amount" ifEquals:"0" then:"null"`
+	}
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(Payment{}))
+
+	// Assert
+	assert.Equal(t, map[string]any{
+		"properties": map[string]any{"type": map[string]any{"const": "card"}},
+		"required":   []string{"type"},
+	}, schema["if"])
+	assert.Equal(t, map[string]any{"required": []any{"cardNumber"}}, schema["then"])
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/CashDetails"}, schema["else"])
+	assert.Equal(t, []any{map[string]any{
+		"if": map[string]any{
+			"properties": map[string]any{"amount": map[string]any{"const": float64(0)}},
+			"required":   []string{"amount"},
+		},
+		"then": map[string]any{"type": "null"},
+	}}, schema["allOf"])
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string"}, props["type"])
+}
+
+type ConditionalPaymentBase struct {
+	Type   string `json:"type" ifEquals:"card" then:"{\"required\":[\"cardNumber\"]}"`
+	Status string `json:"status" ifEquals:"void" then:"{\"required\":[\"reason\"]}"`
+}
+
+func TestShouldLiftConditionalTagsGivenInlineEmbeddedIfEqualsField(t *testing.T) {
+	// Arrange
+	type Payment struct {
+		*ConditionalPaymentBase `json:",inline"`
+		Status                  string `json:"status"`
+		CardNumber              string `json:"cardNumber,omitempty"`
+		Reason                  string `json:"reason,omitempty"`
+	}
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(Payment{}))
+
+	// Assert
+	assert.Equal(t, map[string]any{
+		"properties": map[string]any{"type": map[string]any{"const": "card"}},
+		"required":   []string{"type"},
+	}, schema["if"])
+	assert.NotContains(t, schema, "allOf", "the direct status field hides the promoted conditional")
+	assert.Error(t, Validate(schema, map[string]any{"type": "card"}))
+	assert.NoError(t, Validate(schema, map[string]any{"type": "card", "cardNumber": "4111"}))
+	assert.NoError(t, Validate(schema, map[string]any{"type": "cash", "status": "void"}))
+}
+
+func TestShouldRequireConditionalFieldsGivenValidationAgainstIfEqualsSchema(t *testing.T) {
+	// Arrange
+	type Payment struct {
+		Type       string `json:"type" ifEquals:"card" then:"{\"required\":[\"cardNumber\"]}"`
+		CardNumber string `json:"cardNumber,omitempty"`
+	}
+	schema := GenerateSchema(reflect.TypeOf(Payment{}))
+
+	// Act
+	cardErr := Validate(schema, map[string]any{"type": "card"})
+	cashErr := Validate(schema, map[string]any{"type": "cash"})
+
+	// Assert
+	require.Error(t, cardErr)
+	assert.Contains(t, cardErr.Error(), "cardNumber")
+	assert.NoError(t, cashErr)
+}
+
+type Level int
+
+func TestShouldApplyRegisteredEnumValuesGivenEnumForType(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	type Settings struct {
+		Level  Level   `json:"level"`
+		Levels []Level `json:"levels"`
+	}
+	EnumFor(reflect.TypeOf(Level(0)), []any{1, 2, 3})
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(Settings{}))
+
+	// Assert
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "integer", "enum": []any{1, 2, 3}}, props["level"])
+	assert.Equal(t, map[string]any{"type": "integer", "enum": []any{1, 2, 3}}, props["levels"].(map[string]any)["items"])
+	assert.NoError(t, Validate(schema, map[string]any{"level": 2.0, "levels": []any{1.0}}))
+	assert.Error(t, Validate(schema, map[string]any{"level": 4.0, "levels": []any{}}))
+}
+
+func TestShouldParseJSONArrayEnumTagWithTypesAndCommas(t *testing.T) {
+	// Arrange
+	type TestStruct struct {
+		Size  int    `json:"size" enum:"[1,2,3]"`
+		Label string `json:"label" enum:"[\"a,b\",\"c\"]"`
+		Mode  string `json:"mode" enum:"fast, slow"`
+	}
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(TestStruct{}))
+
+	// Assert
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, []any{float64(1), float64(2), float64(3)}, props["size"].(map[string]any)["enum"])
+	assert.Equal(t, []any{"a,b", "c"}, props["label"].(map[string]any)["enum"])
+	assert.Equal(t, []string{"fast", "slow"}, props["mode"].(map[string]any)["enum"])
+}
+
+func TestShouldSplitRequestAndResponseSchemasGivenReadOnlyAndWriteOnlyTags(t *testing.T) {
+	// Arrange
+	type Credentials struct {
+		Username string `json:"username" required:"true"`
+		Password string `json:"password" required:"true" writeOnly:"true"`
+	}
+	type Account struct {
+		ID          string      `json:"id" required:"true" readOnly:"true"`
+		Name        string      `json:"name" required:"true"`
+		Credentials Credentials `json:"credentials"`
+	}
+	typ := reflect.TypeOf(Account{})
+
+	// Act
+	request := GenerateRequestSchema(typ)
+	response := GenerateResponseSchema(typ)
+
+	// Assert
+	requestProps := request["properties"].(map[string]any)
+	assert.NotContains(t, requestProps, "id")
+	assert.Equal(t, []string{"name"}, request["required"])
+	assert.Contains(t, requestProps["credentials"].(map[string]any)["properties"], "password")
+
+	responseProps := response["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string", "readOnly": true}, responseProps["id"])
+	assert.Equal(t, []string{"id", "name"}, response["required"])
+	credentials := responseProps["credentials"].(map[string]any)
+	assert.NotContains(t, credentials["properties"], "password")
+	assert.Equal(t, []string{"username"}, credentials["required"])
+
+	assert.Contains(t, GenerateSchema(typ)["properties"], "id")
+}
+
+type requestAudit struct {
+	CreatedBy string `json:"createdBy" required:"true" readOnly:"true"`
+	Note      string `json:"note" required:"true"`
+}
+
+type requestDocument struct {
+	ID       string                  `json:"id" readOnly:"true"`
+	Audit    requestAudit            `json:"audit"`
+	Previous *requestAudit           `json:"previous"`
+	History  []requestAudit          `json:"history"`
+	ByUser   map[string]requestAudit `json:"byUser"`
+}
+
+func TestShouldRemoveNestedReadOnlyPropertiesGivenRequestSchema(t *testing.T) {
+	// Arrange
+	typ := reflect.TypeOf(requestDocument{})
+	want := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"note": map[string]any{"type": "string"}},
+		"required":   []string{"note"},
+	}
+
+	// Act
+	request := GenerateRequestSchema(typ)
+	response := GenerateResponseSchema(typ)
+
+	// Assert
+	props := request["properties"].(map[string]any)
+	assert.NotContains(t, props, "id")
+	assert.Equal(t, want, props["audit"])
+	assert.Equal(t, want["properties"], props["previous"].(map[string]any)["properties"])
+	assert.Equal(t, want, props["history"].(map[string]any)["items"])
+	assert.Equal(t, want, props["byUser"].(map[string]any)["additionalProperties"])
+	audit := response["properties"].(map[string]any)["audit"].(map[string]any)
+	assert.Contains(t, audit["properties"], "createdBy")
+	assert.Equal(t, []string{"createdBy", "note"}, audit["required"])
+}
+
+func TestShouldDropReadOnlyRequiredEntryGivenRegisteredNestedSchema(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	var registered map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {"createdBy": {"type": "string", "readOnly": true}, "note": {"type": "string"}},
+		"required": ["createdBy", "note"]
+	}`), &registered))
+	RegisterSchema(reflect.TypeOf(requestAudit{}), registered)
+
+	// Act
+	request := GenerateRequestSchema(reflect.TypeOf(requestDocument{}))
+
+	// Assert
+	audit := request["properties"].(map[string]any)["audit"].(map[string]any)
+	assert.NotContains(t, audit["properties"], "createdBy")
+	assert.Equal(t, []any{"note"}, audit["required"])
+	assert.Equal(t, []any{"createdBy", "note"}, registered["required"])
+}
+
+type projectedCustomer struct {
+	ID      string `json:"id" required:"true"`
+	Name    string `json:"name" required:"true"`
+	Email   string `json:"email"`
+	Address struct {
+		City string `json:"city" required:"true"`
+	} `json:"address"`
+}
+
+func TestShouldKeepOnlyIncludedFieldsGivenIncludeFieldsOption(t *testing.T) {
+	// Arrange
+	typ := reflect.TypeOf(projectedCustomer{})
+
+	// Act
+	schema := GenerateSchema(typ, WithIncludeFields("name", "address"))
+
+	// Assert
+	props := schema["properties"].(map[string]any)
+	assert.Len(t, props, 2)
+	assert.Contains(t, props, "name")
+	assert.Contains(t, props, "address")
+	assert.Equal(t, []string{"name"}, schema["required"])
+	assert.Equal(t, []string{"city"}, props["address"].(map[string]any)["required"])
+	assert.Len(t, GenerateSchema(typ)["properties"], 4, "projection must not leak into the shared cache")
+}
+
+func TestShouldDropExcludedFieldsGivenExcludeFieldsOption(t *testing.T) {
+	// Arrange
+	typ := reflect.TypeOf(projectedCustomer{})
+
+	// Act
+	schema := GenerateSchema(typ, WithExcludeFields("id", "email"))
+	root, _ := NewBuilder(WithExcludeFields("id")).SchemaWithComponents(typ)
+
+	// Assert
+	props := schema["properties"].(map[string]any)
+	assert.NotContains(t, props, "id")
+	assert.NotContains(t, props, "email")
+	assert.Contains(t, props, "name")
+	assert.Equal(t, []string{"name"}, schema["required"])
+	assert.NotContains(t, root["properties"], "id")
+	assert.Equal(t, []string{"name"}, root["required"])
+}
+
+func TestShouldEchoTypedDefaultIntoExamplesGivenDefaultExamplesOption(t *testing.T) {
+	// Arrange
+	type Settings struct {
+		Retries  int      `json:"retries" default:"3"`
+		Ratio    *float64 `json:"ratio" default:"0.5"`
+		Scale    float32  `json:"scale" default:"0.1"`
+		Enabled  bool     `json:"enabled" default:"true"`
+		Region   string   `json:"region" default:"eu-west-1"`
+		Tags     []string `json:"tags" default:"[\"a\",\"b\"]"`
+		Timeout  int      `json:"timeout" default:"30" examples:"[10,60]"`
+		Untagged int      `json:"untagged"`
+	}
+	typ := reflect.TypeOf(Settings{})
+
+	// Act
+	schema := NewBuilder(WithDefaultExamples()).Schema(typ)
+
+	// Assert
+	props := schema["properties"].(map[string]any)
+	retries := props["retries"].(map[string]any)
+	assert.Equal(t, int64(3), retries["default"])
+	assert.Equal(t, []any{int64(3)}, retries["examples"])
+	assert.Equal(t, []any{0.5}, props["ratio"].(map[string]any)["examples"])
+	assert.Equal(t, []any{0.1}, props["scale"].(map[string]any)["examples"])
+	assert.Equal(t, []any{true}, props["enabled"].(map[string]any)["examples"])
+	assert.Equal(t, []any{"eu-west-1"}, props["region"].(map[string]any)["examples"])
+	assert.Equal(t, []any{[]any{"a", "b"}}, props["tags"].(map[string]any)["examples"])
+	assert.Equal(t, []any{float64(10), float64(60)}, props["timeout"].(map[string]any)["examples"])
+	assert.NotContains(t, props["untagged"], "examples")
+	assert.NotContains(t, GenerateSchema(typ)["properties"].(map[string]any)["retries"], "examples")
+}
+
+type GenericPage[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+type GenericPair[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+type PageUser struct {
+	Name string   `json:"name" required:"true"`
+	Tags []string `json:"tags"`
+}
+
+type UserDirectory struct {
+	Page    GenericPage[PageUser]              `json:"page"`
+	Lookups []GenericPair[string, []*PageUser] `json:"lookups"`
+}
+
+func TestShouldNameComponentsGivenGenericTypeInstantiations(t *testing.T) {
+	// Arrange
+	typ := reflect.TypeOf(UserDirectory{})
+
+	// Act
+	root, components := NewBuilder().SchemaWithComponents(typ)
+
+	// Assert
+	props := root["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/GenericPage_PageUser"}, props["page"])
+	assert.Equal(t, "#/components/schemas/GenericPair_string_List_PageUser",
+		props["lookups"].(map[string]any)["items"].(map[string]any)["$ref"])
+
+	require.Contains(t, components, "GenericPage_PageUser")
+	require.Contains(t, components, "GenericPair_string_List_PageUser")
+	page := components["GenericPage_PageUser"].(map[string]any)
+	items := page["properties"].(map[string]any)["items"].(map[string]any)
+	assert.Equal(t, "array", items["type"])
+	assert.Equal(t, "#/components/schemas/PageUser", items["items"].(map[string]any)["$ref"])
+	assert.Contains(t, components, "PageUser")
+	for name := range components {
+		assert.Regexp(t, `^[A-Za-z0-9_.-]+$`, name)
+	}
+}
+
+func TestShouldInlineInstantiatedFieldTypesGivenGenericStruct(t *testing.T) {
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(GenericPage[PageUser]{}))
+
+	// Assert
+	items := schema["properties"].(map[string]any)["items"].(map[string]any)
+	user := items["items"].(map[string]any)
+	assert.Equal(t, "object", user["type"])
+	assert.Equal(t, []string{"name"}, user["required"])
+}
+
+type TreeNode struct {
+	Name     string     `json:"name" required:"true"`
+	Children []TreeNode `json:"children"`
+	Parent   *TreeNode  `json:"parent"`
+}
+
+type Catalog struct {
+	Title string   `json:"title"`
+	Root  TreeNode `json:"root"`
+}
+
+func TestShouldAnchorRootGivenSelfRecursiveType(t *testing.T) {
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(TreeNode{}))
+
+	// Assert
+	assert.Equal(t, "TreeNode", schema["$anchor"])
+	assert.NotContains(t, schema, "$defs")
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"$ref": "#TreeNode"}, props["children"].(map[string]any)["items"])
+	assert.Equal(t, map[string]any{"anyOf": []any{
+		map[string]any{"$ref": "#TreeNode"},
+		map[string]any{"type": "null"},
+	}}, props["parent"])
+
+	valid := map[string]any{"name": "root", "parent": nil, "children": []any{map[string]any{"name": "leaf"}}}
+	assert.NoError(t, Validate(schema, valid))
+	invalid := map[string]any{"name": "root", "children": []any{map[string]any{"parent": nil}}}
+	assert.Error(t, Validate(schema, invalid))
+}
+
+func TestShouldHoistRecursiveTypeIntoDefsGivenNestedUse(t *testing.T) {
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(Catalog{}))
+
+	// Assert
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"$ref": "#TreeNode"}, props["root"])
+	defs := schema["$defs"].(map[string]any)
+	node := defs["TreeNode"].(map[string]any)
+	assert.Equal(t, "TreeNode", node["$anchor"])
+	nodeProps := node["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"$ref": "#TreeNode"}, nodeProps["children"].(map[string]any)["items"])
+
+	doc := map[string]any{"title": "c", "root": map[string]any{"name": "a", "children": []any{map[string]any{"name": "b"}}}}
+	assert.NoError(t, Validate(schema, doc))
+	bad := map[string]any{"root": map[string]any{"name": "a", "children": []any{map[string]any{"name": 1.0}}}}
+	assert.Error(t, Validate(schema, bad))
+}
+
+type cacheOrderLine struct {
+	SKU string `json:"sku"`
+}
+
+type cacheOrder struct {
+	Line cacheOrderLine `json:"line"`
+}
+
+func TestShouldInlineNestedTypesGivenSchemaGeneratedAfterComponents(t *testing.T) {
+	// Arrange
+	typ := reflect.TypeOf(cacheOrder{})
+	_, _ = GenerateSchemaWithComponents(typ)
+
+	// Act
+	schema := GenerateSchema(typ)
+
+	// Assert
+	line := schema[PropertiesKey].(map[string]any)["line"].(map[string]any)
+	assert.NotContains(t, line, RefKey)
+	assert.Equal(t, TypeObject, line[TypeKey])
+}
+
+func TestShouldTypeDefaultTagGivenFieldSchemaType(t *testing.T) {
+	// Arrange
+	type Limits struct {
+		Retries  int               `json:"retries" default:"5"`
+		Enabled  bool              `json:"enabled" default:"true"`
+		Ratio    float64           `json:"ratio" default:"0.25"`
+		Optional *uint16           `json:"optional" default:"7"`
+		Region   string            `json:"region" default:"42"`
+		Labels   map[string]string `json:"labels" default:"{\"env\":\"dev\"}"`
+		Invalid  int               `json:"invalid" default:"many"`
+	}
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(Limits{}))
+
+	// Assert
+	props := schema[PropertiesKey].(map[string]any)
+	tests := []struct {
+		field string
+		want  any
+	}{
+		{"retries", int64(5)},
+		{"enabled", true},
+		{"ratio", 0.25},
+		{"optional", int64(7)},
+		{"region", "42"},
+		{"labels", map[string]any{"env": "dev"}},
+		{"invalid", "many"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			assert.Equal(t, tt.want, props[tt.field].(map[string]any)[DefaultKey])
+		})
+	}
+	data, err := json.Marshal(props["retries"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"integer","default":5}`, string(data))
+}
+
+type textDuration time.Duration
+
+func (d textDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+type countDuration int64
+
+func (d countDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int64(d))
+}
+
+func TestShouldDescribeDurationGivenRepresentation(t *testing.T) {
+	type Job struct {
+		Timeout  time.Duration  `json:"timeout"`
+		Interval *time.Duration `json:"interval"`
+		Backoff  textDuration   `json:"backoff"`
+		Delay    countDuration  `json:"delay"`
+	}
+	tests := []struct {
+		name     string
+		opts     []BuilderOption
+		timeout  map[string]any
+		interval map[string]any
+	}{
+		{
+			name:     "integer by default",
+			timeout:  map[string]any{TypeKey: TypeInteger, FormatKey: "int64"},
+			interval: map[string]any{TypeKey: []any{TypeInteger, "null"}, FormatKey: "int64"},
+		},
+		{
+			name:     "string with option",
+			opts:     []BuilderOption{WithDurationAsString()},
+			timeout:  map[string]any{TypeKey: TypeString, FormatKey: "duration"},
+			interval: map[string]any{TypeKey: []any{TypeString, "null"}, FormatKey: "duration"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			schema := NewBuilder(tt.opts...).Schema(reflect.TypeOf(Job{}))
+
+			// Assert
+			props := schema[PropertiesKey].(map[string]any)
+			assert.Equal(t, tt.timeout, props["timeout"])
+			assert.Equal(t, tt.interval, props["interval"])
+			assert.Equal(t, map[string]any{TypeKey: TypeString, FormatKey: "duration"}, props["backoff"])
+			assert.Equal(t, map[string]any{CommentKey: "custom JSON marshaling: jsonschema.countDuration"}, props["delay"])
+		})
+	}
+}
+
+func TestShouldPreferRegisteredDurationSchemaGivenDurationOption(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	custom := map[string]any{TypeKey: TypeString, PatternKey: "^[0-9]+(ms|s|m|h)$"}
+	RegisterSchema(reflect.TypeOf(time.Duration(0)), custom)
+
+	// Act
+	schema := NewBuilder(WithDurationAsString()).Schema(reflect.TypeOf(time.Duration(0)))
+
+	// Assert
+	assert.Equal(t, custom, schema)
+}
+
+func TestShouldDescribeStringEncodedNumberGivenStringOption(t *testing.T) {
+	// Arrange
+	type Reading struct {
+		N       int      `json:"n,string" minimum:"0"`
+		Ratio   *float64 `json:"ratio,string" maximum:"1" default:"0.5"`
+		Enabled bool     `json:"enabled,string"`
+		Label   string   `json:"label,string"`
+	}
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(Reading{}))
+
+	// Assert
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"type":             "string",
+		"pattern":          `^-?[0-9]+$`,
+		"contentMediaType": "application/json",
+		"contentSchema":    map[string]any{"type": "integer", "minimum": float64(0)},
+	}, props["n"])
+	ratio := props["ratio"].(map[string]any)
+	assert.Equal(t, []any{"string", "null"}, ratio["type"])
+	assert.Equal(t, "0.5", ratio["default"])
+	assert.Equal(t, map[string]any{"type": "number", "maximum": float64(1)}, ratio["contentSchema"])
+	assert.Equal(t, `^(true|false)$`, props["enabled"].(map[string]any)["pattern"])
+	assert.Equal(t, map[string]any{"type": "string"}, props["label"])
+}
+
+func TestShouldValidateStringEncodedNumberGivenContentSchema(t *testing.T) {
+	// Arrange
+	type Reading struct {
+		N int `json:"n,string" minimum:"0"`
+	}
+	schema := GenerateSchema(reflect.TypeOf(Reading{}))
+	encoded, err := json.Marshal(Reading{N: 7})
+	require.NoError(t, err)
+	var valid map[string]any
+	require.NoError(t, json.Unmarshal(encoded, &valid))
+
+	// Act
+	validErr := Validate(schema, valid)
+	negativeErr := Validate(schema, map[string]any{"n": "-3"})
+	numberErr := Validate(schema, map[string]any{"n": 7.0})
+	garbageErr := Validate(schema, map[string]any{"n": "seven"})
+
+	// Assert
+	assert.Equal(t, map[string]any{"n": "7"}, valid)
+	assert.NoError(t, validErr)
+	require.Error(t, negativeErr)
+	assert.Contains(t, negativeErr.Error(), "minimum")
+	assert.Error(t, numberErr)
+	require.Error(t, garbageErr)
+	assert.Contains(t, garbageErr.Error(), "not valid JSON")
+}
@@ -0,0 +1,144 @@
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+// UnsupportedKindError reports a field whose Go kind has no JSON
+// representation (channels, functions, complex numbers, unsafe pointers).
+// Path is the field's location using JSON names, with "[]" for slice or
+// array elements and "{}" for map values, e.g. "jobs[].callback".
+type UnsupportedKindError struct {
+	Path string
+	Kind reflect.Kind
+}
+
+func (e *UnsupportedKindError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("jsonschema: type has unsupported kind %s", e.Kind)
+	}
+	return fmt.Sprintf("jsonschema: field %s has unsupported kind %s", e.Path, e.Kind)
+}
+
+// PropertyNamesPatternError reports a map field whose propertyNames tag is
+// not a valid regular expression. Path is the field's location as in
+// UnsupportedKindError and Err is the error from compiling Pattern.
+type PropertyNamesPatternError struct {
+	Path    string
+	Pattern string
+	Err     error
+}
+
+func (e *PropertyNamesPatternError) Error() string {
+	return fmt.Sprintf("jsonschema: field %s has invalid propertyNames pattern %q: %v", e.Path, e.Pattern, e.Err)
+}
+
+func (e *PropertyNamesPatternError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownTypeNameError reports a name in a field's oneOfTypes tag that is not
+// registered with RegisterTypeName. Path is the field's location as in
+// UnsupportedKindError.
+type UnknownTypeNameError struct {
+	Path string
+	Name string
+}
+
+func (e *UnknownTypeNameError) Error() string {
+	return fmt.Sprintf("jsonschema: field %s lists oneOfTypes name %q not registered with RegisterTypeName", e.Path, e.Name)
+}
+
+// GenerateSchemaStrict behaves like GenerateSchema but fails instead of
+// emitting a placeholder {"type":"string"} schema when the type contains
+// fields that cannot be represented in JSON, instead of dropping a
+// propertyNames tag that does not compile, and instead of leaving out
+// oneOfTypes names that are not registered. The returned error joins one
+// *UnsupportedKindError, *PropertyNamesPatternError, or
+// *UnknownTypeNameError per offending field or name.
+// Fields excluded from JSON
+// (unexported or tagged json:"-"), fields with an explicit $ref tag, and
+// types with a registered or provided schema are not inspected.
+func GenerateSchemaStrict(t reflect.Type) (map[string]any, error) {
+	if err := checkSchema(t); err != nil {
+		return nil, err
+	}
+	return GenerateSchema(t), nil
+}
+
+// checkSchema returns the errors checkSchemaKinds finds in t, joined.
+func checkSchema(t reflect.Type) error {
+	if t == nil {
+		return errors.New("jsonschema: reflect.Type must not be nil")
+	}
+	var errs []error
+	checkSchemaKinds(t, "", map[reflect.Type]bool{}, &errs)
+	return errors.Join(errs...)
+}
+
+// checkSchemaKinds walks t the way the Builder does and records every
+// reachable field of an unsupported kind or with an invalid tag.
+func checkSchemaKinds(t reflect.Type, path string, visited map[reflect.Type]bool, errs *[]error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if _, ok := getRegisteredSchema(t); ok {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if visited[t] {
+			return
+		}
+		visited[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := jsonFieldName(field)
+			if field.PkgPath != "" || name == "-" || field.Tag.Get(RefKey) != "" {
+				continue
+			}
+			fieldPath := name
+			if field.Anonymous && hasJSONOption(field, "inline") {
+				fieldPath = path
+			} else if path != "" {
+				fieldPath = path + "." + name
+			}
+			checkFieldTags(field, fieldPath, errs)
+			checkSchemaKinds(field.Type, fieldPath, visited, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		checkSchemaKinds(t.Elem(), path+"[]", visited, errs)
+	case reflect.Map:
+		checkSchemaKinds(t.Elem(), path+"{}", visited, errs)
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		*errs = append(*errs, &UnsupportedKindError{Path: path, Kind: t.Kind()})
+	case reflect.Invalid, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer, reflect.String:
+	}
+}
+
+// checkFieldTags records the tags of field that the Builder drops because
+// their value is invalid.
+func checkFieldTags(field reflect.StructField, path string, errs *[]error) {
+	ft := field.Type
+	for ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+	}
+	if pattern := field.Tag.Get(PropertyNamesKey); pattern != "" && ft.Kind() == reflect.Map {
+		if _, err := regexp.Compile(pattern); err != nil {
+			*errs = append(*errs, &PropertyNamesPatternError{Path: path, Pattern: pattern, Err: err})
+		}
+	}
+	if tag := field.Tag.Get(OneOfTypesTag); tag != "" && oneOfTypesWrap(field.Type) != nil {
+		for _, name := range oneOfTypesNames(tag) {
+			if _, ok := lookupTypeName(name); !ok {
+				*errs = append(*errs, &UnknownTypeNameError{Path: path, Name: name})
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldReturnErrorNamingFieldPathGivenFuncField(t *testing.T) {
+	// Arrange
+	type Job struct {
+		Name     string       `json:"name"`
+		Callback func() error `json:"callback"`
+	}
+	type Queue struct {
+		Jobs    []Job               `json:"jobs"`
+		Signals map[string]chan int `json:"signals"`
+		OnDone  func()              `json:"-"`
+		hook    func()
+	}
+
+	// Act
+	schema, err := GenerateSchemaStrict(reflect.TypeOf(Queue{}))
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, schema)
+	assert.EqualError(t, err, "jsonschema: field jobs[].callback has unsupported kind func\n"+
+		"jsonschema: field signals{} has unsupported kind chan")
+	var kindErr *UnsupportedKindError
+	require.ErrorAs(t, err, &kindErr)
+	assert.Equal(t, "jobs[].callback", kindErr.Path)
+	assert.Equal(t, reflect.Func, kindErr.Kind)
+}
+
+func TestShouldMatchGenerateSchemaGivenSupportedType(t *testing.T) {
+	// Arrange
+	type Leaf struct {
+		Label string `json:"label"`
+	}
+	type Node struct {
+		Value    complex128 `json:"value" $ref:"#/components/schemas/Complex"`
+		Children []*Leaf    `json:"children"`
+		Meta     any        `json:"meta"`
+	}
+
+	// Act
+	schema, err := GenerateSchemaStrict(reflect.TypeOf(Node{}))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, GenerateSchema(reflect.TypeOf(Node{})), schema)
+}
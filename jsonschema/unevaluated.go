@@ -0,0 +1,118 @@
+package jsonschema
+
+import (
+	"slices"
+)
+
+// maxEvaluationDepth bounds how many subschemas evaluatedProperties follows,
+// so a $ref cycle that consumes no data cannot recurse forever.
+const maxEvaluationDepth = 64
+
+// validateUnevaluatedProperties applies unevaluatedProperties to the members
+// of an object that neither schema nor any subschema that applies to the
+// object (through $ref, allOf, a matching anyOf/oneOf branch, or the taken
+// if/then/else branch) evaluated via properties, patternProperties,
+// additionalProperties, or unevaluatedProperties.
+func validateUnevaluatedProperties(root map[string]any, path *validationPath, schema map[string]any, data any, errs *[]ValidationError) {
+	unevaluated, ok := schema[UnevaluatedPropertiesKey]
+	if !ok {
+		return
+	}
+	obj, ok := data.(map[string]any)
+	if !ok {
+		return
+	}
+
+	evaluated := make(map[string]bool, len(obj))
+	evaluatedProperties(root, schema, obj, evaluated, true, 0)
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		if !evaluated[key] {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		path.push(escapeJSONPointer(key))
+		switch typed := unevaluated.(type) {
+		case bool:
+			if !typed {
+				addErr(errs, path, "unevaluated property not allowed")
+			}
+		case map[string]any:
+			validateAt(root, path, typed, obj[key], errs)
+		}
+		path.pop()
+	}
+}
+
+// evaluatedProperties marks in evaluated the members of obj that schema
+// evaluates. isRoot skips schema's own unevaluatedProperties, which is the
+// keyword being applied.
+func evaluatedProperties(root, schema map[string]any, obj map[string]any, evaluated map[string]bool, isRoot bool, depth int) {
+	if schema == nil || depth > maxEvaluationDepth {
+		return
+	}
+	_, hasAdditional := schema[AdditionalPropertiesKey]
+	_, hasUnevaluated := schema[UnevaluatedPropertiesKey]
+	if hasAdditional || (hasUnevaluated && !isRoot) {
+		for key := range obj {
+			evaluated[key] = true
+		}
+		return
+	}
+
+	if props, ok := schema[PropertiesKey].(map[string]any); ok {
+		for key := range props {
+			if _, present := obj[key]; present {
+				evaluated[key] = true
+			}
+		}
+	}
+	var ignored []ValidationError
+	var path validationPath
+	for _, pattern := range compilePatternProperties(&path, schema, &ignored) {
+		for key := range obj {
+			if pattern.re.MatchString(key) {
+				evaluated[key] = true
+			}
+		}
+	}
+
+	if ref, ok := schema[RefKey].(string); ok {
+		if resolved, err := resolveRef(root, ref); err == nil {
+			evaluatedProperties(root, resolved, obj, evaluated, false, depth+1)
+		}
+	}
+	if allOf, ok := schema[AllOfKey].([]any); ok {
+		for _, s := range allOf {
+			sub, _ := s.(map[string]any)
+			evaluatedProperties(root, sub, obj, evaluated, false, depth+1)
+		}
+	}
+	for _, key := range []string{AnyOfKey, OneOfKey} {
+		branches, _ := schema[key].([]any)
+		for _, s := range branches {
+			if sub, _ := s.(map[string]any); sub != nil && schemaMatches(root, sub, obj) {
+				evaluatedProperties(root, sub, obj, evaluated, false, depth+1)
+			}
+		}
+	}
+	if ifSchema, ok := schema[IfKey].(map[string]any); ok {
+		branch := ElseKey
+		if schemaMatches(root, ifSchema, obj) {
+			evaluatedProperties(root, ifSchema, obj, evaluated, false, depth+1)
+			branch = ThenKey
+		}
+		sub, _ := schema[branch].(map[string]any)
+		evaluatedProperties(root, sub, obj, evaluated, false, depth+1)
+	}
+}
+
+func schemaMatches(root, schema map[string]any, data any) bool {
+	var errs []ValidationError
+	var path validationPath
+	validateAt(root, &path, schema, data, &errs)
+	return len(errs) == 0
+}
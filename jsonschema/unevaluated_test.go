@@ -0,0 +1,105 @@
+package jsonschema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldSetUnevaluatedPropertiesGivenRootOptionOnComposedSchema(t *testing.T) {
+	// Arrange
+	type Payment struct {
+		Type   string `json:"type" ifEquals:"card" then:"{\"properties\":{\"cardNumber\":{\"type\":\"string\"}}}"`
+		Amount int    `json:"amount" ifEquals:"0" then:"{\"properties\":{\"reason\":{\"type\":\"string\"}}}"`
+	}
+
+	// Act
+	schema := NewBuilder(WithUnevaluatedProperties(false)).Schema(reflect.TypeOf(Payment{}))
+
+	// Assert
+	assert.Equal(t, false, schema["unevaluatedProperties"])
+	assert.Contains(t, schema, "allOf")
+	assert.NotContains(t, GenerateSchema(reflect.TypeOf(Payment{})), "unevaluatedProperties")
+}
+
+func TestShouldApplyUnevaluatedPropertiesTagGivenFieldTag(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  string
+		want any
+	}{
+		{name: "bool", tag: "false", want: false},
+		{name: "ref", tag: "#/components/schemas/Extra", want: map[string]any{"$ref": "#/components/schemas/Extra"}},
+		{name: "schema", tag: `{"type":"integer"}`, want: map[string]any{"type": "integer"}},
+		{name: "type", tag: "string", want: map[string]any{"type": "string"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			typ := reflect.StructOf([]reflect.StructField{{
+				Name: "Meta",
+				Type: reflect.TypeOf(map[string]any{}),
+				Tag:  reflect.StructTag(`json:"meta" unevaluatedProperties:"` + strings.ReplaceAll(tt.tag, `"`, `\"`) + `"`),
+			}})
+
+			// Act
+			schema := GenerateSchema(typ)
+
+			// Assert
+			meta := schema["properties"].(map[string]any)["meta"].(map[string]any)
+			assert.Equal(t, tt.want, meta["unevaluatedProperties"])
+		})
+	}
+}
+
+func TestShouldRejectUnevaluatedMembersGivenValidationAgainstComposedSchema(t *testing.T) {
+	// Arrange
+	schema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"kind": map[string]any{"type": "string"}},
+		"allOf": []any{
+			map[string]any{"properties": map[string]any{"name": map[string]any{"type": "string"}}},
+		},
+		"anyOf": []any{
+			map[string]any{"properties": map[string]any{"size": map[string]any{"type": "integer"}}, "required": []any{"size"}},
+			map[string]any{"properties": map[string]any{"color": map[string]any{"type": "string"}}, "required": []any{"color"}},
+		},
+		"if":                    map[string]any{"properties": map[string]any{"kind": map[string]any{"const": "card"}}},
+		"then":                  map[string]any{"patternProperties": map[string]any{"^card": map[string]any{}}},
+		"unevaluatedProperties": false,
+	}
+
+	// Act
+	validErr := Validate(schema, map[string]any{"kind": "card", "name": "x", "size": 1.0, "cardNumber": "1"})
+	extraErr := Validate(schema, map[string]any{"kind": "cash", "name": "x", "size": 1.0, "cardNumber": "1", "color": "red", "extra": true})
+
+	// Assert
+	require.NoError(t, validErr)
+	require.Error(t, extraErr)
+	var verr *ErrValidation
+	require.ErrorAs(t, extraErr, &verr)
+	assert.Len(t, verr.Errs, 2)
+	assert.Equal(t, "/cardNumber", verr.Errs[0].Path)
+	assert.Equal(t, "/extra", verr.Errs[1].Path)
+}
+
+func TestShouldValidateUnevaluatedMembersGivenSchemaValue(t *testing.T) {
+	// Arrange
+	schema := map[string]any{
+		"$defs":                 map[string]any{"Base": map[string]any{"properties": map[string]any{"id": map[string]any{"type": "string"}}}},
+		"$ref":                  "#/$defs/Base",
+		"unevaluatedProperties": map[string]any{"type": "integer"},
+	}
+
+	// Act
+	validErr := Validate(schema, map[string]any{"id": "a", "count": 3.0})
+	invalidErr := Validate(schema, map[string]any{"id": "a", "label": "x"})
+
+	// Assert
+	assert.NoError(t, validErr)
+	require.Error(t, invalidErr)
+	assert.Contains(t, invalidErr.Error(), "/label")
+}
@@ -0,0 +1,191 @@
+package jsonschema
+
+import (
+	"reflect"
+
+	"github.com/fgrzl/json/polymorphic"
+)
+
+// GenerateTSUnionSchema returns a JSON Schema for the inline format written
+// by polymorphic.MarshalPolymorphicJSONInline, covering every registered
+// polymorphic type. The result is a oneOf with one object schema per
+// discriminator, in sorted order; each variant is titled with its
+// discriminator and requires a "type" member whose const is the
+// discriminator, which TypeScript generators map to a discriminated union.
+// Types whose factory does not produce a struct are skipped, as they cannot
+// be inlined.
+func GenerateTSUnionSchema() map[string]any {
+	variants := []any{}
+	for _, impl := range registeredImplementations(nil) {
+		variants = append(variants, unionVariant(impl.discriminator, GenerateSchema(impl.structType)))
+	}
+	return map[string]any{OneOfKey: variants}
+}
+
+// GenerateRegistrySchema returns one schema document describing every type
+// registered with the polymorphic package in the envelope format written by
+// polymorphic.MarshalPolymorphicJSON. Each registered type's content schema
+// is stored in "$defs" under its Go type name, together with the named types
+// it uses. The root is a oneOf with one envelope variant per discriminator,
+// in sorted order: an object titled with the discriminator whose "$type"
+// member is const, whose optional "meta" is an object, and whose "content"
+// references the type's definition. A
+// "discriminator" object with propertyName "$type" maps each discriminator to
+// its definition name.
+func GenerateRegistrySchema() map[string]any {
+	generator := NewGenerator(WithDefs())
+	defs := map[string]any{}
+	variants := []any{}
+	mapping := map[string]any{}
+	for _, discriminator := range polymorphic.Discriminators() {
+		factory, err := polymorphic.LoadFactory(discriminator)
+		if err != nil {
+			continue
+		}
+		t := reflect.TypeOf(factory())
+		if t == nil {
+			continue
+		}
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		content := generator.generate(t)
+		delete(content, IDKey)
+		if nested, ok := content[DefsKey].(map[string]any); ok {
+			delete(content, DefsKey)
+			for name, schema := range nested {
+				defs[name] = schema
+			}
+		}
+		if name := componentName(t); name != "" {
+			defs[name] = content
+			mapping[discriminator] = name
+			content = map[string]any{RefKey: "#/$defs/" + name}
+		}
+		variants = append(variants, map[string]any{
+			TypeKey:  TypeObject,
+			TitleKey: discriminator,
+			PropertiesKey: map[string]any{
+				"$type":    map[string]any{TypeKey: TypeString, ConstKey: discriminator},
+				"$version": map[string]any{TypeKey: TypeInteger},
+				"meta":     map[string]any{TypeKey: TypeObject},
+				"content":  content,
+			},
+			RequiredKey: []string{"$type", "content"},
+		})
+	}
+
+	schema := map[string]any{
+		OneOfKey: variants,
+		DiscriminatorKey: map[string]any{
+			"propertyName": "$type",
+			"mapping":      mapping,
+		},
+	}
+	if len(defs) > 0 {
+		schema[DefsKey] = defs
+	}
+	return schema
+}
+
+// polymorphicImplementation is a registered polymorphic struct type.
+type polymorphicImplementation struct {
+	discriminator string
+	structType    reflect.Type
+}
+
+// registeredImplementations returns the registered polymorphic types whose
+// factory produces a struct (or pointer to one), in discriminator order.
+// When iface is non-nil only types whose factory value implements it are
+// returned.
+func registeredImplementations(iface reflect.Type) []polymorphicImplementation {
+	var impls []polymorphicImplementation
+	for _, discriminator := range polymorphic.Discriminators() {
+		factory, err := polymorphic.LoadFactory(discriminator)
+		if err != nil {
+			continue
+		}
+		t := reflect.TypeOf(factory())
+		if t == nil || (iface != nil && !t.Implements(iface)) {
+			continue
+		}
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			continue
+		}
+		impls = append(impls, polymorphicImplementation{discriminator: discriminator, structType: t})
+	}
+	return impls
+}
+
+// interfaceSchema describes a field of interface type t as a oneOf over the
+// registered polymorphic types implementing it, in the inline format, with a
+// discriminator object mapping each discriminator to the implementing type's
+// name. It reports false for the empty interface and for interfaces without
+// registered implementations. The polymorphic registry can change after
+// generation without clearing the schema cache, so the builder notes that it
+// consulted it and leaves the result uncached.
+func (b *Builder) interfaceSchema(t reflect.Type, useRef bool) (map[string]any, bool) {
+	if t.NumMethod() == 0 {
+		return nil, false
+	}
+	b.usesPolymorphicRegistry = true
+	impls := registeredImplementations(t)
+	if len(impls) == 0 {
+		return nil, false
+	}
+
+	// Inline generation breaks cycles at the implementing structs; component
+	// generation does not track them, so stop at the interface instead.
+	if b.inProgress == nil || useRef {
+		if b.interfaces[t] {
+			return map[string]any{}, true
+		}
+		if b.interfaces == nil {
+			b.interfaces = make(map[reflect.Type]bool)
+		}
+		b.interfaces[t] = true
+		defer delete(b.interfaces, t)
+	}
+
+	variants := make([]any, 0, len(impls))
+	mapping := make(map[string]any, len(impls))
+	for _, impl := range impls {
+		schema := b.schemaInternal(impl.structType, useRef)
+		if _, isRef := schema[RefKey]; isRef {
+			schema = map[string]any{TypeKey: TypeObject, AllOfKey: []any{schema}}
+		}
+		variants = append(variants, unionVariant(impl.discriminator, schema))
+		mapping[impl.discriminator] = componentName(impl.structType)
+	}
+	return map[string]any{
+		OneOfKey: variants,
+		DiscriminatorKey: map[string]any{
+			"propertyName": polymorphic.InlineDiscriminatorKey,
+			"mapping":      mapping,
+		},
+	}, true
+}
+
+// unionVariant adds the discriminator member to a struct schema.
+func unionVariant(discriminator string, schema map[string]any) map[string]any {
+	delete(schema, IDKey)
+	schema[TitleKey] = discriminator
+
+	properties, _ := schema[PropertiesKey].(map[string]any)
+	if properties == nil {
+		properties = map[string]any{}
+		schema[PropertiesKey] = properties
+	}
+	properties[polymorphic.InlineDiscriminatorKey] = map[string]any{
+		TypeKey:  TypeString,
+		ConstKey: discriminator,
+	}
+
+	required, _ := schema[RequiredKey].([]string)
+	schema[RequiredKey] = append([]string{polymorphic.InlineDiscriminatorKey}, required...)
+	return schema
+}
@@ -0,0 +1,245 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/fgrzl/json/polymorphic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type UnionCircle struct {
+	Radius float64 `json:"radius"`
+}
+
+func (c *UnionCircle) GetDiscriminator() string { return "circle" }
+
+func (c *UnionCircle) Area() float64 { return 3.14159 * c.Radius * c.Radius }
+
+type UnionSquare struct {
+	Side  float64 `json:"side" required:"true"`
+	Label string  `json:"label,omitempty"`
+}
+
+func (s *UnionSquare) GetDiscriminator() string { return "square" }
+
+func (s *UnionSquare) Area() float64 { return s.Side * s.Side }
+
+type UnionShape interface {
+	Area() float64
+}
+
+type UnionGroup struct {
+	Shapes []UnionShape `json:"shapes"`
+}
+
+func (g *UnionGroup) GetDiscriminator() string { return "group" }
+
+func (g *UnionGroup) Area() float64 { return 0 }
+
+type UnionDrawing struct {
+	Name  string     `json:"name"`
+	Shape UnionShape `json:"shape"`
+	Meta  any        `json:"meta"`
+}
+
+func unionVariantTitled(t *testing.T, schema map[string]any, title string) map[string]any {
+	t.Helper()
+	for _, variant := range schema[OneOfKey].([]any) {
+		if v := variant.(map[string]any); v[TitleKey] == title {
+			return v
+		}
+	}
+	require.Failf(t, "variant not found", "no variant titled %q", title)
+	return nil
+}
+
+func TestShouldGenerateTSUnionSchemaGivenRegisteredTypes(t *testing.T) {
+	// Arrange
+	polymorphic.ClearRegistry()
+	t.Cleanup(polymorphic.ClearRegistry)
+	polymorphic.RegisterType[UnionSquare]()
+	polymorphic.RegisterType[UnionCircle]()
+
+	// Act
+	schema := GenerateTSUnionSchema()
+
+	// Assert
+	circle := unionVariantTitled(t, schema, "circle")
+	assert.Equal(t, map[string]any{
+		TypeKey:  TypeObject,
+		TitleKey: "circle",
+		PropertiesKey: map[string]any{
+			"type":   map[string]any{TypeKey: TypeString, ConstKey: "circle"},
+			"radius": map[string]any{TypeKey: TypeNumber},
+		},
+		RequiredKey: []string{"type"},
+	}, circle)
+	square := unionVariantTitled(t, schema, "square")
+	assert.Equal(t, []string{"type", "side"}, square[RequiredKey])
+	assert.Equal(t, map[string]any{TypeKey: TypeString, ConstKey: "square"}, square[PropertiesKey].(map[string]any)["type"])
+}
+
+func TestShouldValidateInlineEnvelopesGivenTSUnionSchema(t *testing.T) {
+	// Arrange
+	polymorphic.ClearRegistry()
+	t.Cleanup(polymorphic.ClearRegistry)
+	polymorphic.RegisterType[UnionSquare]()
+	polymorphic.RegisterType[UnionCircle]()
+	schema := GenerateTSUnionSchema()
+	data, err := polymorphic.MarshalPolymorphicJSONInline(&UnionCircle{Radius: 2})
+	require.NoError(t, err)
+	var circle, mismatched any
+	require.NoError(t, json.Unmarshal(data, &circle))
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"square","radius":2}`), &mismatched))
+
+	// Act
+	validErr := Validate(schema, circle)
+	invalidErr := Validate(schema, mismatched)
+
+	// Assert
+	assert.NoError(t, validErr)
+	assert.Error(t, invalidErr)
+}
+
+func TestShouldGenerateOneOfGivenInterfaceFieldWithRegisteredImplementations(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	polymorphic.ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	t.Cleanup(polymorphic.ClearRegistry)
+	polymorphic.RegisterType[UnionCircle]()
+	polymorphic.RegisterType[UnionSquare]()
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(UnionDrawing{}))
+
+	// Assert
+	properties := schema[PropertiesKey].(map[string]any)
+	shape := properties["shape"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"propertyName": "type",
+		"mapping":      map[string]any{"circle": "UnionCircle", "square": "UnionSquare"},
+	}, shape[DiscriminatorKey])
+	variants := shape[OneOfKey].([]any)
+	require.Len(t, variants, 2)
+	assert.Equal(t, "circle", variants[0].(map[string]any)[TitleKey])
+	assert.Equal(t, "square", variants[1].(map[string]any)[TitleKey])
+	assert.Equal(t, map[string]any{TypeKey: TypeString}, properties["meta"], "the empty interface is not expanded")
+
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"d","shape":{"type":"square","side":2}}`), &doc))
+	assert.NoError(t, Validate(schema, doc))
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"d","shape":{"type":"square","radius":2}}`), &doc))
+	assert.Error(t, Validate(schema, doc))
+}
+
+func TestShouldReflectLaterRegistrationsGivenInterfaceFieldSchemaGeneratedEarlier(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	polymorphic.ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	t.Cleanup(polymorphic.ClearRegistry)
+	polymorphic.RegisterType[UnionCircle]()
+	GenerateSchema(reflect.TypeOf(UnionDrawing{}))
+	GenerateSchemaWithComponents(reflect.TypeOf(UnionDrawing{}))
+	SchemaFrom[UnionDrawing]()
+	polymorphic.RegisterType[UnionSquare]()
+
+	// Act
+	inline := GenerateSchema(reflect.TypeOf(UnionDrawing{}))
+	root, _ := GenerateSchemaWithComponents(reflect.TypeOf(UnionDrawing{}))
+	var raw map[string]any
+	require.NoError(t, json.Unmarshal(SchemaFrom[UnionDrawing](), &raw))
+
+	// Assert
+	for _, schema := range []map[string]any{inline, root, raw} {
+		shape := schema[PropertiesKey].(map[string]any)["shape"].(map[string]any)
+		assert.Len(t, shape[OneOfKey], 2)
+	}
+}
+
+func TestShouldStopAtRecursiveInterfaceGivenSelfContainingImplementation(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	polymorphic.ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	t.Cleanup(polymorphic.ClearRegistry)
+	polymorphic.RegisterType[UnionCircle]()
+	polymorphic.RegisterType[UnionGroup]()
+
+	// Act
+	inline := GenerateSchema(reflect.TypeOf(UnionDrawing{}))
+	root, _ := GenerateSchemaWithComponents(reflect.TypeOf(UnionDrawing{}))
+
+	// Assert
+	for _, schema := range []map[string]any{inline, root} {
+		shape := schema[PropertiesKey].(map[string]any)["shape"].(map[string]any)
+		assert.Len(t, shape[OneOfKey], 2)
+	}
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(`{"shape":{"type":"group","shapes":[{"type":"circle","radius":1},{"type":"group","shapes":[]}]}}`), &doc))
+	assert.NoError(t, Validate(inline, doc))
+}
+
+func TestShouldGenerateRegistrySchemaGivenRegisteredTypes(t *testing.T) {
+	// Arrange
+	polymorphic.ClearRegistry()
+	t.Cleanup(polymorphic.ClearRegistry)
+	polymorphic.RegisterType[UnionSquare]()
+	polymorphic.RegisterType[UnionCircle]()
+
+	// Act
+	schema := GenerateRegistrySchema()
+
+	// Assert
+	defs := schema[DefsKey].(map[string]any)
+	assert.Equal(t, map[string]any{
+		TypeKey: TypeObject,
+		PropertiesKey: map[string]any{
+			"radius": map[string]any{TypeKey: TypeNumber},
+		},
+	}, defs["UnionCircle"])
+	assert.Contains(t, defs, "UnionSquare")
+	mapping := schema[DiscriminatorKey].(map[string]any)["mapping"].(map[string]any)
+	assert.Equal(t, "UnionCircle", mapping["circle"])
+	assert.Equal(t, "UnionSquare", mapping["square"])
+	assert.Equal(t, "$type", schema[DiscriminatorKey].(map[string]any)["propertyName"])
+
+	circle := unionVariantTitled(t, schema, "circle")
+	assert.Equal(t, map[string]any{
+		TypeKey:  TypeObject,
+		TitleKey: "circle",
+		PropertiesKey: map[string]any{
+			"$type":    map[string]any{TypeKey: TypeString, ConstKey: "circle"},
+			"$version": map[string]any{TypeKey: TypeInteger},
+			"meta":     map[string]any{TypeKey: TypeObject},
+			"content":  map[string]any{RefKey: "#/$defs/UnionCircle"},
+		},
+		RequiredKey: []string{"$type", "content"},
+	}, circle)
+}
+
+func TestShouldValidateEnvelopesGivenRegistrySchema(t *testing.T) {
+	// Arrange
+	polymorphic.ClearRegistry()
+	t.Cleanup(polymorphic.ClearRegistry)
+	polymorphic.RegisterType[UnionSquare]()
+	polymorphic.RegisterType[UnionCircle]()
+	schema := GenerateRegistrySchema()
+	data, err := polymorphic.MarshalPolymorphicJSON(&UnionSquare{Side: 3})
+	require.NoError(t, err)
+	var square, mismatched any
+	require.NoError(t, json.Unmarshal(data, &square))
+	require.NoError(t, json.Unmarshal([]byte(`{"$type":"square","content":{"radius":2}}`), &mismatched))
+
+	// Act
+	validErr := Validate(schema, square)
+	invalidErr := Validate(schema, mismatched)
+
+	// Assert
+	assert.NoError(t, validErr)
+	assert.Error(t, invalidErr)
+}
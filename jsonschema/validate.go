@@ -101,6 +101,15 @@ func validateAt(root map[string]any, path *validationPath, schema map[string]any
 		}
 	}
 
+	// Go-side validators registered with RegisterValidator
+	if id, ok := schema[ValidatorKey].(string); ok {
+		if fn, ok := lookupValidator(id); ok {
+			if err := fn(data); err != nil {
+				addErr(errs, path, err.Error())
+			}
+		}
+	}
+
 	// allOf: must validate against all subschemas
 	if allOf, ok := schema[AllOfKey].([]any); ok {
 		for _, s := range allOf {
@@ -165,6 +174,8 @@ func validateAt(root map[string]any, path *validationPath, schema map[string]any
 	}
 
 	validateIfThenElse(root, path, schema, data, errs)
+	validateUnevaluatedProperties(root, path, schema, data, errs)
+	validateContent(root, path, schema, data, errs)
 
 	// type: string or []any for nullable
 	if typeVal, hasType := schema[TypeKey]; hasType {
@@ -242,6 +253,24 @@ func validateInstanceConstraints(root map[string]any, path *validationPath, sche
 	}
 }
 
+// validateContent decodes a string whose contentMediaType is
+// application/json and validates the result against contentSchema, as used
+// for `json:",string"` numbers. Other media types are annotations only.
+func validateContent(root map[string]any, path *validationPath, schema map[string]any, data any, errs *[]ValidationError) {
+	s, ok := data.(string)
+	if !ok || schema[ContentMediaTypeKey] != ContentTypeJSON {
+		return
+	}
+	var decoded any
+	if err := json.Unmarshal([]byte(s), &decoded); err != nil {
+		addErr(errs, path, "string content is not valid JSON")
+		return
+	}
+	if contentSchema, ok := schema[ContentSchemaKey].(map[string]any); ok {
+		validateAt(root, path, contentSchema, decoded, errs)
+	}
+}
+
 func validateIfThenElse(root map[string]any, path *validationPath, schema map[string]any, data any, errs *[]ValidationError) {
 	ifSchema, ok := schema[IfKey].(map[string]any)
 	if !ok {
@@ -353,7 +382,13 @@ func validateObject(root map[string]any, path *validationPath, schema map[string
 		}
 	}
 	props, _ := schema[PropertiesKey].(map[string]any)
+	propertyNames, _ := schema[PropertyNamesKey].(map[string]any)
 	for key, val := range obj {
+		if propertyNames != nil {
+			path.push(escapeJSONPointer(key))
+			validateAt(root, path, propertyNames, key, errs)
+			path.pop()
+		}
 		if props != nil {
 			if subSchema, ok := props[key].(map[string]any); ok {
 				path.push(escapeJSONPointer(key))
@@ -374,11 +409,26 @@ func validateObject(root map[string]any, path *validationPath, schema map[string
 }
 
 func validateArray(root map[string]any, path *validationPath, schema map[string]any, arr []any, errs *[]ValidationError) {
+	prefixItems, _ := schema[PrefixItemsKey].([]any)
 	itemsSchema, hasItems := schema[ItemsKey].(map[string]any)
+	if tuple, ok := schema[ItemsKey].([]any); ok && prefixItems == nil {
+		// Drafts before 2020-12 spell prefixItems as an items array, with
+		// additionalItems describing the rest.
+		prefixItems = tuple
+		itemsSchema, hasItems = schema[additionalItemsKey].(map[string]any)
+	}
+	for i := 0; i < len(prefixItems) && i < len(arr); i++ {
+		if sub, ok := prefixItems[i].(map[string]any); ok {
+			path.push(strconv.Itoa(i))
+			validateAt(root, path, sub, arr[i], errs)
+			path.pop()
+		}
+	}
+
 	if hasItems {
-		for i, item := range arr {
+		for i := len(prefixItems); i < len(arr); i++ {
 			path.push(strconv.Itoa(i))
-			validateAt(root, path, itemsSchema, item, errs)
+			validateAt(root, path, itemsSchema, arr[i], errs)
 			path.pop()
 		}
 	}
@@ -607,17 +657,36 @@ func validateContains(root map[string]any, path *validationPath, schema map[stri
 		return
 	}
 
+	minContains := 1
+	if v, ok := toFloat(schema[MinContainsKey]); ok {
+		minContains = int(v)
+	}
+	maxContains, hasMax := toFloat(schema[MaxContainsKey])
+
+	matches := 0
 	for i, item := range arr {
 		var itemErrs []ValidationError
 		path.push(strconv.Itoa(i))
 		validateAt(root, path, containsSchema, item, &itemErrs)
 		path.pop()
 		if len(itemErrs) == 0 {
-			return
+			matches++
+			if !hasMax && matches >= minContains {
+				return
+			}
 		}
 	}
 
-	addErr(errs, path, "array must contain at least one item matching contains")
+	if matches < minContains {
+		if minContains == 1 {
+			addErr(errs, path, "array must contain at least one item matching contains")
+		} else {
+			addErr(errs, path, fmt.Sprintf("array contains %d matching item(s), less than minContains %d", matches, minContains))
+		}
+	}
+	if hasMax && float64(matches) > maxContains {
+		addErr(errs, path, fmt.Sprintf("array contains %d matching item(s), greater than maxContains %d", matches, int(maxContains)))
+	}
 }
 
 func validateArrayLength(path *validationPath, schema map[string]any, length int, errs *[]ValidationError) {
@@ -728,14 +797,16 @@ func escapeJSONPointer(key string) string {
 }
 
 // deepEqualJSON compares two JSON-like values (float64, string, bool, nil, []any, map[string]any).
+// Numbers compare by value, so schema values written as Go ints match decoded float64 data.
 func deepEqualJSON(a, b any) bool {
 	if a == nil || b == nil {
 		return a == b
 	}
-	switch av := a.(type) {
-	case float64:
-		bv, ok := b.(float64)
+	if av, ok := toFloat(a); ok {
+		bv, ok := toFloat(b)
 		return ok && av == bv
+	}
+	switch av := a.(type) {
 	case string:
 		bv, ok := b.(string)
 		return ok && av == bv
@@ -784,7 +855,9 @@ func schemaAnySlice(value any) ([]any, bool) {
 	}
 }
 
-// resolveRef resolves #/$defs/X or #/defs/X from the root (same-document only).
+// resolveRef resolves #/$defs/X, #/defs/X, #/components/schemas/X, and
+// plain-name anchors (#X, matching "$anchor": "X") from the root
+// (same-document only).
 func resolveRef(rootSchema map[string]any, ref string) (map[string]any, error) {
 	if ref == "" {
 		return nil, fmt.Errorf("unresolved ref %q", ref)
@@ -798,11 +871,17 @@ func resolveRef(rootSchema map[string]any, ref string) (map[string]any, error) {
 	if ref == "" {
 		return rootSchema, nil
 	}
+	if ref[0] != '/' {
+		if sub := findAnchor(rootSchema, ref); sub != nil {
+			return sub, nil
+		}
+		return nil, fmt.Errorf("unresolved ref %q", originalRef)
+	}
 	if ref[0] == '/' {
 		ref = ref[1:]
 	}
 	parts := strings.Split(ref, "/")
-	if len(parts) >= 2 && (parts[0] == "$defs" || parts[0] == "defs") {
+	if len(parts) >= 2 && (parts[0] == "$defs" || parts[0] == "defs" || parts[0] == definitionsKey) {
 		defs, _ := rootSchema[parts[0]].(map[string]any)
 		if defs == nil {
 			defs, _ = rootSchema[DefsKey].(map[string]any)
@@ -826,3 +905,25 @@ func resolveRef(rootSchema map[string]any, ref string) (map[string]any, error) {
 	}
 	return nil, fmt.Errorf("unresolved ref %q", originalRef)
 }
+
+// findAnchor returns the subschema of schema declaring "$anchor": name.
+func findAnchor(schema any, name string) map[string]any {
+	switch node := schema.(type) {
+	case map[string]any:
+		if node[AnchorKey] == name || node[IDKey] == "#"+name {
+			return node
+		}
+		for _, value := range node {
+			if found := findAnchor(value, name); found != nil {
+				return found
+			}
+		}
+	case []any:
+		for _, item := range node {
+			if found := findAnchor(item, name); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
@@ -738,6 +738,60 @@ func TestValidateContainsPassesWhenAnyItemMatches(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestValidateContainsEnforcesMinAndMaxContains(t *testing.T) {
+	schema := map[string]any{
+		TypeKey:        TypeArray,
+		ContainsKey:    map[string]any{TypeKey: TypeInteger},
+		MinContainsKey: 2,
+		MaxContainsKey: 3,
+	}
+
+	assert.NoError(t, Validate(schema, []any{1.0, "a", 2.0}))
+
+	err := Validate(schema, []any{1.0, "a"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "minContains")
+
+	err = Validate(schema, []any{1.0, 2.0, 3.0, 4.0})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "maxContains")
+}
+
+func TestValidatePrefixItemsChecksPositionalSchemas(t *testing.T) {
+	schema := map[string]any{
+		TypeKey: TypeArray,
+		PrefixItemsKey: []any{
+			map[string]any{TypeKey: TypeString},
+			map[string]any{TypeKey: TypeInteger},
+		},
+		ItemsKey: map[string]any{TypeKey: TypeBoolean},
+	}
+
+	assert.NoError(t, Validate(schema, []any{"a", 1.0, true}))
+
+	err := Validate(schema, []any{1.0, "a"})
+	require.Error(t, err)
+	verr := err.(*ErrValidation)
+	assert.Len(t, verr.Errs, 2)
+	assert.Equal(t, "/0", verr.Errs[0].Path)
+}
+
+func TestValidatePropertyNamesChecksEveryKey(t *testing.T) {
+	schema := map[string]any{
+		TypeKey:          TypeObject,
+		PropertyNamesKey: map[string]any{PatternKey: "^[a-z]+$"},
+	}
+
+	assert.NoError(t, Validate(schema, map[string]any{"alpha": 1.0, "beta": 2.0}))
+
+	err := Validate(schema, map[string]any{"alpha": 1.0, "Beta2": 2.0})
+	require.Error(t, err)
+	verr := err.(*ErrValidation)
+	require.Len(t, verr.Errs, 1)
+	assert.Equal(t, "/Beta2", verr.Errs[0].Path)
+	assert.Contains(t, verr.Errs[0].Message, "pattern")
+}
+
 func TestValidateIfThenAppliesThenSchemaWhenConditionMatches(t *testing.T) {
 	schema := map[string]any{
 		IfKey: map[string]any{
@@ -789,3 +843,36 @@ func TestValidateIfThenElseAppliesElseSchemaWhenConditionFails(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "model")
 }
+
+func TestValidateDistinguishesMissingFromExplicitNull(t *testing.T) {
+	// Arrange
+	type Contact struct {
+		Email    *string `json:"email" required:"true"`
+		Nickname string  `json:"nickname" required:"true"`
+	}
+	schema := GenerateSchema(reflect.TypeOf(Contact{}))
+
+	tests := []struct {
+		name    string
+		data    map[string]any
+		wantErr string
+	}{
+		{name: "null on nullable required field", data: map[string]any{"email": nil, "nickname": "al"}},
+		{name: "null on non-nullable required field", data: map[string]any{"email": "a@b.c", "nickname": nil}, wantErr: "nickname"},
+		{name: "missing nullable required field", data: map[string]any{"nickname": "al"}, wantErr: "email"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := Validate(schema, tt.data)
+
+			// Assert
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
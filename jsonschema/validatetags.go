@@ -0,0 +1,140 @@
+package jsonschema
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ValidateTag is the struct tag read by WithValidateTags, in the format used
+// by github.com/go-playground/validator.
+const ValidateTag = "validate"
+
+// WithValidateTags derives schema constraints from validate struct tags so
+// types already annotated for go-playground/validator need no duplicate
+// schema tags. Mapped rules are required; min, max, len, gt, gte, lt, and lte
+// (bounds on numbers, lengths on strings, item counts on arrays, and property
+// counts on maps); email, url, uri, uuid, hostname, ipv4, and ipv6 (format);
+// and oneof (enum). Rules after dive describe elements and are skipped, as
+// are alternatives joined with "|" and unknown rules. Keywords set by
+// explicit schema tags take precedence.
+func WithValidateTags() BuilderOption {
+	return func(b *Builder) {
+		b.validateTags = true
+	}
+}
+
+// validateFormats maps validator rules to the equivalent format keyword.
+var validateFormats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"uuid":     "uuid",
+	"uuid4":    "uuid",
+	"hostname": "hostname",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+}
+
+// oneOfValue matches one value of a oneof rule: a single-quoted string or a
+// run of non-space characters.
+var oneOfValue = regexp.MustCompile(`'[^']*'|\S+`)
+
+// applyValidateTag maps the rules of field's validate tag onto schema and
+// reports whether the tag marks the field as required.
+func applyValidateTag(field reflect.StructField, schema map[string]any) bool {
+	tag := field.Tag.Get(ValidateTag)
+	if tag == "" || tag == "-" {
+		return false
+	}
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "dive", "keys":
+			return required
+		case "required":
+			required = true
+		case "min", "max", "len", "gt", "gte", "lt", "lte":
+			applyValidateBound(schema, name, param)
+		case "oneof":
+			var values []any
+			for _, v := range oneOfValue.FindAllString(param, -1) {
+				values = append(values, typedDefaultValue(field.Type, schema, strings.Trim(v, "'")))
+			}
+			if len(values) > 0 {
+				setMissing(schema, EnumKey, values)
+			}
+		default:
+			if format, ok := validateFormats[name]; ok {
+				setMissing(schema, FormatKey, format)
+			}
+		}
+	}
+	return required
+}
+
+// applyValidateBound maps a min, max, len, gt, gte, lt, or lte rule onto the
+// keyword matching the schema's type.
+func applyValidateBound(schema map[string]any, rule, param string) {
+	switch schema[TypeKey] {
+	case TypeInteger, TypeNumber:
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+		switch rule {
+		case "min", "gte":
+			setMissing(schema, MinimumKey, n)
+		case "max", "lte":
+			setMissing(schema, MaximumKey, n)
+		case "len":
+			setMissing(schema, MinimumKey, n)
+			setMissing(schema, MaximumKey, n)
+		case "gt":
+			setMissing(schema, ExclusiveMinimumKey, n)
+		case "lt":
+			setMissing(schema, ExclusiveMaximumKey, n)
+		}
+	case TypeString:
+		applyValidateCount(schema, rule, param, MinLengthKey, MaxLengthKey)
+	case TypeArray:
+		applyValidateCount(schema, rule, param, MinItemsKey, MaxItemsKey)
+	case TypeObject:
+		if _, ok := schema[PropertiesKey]; !ok {
+			applyValidateCount(schema, rule, param, MinPropertiesKey, MaxPropertiesKey)
+		}
+	}
+}
+
+// applyValidateCount maps a bound rule onto a pair of count keywords such as
+// minLength and maxLength; gt and lt become the adjacent inclusive counts.
+func applyValidateCount(schema map[string]any, rule, param, minKey, maxKey string) {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return
+	}
+	switch rule {
+	case "min", "gte":
+		setMissing(schema, minKey, n)
+	case "max", "lte":
+		setMissing(schema, maxKey, n)
+	case "len":
+		setMissing(schema, minKey, n)
+		setMissing(schema, maxKey, n)
+	case "gt":
+		setMissing(schema, minKey, n+1)
+	case "lt":
+		if n > 0 {
+			setMissing(schema, maxKey, n-1)
+		}
+	}
+}
+
+// setMissing sets schema[key] unless a value is already present.
+func setMissing(schema map[string]any, key string, value any) {
+	if _, ok := schema[key]; !ok {
+		schema[key] = value
+	}
+}
@@ -0,0 +1,106 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validateTagsAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type validateTagsUser struct {
+	Name     string               `json:"name" validate:"required,min=2,max=50"`
+	Email    string               `json:"email" validate:"required,email"`
+	Age      int                  `json:"age" validate:"gte=0,lte=130"`
+	Score    float64              `json:"score" validate:"gt=0,lt=1"`
+	Role     string               `json:"role" validate:"oneof=admin user 'read only'"`
+	Level    int                  `json:"level" validate:"oneof=1 2 3"`
+	Code     string               `json:"code" validate:"len=6"`
+	Tags     []string             `json:"tags" validate:"min=1,dive,min=3"`
+	Labels   map[string]string    `json:"labels" validate:"max=10"`
+	Homepage *string              `json:"homepage" validate:"omitempty,url"`
+	Nick     string               `json:"nick" validate:"max=20" maxLength:"10"`
+	Contact  string               `json:"contact" validate:"email|url"`
+	Address  *validateTagsAddress `json:"address" validate:"required"`
+	Ignored  string               `json:"ignored" validate:"-"`
+}
+
+func TestShouldDeriveConstraintsGivenValidateTags(t *testing.T) {
+	// Arrange
+	typ := reflect.TypeOf(validateTagsUser{})
+
+	// Act
+	schema := NewBuilder(WithValidateTags()).Schema(typ)
+
+	// Assert
+	props := schema[PropertiesKey].(map[string]any)
+	assert.ElementsMatch(t, []string{"name", "email", "address"}, schema[RequiredKey])
+	assert.Equal(t, map[string]any{TypeKey: TypeString, MinLengthKey: 2, MaxLengthKey: 50}, props["name"])
+	assert.Equal(t, map[string]any{TypeKey: TypeString, FormatKey: "email"}, props["email"])
+	assert.Equal(t, map[string]any{TypeKey: TypeInteger, MinimumKey: 0.0, MaximumKey: 130.0}, props["age"])
+	assert.Equal(t, map[string]any{TypeKey: TypeNumber, ExclusiveMinimumKey: 0.0, ExclusiveMaximumKey: 1.0}, props["score"])
+	assert.Equal(t, []any{"admin", "user", "read only"}, props["role"].(map[string]any)[EnumKey])
+	assert.Equal(t, []any{int64(1), int64(2), int64(3)}, props["level"].(map[string]any)[EnumKey])
+	assert.Equal(t, map[string]any{TypeKey: TypeString, MinLengthKey: 6, MaxLengthKey: 6}, props["code"])
+	assert.Equal(t, 1, props["tags"].(map[string]any)[MinItemsKey])
+	assert.NotContains(t, props["tags"].(map[string]any)[ItemsKey], MinLengthKey)
+	assert.Equal(t, 10, props["labels"].(map[string]any)[MaxPropertiesKey])
+	assert.Equal(t, "uri", props["homepage"].(map[string]any)[FormatKey])
+	assert.Equal(t, 10, props["nick"].(map[string]any)[MaxLengthKey])
+	assert.NotContains(t, props["contact"], FormatKey)
+	assert.Equal(t, map[string]any{TypeKey: TypeString}, props["ignored"])
+	address := props["address"].(map[string]any)
+	assert.Equal(t, []string{"city"}, address[RequiredKey])
+}
+
+func TestShouldValidateAgainstSchemaGivenValidateTags(t *testing.T) {
+	// Arrange
+	schema := NewBuilder(WithValidateTags()).Schema(reflect.TypeOf(validateTagsUser{}))
+	doc := map[string]any{
+		"name":    "A",
+		"email":   "ada@example.com",
+		"age":     float64(140),
+		"role":    "owner",
+		"address": map[string]any{"city": "London"},
+	}
+
+	// Act
+	err := Validate(schema, doc)
+
+	// Assert
+	var verr *ErrValidation
+	require.ErrorAs(t, err, &verr)
+	paths := make([]string, 0, len(verr.Errors()))
+	for _, e := range verr.Errors() {
+		paths = append(paths, e.Path)
+	}
+	assert.ElementsMatch(t, []string{"/name", "/age", "/role"}, paths)
+}
+
+func TestShouldRequireReferencedFieldGivenValidateTagsWithComponents(t *testing.T) {
+	// Arrange
+	type Order struct {
+		Shipping validateTagsAddress `json:"shipping" validate:"required"`
+	}
+
+	// Act
+	schema, components := NewBuilder(WithValidateTags()).SchemaWithComponents(reflect.TypeOf(Order{}))
+
+	// Assert
+	assert.Equal(t, []string{"shipping"}, schema[RequiredKey])
+	assert.Equal(t, map[string]any{RefKey: "#/components/schemas/validateTagsAddress"}, schema[PropertiesKey].(map[string]any)["shipping"])
+	assert.Contains(t, components, "validateTagsAddress")
+}
+
+func TestShouldIgnoreValidateTagsGivenDefaultBuilder(t *testing.T) {
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(validateTagsUser{}))
+
+	// Assert
+	assert.NotContains(t, schema, RequiredKey)
+	assert.Equal(t, map[string]any{TypeKey: TypeString}, schema[PropertiesKey].(map[string]any)["email"])
+}
@@ -0,0 +1,78 @@
+package jsonschema
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// FieldValidator checks a decoded JSON value (map[string]any, []any,
+// float64, string, bool, or nil) and returns an error describing why it is
+// invalid, or nil.
+type FieldValidator = func(value any) error
+
+var (
+	fieldValidators   = make(map[string]FieldValidator)
+	fieldValidatorsMu sync.RWMutex
+)
+
+// RegisterValidator attaches a Go-side check to the field fieldName (the Go
+// field name) of struct type t. Schemas generated for t afterwards mark the
+// field's property with ValidatorKey, and Validate calls fn with the
+// property's value whenever it is present, reporting a returned error at the
+// property's path alongside the schema keyword errors. Registering again for
+// the same field replaces the validator.
+//
+// Like RegisterSchema, the registration is process-wide and is removed by
+// ClearRegistry. RegisterValidator panics if t is not a named struct type,
+// has no field fieldName, or fn is nil.
+func RegisterValidator(t reflect.Type, fieldName string, fn FieldValidator) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || t.Name() == "" {
+		panic(fmt.Sprintf("jsonschema: RegisterValidator requires a named struct type, got %v", t))
+	}
+	if _, ok := t.FieldByName(fieldName); !ok {
+		panic(fmt.Sprintf("jsonschema: type %s has no field %q", t, fieldName))
+	}
+	if fn == nil {
+		panic("jsonschema: validator must be non-nil")
+	}
+
+	fieldValidatorsMu.Lock()
+	fieldValidators[validatorID(t, fieldName)] = fn
+	fieldValidatorsMu.Unlock()
+	clearSchemaCache()
+}
+
+// validatorID names the validator of a struct field in generated schemas.
+func validatorID(t reflect.Type, fieldName string) string {
+	return t.PkgPath() + "." + t.Name() + "." + fieldName
+}
+
+// registeredValidatorID returns the ValidatorKey value for the field of t,
+// if a validator is registered for it.
+func registeredValidatorID(t reflect.Type, fieldName string) (string, bool) {
+	if t.Name() == "" {
+		return "", false
+	}
+	id := validatorID(t, fieldName)
+	fieldValidatorsMu.RLock()
+	_, ok := fieldValidators[id]
+	fieldValidatorsMu.RUnlock()
+	return id, ok
+}
+
+func lookupValidator(id string) (FieldValidator, bool) {
+	fieldValidatorsMu.RLock()
+	defer fieldValidatorsMu.RUnlock()
+	fn, ok := fieldValidators[id]
+	return fn, ok
+}
+
+func clearValidators() {
+	fieldValidatorsMu.Lock()
+	fieldValidators = make(map[string]FieldValidator)
+	fieldValidatorsMu.Unlock()
+}
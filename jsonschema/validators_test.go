@@ -0,0 +1,89 @@
+package jsonschema
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type SignupRequest struct {
+	Email string `json:"email" required:"true"`
+	Age   int    `json:"age" minimum:"18"`
+}
+
+func rejectDomain(domain string) FieldValidator {
+	return func(value any) error {
+		email, _ := value.(string)
+		if strings.HasSuffix(email, "@"+domain) {
+			return errors.New("email domain " + domain + " is not allowed")
+		}
+		return nil
+	}
+}
+
+func TestValidateRunsRegisteredValidatorGivenGeneratedSchema(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterValidator(reflect.TypeOf(SignupRequest{}), "Email", rejectDomain("spam.example"))
+	schema := GenerateSchema(reflect.TypeOf(SignupRequest{}))
+
+	// Act
+	allowed := Validate(schema, map[string]any{"email": "alice@example.com", "age": float64(30)})
+	rejected := Validate(schema, map[string]any{"email": "bot@spam.example", "age": float64(12)})
+
+	// Assert
+	assert.NoError(t, allowed)
+	var verr *ErrValidation
+	require.ErrorAs(t, rejected, &verr)
+	assert.ElementsMatch(t, []ValidationError{
+		{Path: "/email", Message: "email domain spam.example is not allowed"},
+		{Path: "/age", Message: "value 12 less than minimum 18"},
+	}, verr.Errors())
+}
+
+func TestValidateSkipsValidatorGivenMissingField(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	calls := 0
+	RegisterValidator(reflect.TypeOf(SignupRequest{}), "Age", func(any) error {
+		calls++
+		return nil
+	})
+	schema := GenerateSchema(reflect.TypeOf(SignupRequest{}))
+
+	// Act
+	err := Validate(schema, map[string]any{"email": "alice@example.com"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Zero(t, calls)
+	assert.Equal(t, validatorID(reflect.TypeOf(SignupRequest{}), "Age"), schema[PropertiesKey].(map[string]any)["age"].(map[string]any)[ValidatorKey])
+}
+
+func TestShouldRemoveValidatorsGivenClearRegistry(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterValidator(reflect.TypeOf(SignupRequest{}), "Email", rejectDomain("spam.example"))
+	ClearRegistry()
+
+	// Act
+	schema := GenerateSchema(reflect.TypeOf(SignupRequest{}))
+
+	// Assert
+	assert.NotContains(t, schema[PropertiesKey].(map[string]any)["email"], ValidatorKey)
+	assert.NoError(t, Validate(schema, map[string]any{"email": "bot@spam.example"}))
+}
+
+func TestShouldPanicGivenInvalidValidatorRegistration(t *testing.T) {
+	ok := func(any) error { return nil }
+	assert.Panics(t, func() { RegisterValidator(reflect.TypeOf(""), "Email", ok) })
+	assert.Panics(t, func() { RegisterValidator(reflect.TypeOf(SignupRequest{}), "Missing", ok) })
+	assert.Panics(t, func() { RegisterValidator(reflect.TypeOf(SignupRequest{}), "Email", nil) })
+}
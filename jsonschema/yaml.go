@@ -0,0 +1,30 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GenerateSchemaYAML returns the schema GenerateSchema builds for t, encoded
+// as YAML for OpenAPI and other YAML-based workflows. The schema is first
+// normalized through its JSON form, so the YAML holds exactly the keywords
+// and values of the JSON output; object keys are written in sorted order, so
+// the output is deterministic.
+func GenerateSchemaYAML(t reflect.Type, opts ...BuilderOption) ([]byte, error) {
+	data, err := json.Marshal(GenerateSchema(t, opts...))
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: marshal schema: %w", err)
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("jsonschema: decode schema: %w", err)
+	}
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: marshal schema as YAML: %w", err)
+	}
+	return out, nil
+}
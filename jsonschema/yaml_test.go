@@ -0,0 +1,59 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type yamlAddress struct {
+	Street string `json:"street" required:"true" minLength:"1"`
+	Zip    string `json:"zip" pattern:"^[0-9]{5}$"`
+}
+
+type yamlCustomer struct {
+	Name    string            `json:"name" required:"true"`
+	Age     int               `json:"age" minimum:"0"`
+	Tags    []string          `json:"tags,omitempty"`
+	Address yamlAddress       `json:"address"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Status  string            `json:"status" enum:"active,inactive"`
+}
+
+func TestShouldRoundTripSchemaGivenYAMLOutput(t *testing.T) {
+	// Arrange
+	typ := reflect.TypeFor[yamlCustomer]()
+	data, err := json.Marshal(GenerateSchema(typ))
+	require.NoError(t, err)
+
+	// Act
+	out, err := GenerateSchemaYAML(typ)
+	require.NoError(t, err)
+
+	// Assert
+	var got any
+	require.NoError(t, yaml.Unmarshal(out, &got))
+	gotJSON, err := json.Marshal(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(gotJSON))
+}
+
+func TestShouldProduceStableYAMLGivenRepeatedGeneration(t *testing.T) {
+	// Arrange
+	typ := reflect.TypeFor[yamlCustomer]()
+
+	// Act
+	first, err := GenerateSchemaYAML(typ)
+	require.NoError(t, err)
+	second, err := GenerateSchemaYAML(typ)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, string(first), string(second))
+	assert.Contains(t, string(first), "type: object\n")
+	assert.Regexp(t, `(?m)^required:\n\s+- name$`, string(first))
+}
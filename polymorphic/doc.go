@@ -3,18 +3,57 @@
 //
 // # Wire format
 //
-// The wire format is a JSON object with two fields:
+// The wire format is a JSON object with two required fields and two optional
+// ones:
 //   - "$type" (string): the discriminator; must be non-empty and must have
 //     been registered via Register, RegisterType, or RegisterWithDiscriminator.
-//   - "content" (object): the JSON value decoded into the type registered
-//     for that discriminator. It must be present and non-null.
+//   - "content": the JSON value decoded into the type registered for that
+//     discriminator. It is usually an object, but registered slice or scalar
+//     types round-trip as arrays or scalars. It must be present and non-null.
+//   - "$version" (integer, optional): the payload version, exposed as
+//     Envelope.Version and omitted when zero. MarshalPolymorphicJSONVersioned
+//     writes it. Migrations registered with RegisterMigration upgrade older
+//     content one version at a time before it is decoded.
+//   - "meta" (object, optional): transport headers such as a correlation ID,
+//     exposed as Envelope.Metadata and omitted when empty. Metadata is decoded
+//     independently of the registered content type.
 //
-// Unknown top-level keys are ignored when unmarshaling.
+// MarshalPolymorphicJSONInline and UnmarshalPolymorphicJSONInline use an
+// alternative inline format instead, {"type":"person","name":"Alice"}, where
+// the discriminator is a plain "type" member next to the content's own
+// fields, matching TypeScript discriminated unions. Content must be a JSON
+// object without its own "type" member, and the inline format carries no
+// version. Discriminators lists the registered discriminators.
+//
+// EnvelopeLayout decodes messages that keep the discriminator and content at
+// other JSON Pointers, such as {"header":{"type":"person"},"body":{...}}.
+//
+// DecodeInto decodes an envelope's content into a caller-provided pointer,
+// such as a pooled instance, instead of one created by the factory. It
+// returns *DiscriminatorMismatchError when the discriminator is registered
+// for a different type. DecodeTyped[T](data) does the same for a fresh T (or
+// *T), returning the zero T on mismatch instead of a value that callers
+// would have to type-assert.
+//
+// UnmarshalPolymorphicJSONLenient decodes object content member by member for
+// data recovery: members that decode are kept, and the others are reported as
+// *FieldError values joined into the returned error.
+//
+// Unknown top-level keys are ignored when unmarshaling. Envelopes nested deeper
+// than MaxDepth (see SetMaxDepth) are rejected with ErrMaxDepthExceeded so that
+// deeply recursive payloads cannot exhaust the stack. SetMaxContentBytes
+// optionally caps the raw size of content, returning *ContentTooLargeError.
+//
+// # Hashing
+//
+// ContentHash returns a hex SHA-256 of an envelope in canonical form (sorted
+// keys, no whitespace), so logically equal envelopes can share an ETag.
 //
 // # Global state
 //
 // Types register themselves under a discriminator string. Some types (for
 // example PolymorphicPage) register in init() when the package is imported.
 // Tests that require a clean registry should call ClearRegistry(), which
-// removes custom registrations and restores the package defaults.
+// removes custom registrations and migrations and restores the package
+// defaults.
 package polymorphic
@@ -1,8 +1,13 @@
 package polymorphic
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 )
 
 // NewEnvelope creates an Envelope wrapping a polymorphic object. The
@@ -23,6 +28,17 @@ func MarshalPolymorphicJSON(obj Polymorphic) ([]byte, error) {
 	return json.Marshal(wrapper)
 }
 
+// MarshalPolymorphicJSONVersioned marshals obj into the envelope format
+// under the given discriminator, recording version as "$version" so
+// consumers can migrate older payloads. A zero version is omitted.
+func MarshalPolymorphicJSONVersioned(discriminator string, version int, obj any) ([]byte, error) {
+	return json.Marshal(&Envelope{
+		Discriminator: discriminator,
+		Version:       version,
+		Content:       obj,
+	})
+}
+
 // UnmarshalPolymorphicJSON unmarshals data into an Envelope and resolves
 // the contained polymorphic value using the registered factory for the
 // discriminator value.
@@ -34,17 +50,59 @@ func UnmarshalPolymorphicJSON(data []byte) (*Envelope, error) {
 	return &envelope, nil
 }
 
+// ContentHash returns a hex-encoded SHA-256 of the envelope's discriminator
+// and content in canonical form: object keys sorted and insignificant
+// whitespace removed. Envelopes whose content is logically equal hash
+// identically, which makes the result suitable as an ETag or dedup key.
+// Numbers are hashed as written, so 1 and 1.0 produce different hashes.
+func ContentHash(e *Envelope) (string, error) {
+	if e == nil {
+		return "", errors.New("cannot hash nil envelope")
+	}
+
+	contentBytes, err := json.Marshal(e.Content)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal content: %w", err)
+	}
+
+	// Round-trip through generic values so maps re-marshal with sorted keys.
+	decoder := json.NewDecoder(bytes.NewReader(contentBytes))
+	decoder.UseNumber()
+	var content any
+	if err := decoder.Decode(&content); err != nil {
+		return "", fmt.Errorf("failed to canonicalize content: %w", err)
+	}
+
+	canonical, err := json.Marshal(map[string]any{
+		"$type":   e.Discriminator,
+		"content": content,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to canonicalize content: %w", err)
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 // Envelope represents a marshaled polymorphic value. The `$type` field
 // contains the discriminator and `Content` holds the concrete value
-// after unmarshaling.
+// after unmarshaling. Version is an optional payload version carried as
+// `$version`; zero means unversioned. Factories are version-agnostic, so
+// callers inspect Version to handle migrations. Metadata holds transport
+// headers such as a correlation ID or timestamp under `meta`; it is kept
+// apart from Content and omitted when empty.
 type Envelope struct {
-	Discriminator string `json:"$type"`
-	Content       any    `json:"-"`
+	Discriminator string         `json:"$type"`
+	Version       int            `json:"$version,omitempty"`
+	Metadata      map[string]any `json:"meta,omitempty"`
+	Content       any            `json:"-"`
 }
 
 // MarshalJSON implements json.Marshaler for Envelope. It validates that
 // the discriminator is registered and marshals the content into a small
-// envelope object containing `$type` and `content`.
+// envelope object containing `$type`, `content`, and, when set, `$version`
+// and `meta`.
 func (e *Envelope) MarshalJSON() ([]byte, error) {
 	// Ensure type is registered
 	_, err := LoadFactory(e.Discriminator)
@@ -59,57 +117,212 @@ func (e *Envelope) MarshalJSON() ([]byte, error) {
 	}
 
 	// Use a map to avoid an extra struct allocation
-	return json.Marshal(map[string]any{
+	envelope := map[string]any{
 		"$type":   e.Discriminator,
 		"content": json.RawMessage(contentBytes),
-	})
+	}
+	if e.Version != 0 {
+		envelope["$version"] = e.Version
+	}
+	if len(e.Metadata) > 0 {
+		envelope["meta"] = e.Metadata
+	}
+	return json.Marshal(envelope)
 }
 
 // UnmarshalJSON implements json.Unmarshaler for Envelope. It expects a
 // JSON object with a non-empty `$type` discriminator and a `content` field.
 // The content must be present and non-null; null or missing content
 // returns an error. The content is unmarshaled into a concrete instance
-// returned by the registered factory for that discriminator.
+// returned by the registered factory for that discriminator. Payloads nested
+// deeper than MaxDepth are rejected with ErrMaxDepthExceeded, and content
+// larger than MaxContentBytes with *ContentTooLargeError, before decoding.
+// Migrations registered with RegisterMigration upgrade the raw content
+// from its $version before it is decoded. A `meta` object, if present, is
+// decoded into Metadata independently of the content type.
 func (e *Envelope) UnmarshalJSON(data []byte) error {
+	fields, err := readEnvelope(data)
+	e.Discriminator, e.Version, e.Metadata = fields.discriminator, fields.version, fields.meta
+	if err != nil {
+		return err
+	}
+
+	// Deserialize into the correct type
+	instance, err := decodeContent(fields.content, fields.registration.factory())
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal content for %q: %w", e.Discriminator, err)
+	}
+
+	e.Content = instance
+	return nil
+}
+
+// DecodeInto decodes an envelope's content into target, a non-nil pointer
+// supplied by the caller, instead of an instance from the registered
+// factory; hot paths can use it to decode into pooled values. The
+// discriminator must be registered for target's type (or for the type target
+// points to, when the factory returns values), otherwise a
+// *DiscriminatorMismatchError is returned and target is left untouched.
+// Target is reset to its zero value before decoding so fields from a
+// previous use do not leak into the result. Limits and migrations apply as
+// they do to Envelope.UnmarshalJSON.
+func DecodeInto(data []byte, target any) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Pointer || targetValue.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer, got %T", target)
+	}
+
+	fields, err := readEnvelope(data)
+	if err != nil {
+		return err
+	}
+	registered := fields.registration.typ
+	if registered != targetValue.Type() && (registered == nil || reflect.PointerTo(registered) != targetValue.Type()) {
+		return &DiscriminatorMismatchError{Discriminator: fields.discriminator, Registered: registered, Target: targetValue.Type()}
+	}
+
+	targetValue.Elem().SetZero()
+	if err := json.Unmarshal(fields.content, target); err != nil {
+		return fmt.Errorf("failed to unmarshal content for %q: %w", fields.discriminator, err)
+	}
+	return nil
+}
+
+// DecodeTyped decodes an envelope whose content is expected to be a T, so
+// callers get a typed value without asserting on Envelope.Content. T may be
+// the registered type or a pointer to it: DecodeTyped[Person] and
+// DecodeTyped[*Person] both accept a discriminator whose factory returns
+// *Person (or Person). When the discriminator is registered for another type
+// the zero T and a *DiscriminatorMismatchError are returned. Limits and
+// migrations apply as they do to DecodeInto.
+func DecodeTyped[T any](data []byte) (T, error) {
+	var result T
+	if t := reflect.TypeFor[T](); t.Kind() == reflect.Pointer {
+		target := reflect.New(t.Elem())
+		if err := DecodeInto(data, target.Interface()); err != nil {
+			return result, err
+		}
+		return target.Interface().(T), nil
+	}
+	var target T
+	if err := DecodeInto(data, &target); err != nil {
+		return result, err
+	}
+	return target, nil
+}
+
+// DiscriminatorMismatchError is returned by DecodeInto and DecodeTyped when the envelope's
+// discriminator is registered for a different type than the target.
+type DiscriminatorMismatchError struct {
+	Discriminator string
+	Registered    reflect.Type
+	Target        reflect.Type
+}
+
+func (e *DiscriminatorMismatchError) Error() string {
+	return fmt.Sprintf("cannot decode %q (registered as %v) into %v", e.Discriminator, e.Registered, e.Target)
+}
+
+// envelopeFields holds the members of an envelope read by readEnvelope.
+type envelopeFields struct {
+	discriminator string
+	version       int
+	meta          map[string]any
+	content       json.RawMessage
+	registration  registration
+}
+
+// readEnvelope validates an envelope and returns its discriminator, version,
+// metadata, migrated raw content, and the registration for the
+// discriminator. The discriminator, version, and metadata are returned as far
+// as they were read even when err is non-nil.
+func readEnvelope(data []byte) (fields envelopeFields, err error) {
+	if limit := MaxDepth(); limit > 0 {
+		if err := checkDepth(data, limit); err != nil {
+			return fields, err
+		}
+	}
+
 	aux := make(map[string]json.RawMessage)
 
 	if err := json.Unmarshal(data, &aux); err != nil {
-		return fmt.Errorf("failed to unmarshal envelope: %w", err)
+		return fields, fmt.Errorf("failed to unmarshal envelope: %w", err)
 	}
 
 	// Extract discriminator
 	rawType, found := aux["$type"]
 	if !found {
-		return fmt.Errorf("missing $type field in envelope")
+		return fields, fmt.Errorf("missing $type field in envelope")
+	}
+	var discriminator string
+	if err := json.Unmarshal(rawType, &discriminator); err != nil {
+		return fields, fmt.Errorf("invalid $type format: %w", err)
 	}
-	if err := json.Unmarshal(rawType, &e.Discriminator); err != nil {
-		return fmt.Errorf("invalid $type format: %w", err)
+	if discriminator == "" {
+		return fields, fmt.Errorf("empty $type discriminator")
+	}
+	fields.discriminator = discriminator
+
+	if rawVersion, found := aux["$version"]; found {
+		if err := json.Unmarshal(rawVersion, &fields.version); err != nil {
+			fields.version = 0
+			return fields, fmt.Errorf("invalid $version format: %w", err)
+		}
 	}
-	if e.Discriminator == "" {
-		return fmt.Errorf("empty $type discriminator")
+
+	if rawMeta, found := aux["meta"]; found {
+		if err := json.Unmarshal(rawMeta, &fields.meta); err != nil {
+			fields.meta = nil
+			return fields, fmt.Errorf("invalid meta format: %w", err)
+		}
 	}
 
 	// Ensure type is registered
-	factory, err := LoadFactory(e.Discriminator)
+	entry, err := loadRegistration(discriminator)
 	if err != nil {
-		return err
+		return fields, err
 	}
 
 	// Extract content
 	rawContent, found := aux["content"]
 	if !found || len(rawContent) == 0 {
-		return fmt.Errorf("missing content for type: %q", e.Discriminator)
+		return fields, fmt.Errorf("missing content for type: %q", discriminator)
 	}
 	if string(rawContent) == "null" {
-		return fmt.Errorf("missing content for type: %q", e.Discriminator)
+		return fields, fmt.Errorf("missing content for type: %q", discriminator)
+	}
+	if limit := MaxContentBytes(); limit > 0 && len(rawContent) > limit {
+		return fields, &ContentTooLargeError{Discriminator: discriminator, Size: len(rawContent), Limit: limit}
 	}
 
-	// Deserialize into the correct type
-	instance := factory()
-	if err := json.Unmarshal(rawContent, instance); err != nil {
-		return fmt.Errorf("failed to unmarshal content for %q: %w", e.Discriminator, err)
+	rawContent, fields.version, err = migrateContent(discriminator, fields.version, rawContent)
+	if err != nil {
+		return fields, err
 	}
+	fields.content, fields.registration = rawContent, entry
+	return fields, nil
+}
 
-	e.Content = instance
-	return nil
+// decodeContent unmarshals raw into instance. Factories usually return a
+// pointer, but value factories (for example a registered slice or scalar
+// alias type) are decoded through a temporary pointer and returned by value
+// so the content keeps the registered type.
+func decodeContent(raw json.RawMessage, instance any) (any, error) {
+	v := reflect.ValueOf(instance)
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		if err := json.Unmarshal(raw, instance); err != nil {
+			return nil, err
+		}
+		return instance, nil
+	}
+	if !v.IsValid() {
+		return nil, fmt.Errorf("factory returned nil")
+	}
+
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	if err := json.Unmarshal(raw, ptr.Interface()); err != nil {
+		return nil, err
+	}
+	return ptr.Elem().Interface(), nil
 }
@@ -0,0 +1,103 @@
+package polymorphic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// InlineDiscriminatorKey is the member that carries the discriminator in the
+// inline format written by MarshalPolymorphicJSONInline.
+const InlineDiscriminatorKey = "type"
+
+// MarshalPolymorphicJSONInline marshals obj in the inline format: a single
+// object whose first member is "type", holding the discriminator, followed by
+// obj's own fields, e.g. {"type":"person","name":"Alice"}. This is the shape
+// TypeScript code generators expect for discriminated unions (see
+// jsonschema.GenerateTSUnionSchema). The discriminator must be registered,
+// and obj must marshal to a JSON object without a "type" member of its own.
+func MarshalPolymorphicJSONInline(obj Polymorphic) ([]byte, error) {
+	discriminator := obj.GetDiscriminator()
+	if _, err := LoadFactory(discriminator); err != nil {
+		return nil, err
+	}
+
+	contentBytes, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal content: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(contentBytes, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("inline content for %q must be a JSON object", discriminator)
+	}
+	if _, found := fields[InlineDiscriminatorKey]; found {
+		return nil, fmt.Errorf("inline content for %q already has a %q member", discriminator, InlineDiscriminatorKey)
+	}
+
+	typeBytes, err := json.Marshal(discriminator)
+	if err != nil {
+		return nil, err
+	}
+
+	// Splice the discriminator in front of the content's members so their
+	// order is preserved.
+	var buf bytes.Buffer
+	buf.WriteString(`{"` + InlineDiscriminatorKey + `":`)
+	buf.Write(typeBytes)
+	if len(fields) > 0 {
+		buf.WriteByte(',')
+		buf.Write(bytes.TrimPrefix(bytes.TrimSpace(contentBytes), []byte("{")))
+	} else {
+		buf.WriteByte('}')
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalPolymorphicJSONInline decodes the inline format written by
+// MarshalPolymorphicJSONInline into an Envelope, using the factory
+// registered for the "type" member. The limits set with SetMaxDepth and
+// SetMaxContentBytes apply as they do to envelopes. The inline format does
+// not carry a version, so no migrations run.
+func UnmarshalPolymorphicJSONInline(data []byte) (*Envelope, error) {
+	if limit := MaxDepth(); limit > 0 {
+		if err := checkDepth(data, limit); err != nil {
+			return nil, err
+		}
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal inline polymorphic JSON: %w", err)
+	}
+	rawType, found := fields[InlineDiscriminatorKey]
+	if !found {
+		return nil, fmt.Errorf("missing %s field in inline object", InlineDiscriminatorKey)
+	}
+	var discriminator string
+	if err := json.Unmarshal(rawType, &discriminator); err != nil {
+		return nil, fmt.Errorf("invalid %s format: %w", InlineDiscriminatorKey, err)
+	}
+	if discriminator == "" {
+		return nil, fmt.Errorf("empty %s discriminator", InlineDiscriminatorKey)
+	}
+
+	factory, err := LoadFactory(discriminator)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(fields, InlineDiscriminatorKey)
+	rawContent, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal content for %q: %w", discriminator, err)
+	}
+	if limit := MaxContentBytes(); limit > 0 && len(rawContent) > limit {
+		return nil, &ContentTooLargeError{Discriminator: discriminator, Size: len(rawContent), Limit: limit}
+	}
+
+	instance, err := decodeContent(rawContent, factory())
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal content for %q: %w", discriminator, err)
+	}
+	return &Envelope{Discriminator: discriminator, Content: instance}, nil
+}
@@ -0,0 +1,104 @@
+package polymorphic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TypedWidget struct {
+	Type string `json:"type"`
+}
+
+func (w *TypedWidget) GetDiscriminator() string {
+	return "widget"
+}
+
+func TestShouldMarshalInlineGivenRegisteredType(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Person]()
+
+	// Act
+	data, err := MarshalPolymorphicJSONInline(&Person{Name: "Alice", Age: 30})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"person","name":"Alice","age":30}`, string(data))
+}
+
+func TestShouldRoundTripInlineGivenRegisteredType(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Person]()
+	RegisterType[Car]()
+	data, err := MarshalPolymorphicJSONInline(&Car{Make: "Volvo", Model: "XC40"})
+	require.NoError(t, err)
+
+	// Act
+	envelope, err := UnmarshalPolymorphicJSONInline(data)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "car", envelope.Discriminator)
+	assert.Equal(t, &Car{Make: "Volvo", Model: "XC40"}, envelope.Content)
+}
+
+func TestShouldFailInlineMarshalingGivenInvalidContent(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  Polymorphic
+		want string
+	}{
+		{name: "unregistered", obj: &Car{}, want: `type "car" is not registered`},
+		{name: "not an object", obj: Tags{"a"}, want: "must be a JSON object"},
+		{name: "type member collision", obj: &TypedWidget{Type: "x"}, want: `already has a "type" member`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ClearRegistry()
+			t.Cleanup(ClearRegistry)
+			RegisterType[Person]()
+			RegisterWithDiscriminator("tags", func() any { return Tags{} })
+			RegisterType[TypedWidget]()
+
+			// Act
+			_, err := MarshalPolymorphicJSONInline(tt.obj)
+
+			// Assert
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestShouldFailInlineUnmarshalingGivenInvalidData(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "missing type", data: `{"name":"Alice"}`, want: "missing type field"},
+		{name: "empty type", data: `{"type":""}`, want: "empty type discriminator"},
+		{name: "non-string type", data: `{"type":1}`, want: "invalid type format"},
+		{name: "unregistered type", data: `{"type":"car"}`, want: `type "car" is not registered`},
+		{name: "wrong field type", data: `{"type":"person","age":"old"}`, want: `failed to unmarshal content for "person"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ClearRegistry()
+			t.Cleanup(ClearRegistry)
+			RegisterType[Person]()
+
+			// Act
+			_, err := UnmarshalPolymorphicJSONInline([]byte(tt.data))
+
+			// Assert
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
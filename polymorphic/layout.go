@@ -0,0 +1,94 @@
+package polymorphic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EnvelopeLayout locates the discriminator and content of messages that do
+// not use the standard envelope shape, such as
+// {"header":{"type":"person"},"body":{...}}. Both fields are JSON Pointers
+// (RFC 6901) into the message; the leading "/" may be omitted, so
+// "header/type" and "/header/type" are equivalent.
+type EnvelopeLayout struct {
+	DiscriminatorPath string
+	ContentPath       string
+}
+
+// Unmarshal decodes data using the layout, creating the content with the
+// factory registered for the discriminator found at DiscriminatorPath. The
+// limits set with SetMaxDepth and SetMaxContentBytes apply as they do to
+// envelopes. The layout carries no version or metadata, so no migrations
+// run.
+func (l EnvelopeLayout) Unmarshal(data []byte) (*Envelope, error) {
+	if limit := MaxDepth(); limit > 0 {
+		if err := checkDepth(data, limit); err != nil {
+			return nil, err
+		}
+	}
+
+	rawType, err := resolvePointer(data, l.DiscriminatorPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read discriminator: %w", err)
+	}
+	var discriminator string
+	if err := json.Unmarshal(rawType, &discriminator); err != nil {
+		return nil, fmt.Errorf("invalid discriminator format at %s: %w", l.DiscriminatorPath, err)
+	}
+	if discriminator == "" {
+		return nil, fmt.Errorf("empty discriminator at %s", l.DiscriminatorPath)
+	}
+
+	factory, err := LoadFactory(discriminator)
+	if err != nil {
+		return nil, err
+	}
+
+	rawContent, err := resolvePointer(data, l.ContentPath)
+	if err != nil || string(rawContent) == "null" {
+		return nil, fmt.Errorf("missing content for type: %q", discriminator)
+	}
+	if limit := MaxContentBytes(); limit > 0 && len(rawContent) > limit {
+		return nil, &ContentTooLargeError{Discriminator: discriminator, Size: len(rawContent), Limit: limit}
+	}
+
+	instance, err := decodeContent(rawContent, factory())
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal content for %q: %w", discriminator, err)
+	}
+	return &Envelope{Discriminator: discriminator, Content: instance}, nil
+}
+
+// resolvePointer returns the raw JSON value at pointer within data,
+// descending through object members and array indices.
+func resolvePointer(data []byte, pointer string) (json.RawMessage, error) {
+	raw := json.RawMessage(data)
+	trimmed := strings.TrimPrefix(pointer, "/")
+	if trimmed == "" {
+		return nil, fmt.Errorf("invalid path %q", pointer)
+	}
+	for _, segment := range strings.Split(trimmed, "/") {
+		segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+		var members map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &members); err == nil {
+			next, found := members[segment]
+			if !found {
+				return nil, fmt.Errorf("path %s not found", pointer)
+			}
+			raw = next
+			continue
+		}
+		var elements []json.RawMessage
+		if err := json.Unmarshal(raw, &elements); err != nil {
+			return nil, fmt.Errorf("path %s not found", pointer)
+		}
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 || index >= len(elements) {
+			return nil, fmt.Errorf("path %s not found", pointer)
+		}
+		raw = elements[index]
+	}
+	return raw, nil
+}
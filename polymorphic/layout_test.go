@@ -0,0 +1,74 @@
+package polymorphic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldDecodeHeaderBodyMessageGivenLayout(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Person]()
+	layout := EnvelopeLayout{DiscriminatorPath: "header/type", ContentPath: "/body"}
+	data := []byte(`{"header":{"type":"person","id":"m-1"},"body":{"name":"Alice","age":30}}`)
+
+	// Act
+	envelope, err := layout.Unmarshal(data)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "person", envelope.Discriminator)
+	assert.Equal(t, &Person{Name: "Alice", Age: 30}, envelope.Content)
+}
+
+func TestShouldResolveEscapedAndIndexedPathsGivenLayout(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Car]()
+	layout := EnvelopeLayout{DiscriminatorPath: "/meta/x~1type", ContentPath: "/items/1"}
+	data := []byte(`{"meta":{"x/type":"car"},"items":[{},{"make":"Volvo","model":"XC40"}]}`)
+
+	// Act
+	envelope, err := layout.Unmarshal(data)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, &Car{Make: "Volvo", Model: "XC40"}, envelope.Content)
+}
+
+func TestShouldFailLayoutDecodingGivenInvalidMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "missing header", data: `{"body":{}}`, want: "path header/type not found"},
+		{name: "header not an object", data: `{"header":"person","body":{}}`, want: "path header/type not found"},
+		{name: "non-string discriminator", data: `{"header":{"type":1},"body":{}}`, want: "invalid discriminator format"},
+		{name: "empty discriminator", data: `{"header":{"type":""},"body":{}}`, want: "empty discriminator"},
+		{name: "unregistered", data: `{"header":{"type":"car"},"body":{}}`, want: `"car" is not registered`},
+		{name: "missing body", data: `{"header":{"type":"person"}}`, want: `missing content for type: "person"`},
+		{name: "null body", data: `{"header":{"type":"person"},"body":null}`, want: `missing content for type: "person"`},
+		{name: "invalid body", data: `{"header":{"type":"person"},"body":{"age":"old"}}`, want: `failed to unmarshal content for "person"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ClearRegistry()
+			t.Cleanup(ClearRegistry)
+			RegisterType[Person]()
+			layout := EnvelopeLayout{DiscriminatorPath: "header/type", ContentPath: "body"}
+
+			// Act
+			_, err := layout.Unmarshal([]byte(tt.data))
+
+			// Assert
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package polymorphic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// FieldError reports a content member that UnmarshalPolymorphicJSONLenient
+// could not decode.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %q: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// UnmarshalPolymorphicJSONLenient is like UnmarshalPolymorphicJSON but
+// decodes object content one member at a time, so a member that does not fit
+// the registered type does not stop the others from being decoded. When any
+// member fails, the envelope is returned with the members that did decode,
+// together with an error joining one *FieldError per failed member (in key
+// order). Envelope-level problems (a missing or unregistered $type, missing
+// content, limits, migrations) still fail with a nil envelope, and content
+// that is not an object, or whose registered type implements json.Unmarshaler,
+// is decoded as a whole.
+func UnmarshalPolymorphicJSONLenient(data []byte) (*Envelope, error) {
+	fields, err := readEnvelope(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal polymorphic JSON: %w", err)
+	}
+	envelope := &Envelope{Discriminator: fields.discriminator, Version: fields.version, Metadata: fields.meta}
+
+	instance := fields.registration.factory()
+	v := reflect.ValueOf(instance)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("failed to unmarshal content for %q: factory returned nil", fields.discriminator)
+	}
+	target := instance
+	var ptr reflect.Value
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		ptr = reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		target = ptr.Interface()
+	}
+
+	err = unmarshalMembers(fields.content, target)
+	if ptr.IsValid() {
+		envelope.Content = ptr.Elem().Interface()
+	} else {
+		envelope.Content = instance
+	}
+	if err != nil {
+		return envelope, fmt.Errorf("failed to unmarshal content for %q: %w", fields.discriminator, err)
+	}
+	return envelope, nil
+}
+
+// unmarshalMembers decodes each member of the JSON object raw into target
+// separately and joins the errors of the members that fail.
+func unmarshalMembers(raw json.RawMessage, target any) error {
+	var members map[string]json.RawMessage
+	if _, ok := target.(json.Unmarshaler); ok || json.Unmarshal(raw, &members) != nil {
+		return json.Unmarshal(raw, target)
+	}
+
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var errs []error
+	for _, key := range keys {
+		member, err := json.Marshal(map[string]json.RawMessage{key: members[key]})
+		if err == nil {
+			err = json.Unmarshal(member, target)
+		}
+		if err != nil {
+			errs = append(errs, &FieldError{Field: key, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,83 @@
+package polymorphic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lenientProfile struct {
+	Name  string   `json:"name"`
+	Age   int      `json:"age"`
+	Email string   `json:"email"`
+	Tags  []string `json:"tags"`
+}
+
+func (p *lenientProfile) GetDiscriminator() string {
+	return "lenient_profile"
+}
+
+func TestShouldDecodeRemainingFieldsGivenOneBadField(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[lenientProfile]()
+	data := []byte(`{"$type":"lenient_profile","content":{"name":"Alice","age":"thirty","email":"alice@example.com","tags":["a"]}}`)
+
+	// Act
+	envelope, err := UnmarshalPolymorphicJSONLenient(data)
+
+	// Assert
+	require.Error(t, err)
+	require.NotNil(t, envelope)
+	assert.Equal(t, &lenientProfile{Name: "Alice", Email: "alice@example.com", Tags: []string{"a"}}, envelope.Content)
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "age", fieldErr.Field)
+	_, strictErr := UnmarshalPolymorphicJSON(data)
+	assert.Error(t, strictErr)
+}
+
+func TestShouldReportEveryBadFieldGivenLenientUnmarshal(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[lenientProfile]()
+	data := []byte(`{"$type":"lenient_profile","content":{"name":42,"age":30,"tags":"a"}}`)
+
+	// Act
+	envelope, err := UnmarshalPolymorphicJSONLenient(data)
+
+	// Assert
+	require.NotNil(t, envelope)
+	assert.Equal(t, &lenientProfile{Age: 30}, envelope.Content)
+	var joined interface{ Unwrap() []error }
+	require.True(t, errors.As(err, &joined))
+	var fields []string
+	for _, e := range joined.Unwrap() {
+		var fieldErr *FieldError
+		require.ErrorAs(t, e, &fieldErr)
+		fields = append(fields, fieldErr.Field)
+	}
+	assert.Equal(t, []string{"name", "tags"}, fields)
+}
+
+func TestShouldDecodeLikeStrictGivenValidLenientPayload(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Person]()
+
+	// Act
+	envelope, err := UnmarshalPolymorphicJSONLenient([]byte(`{"$type":"person","$version":2,"meta":{"id":"x"},"content":{"name":"Alice","age":30}}`))
+	_, missingErr := UnmarshalPolymorphicJSONLenient([]byte(`{"$type":"person"}`))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, &Person{Name: "Alice", Age: 30}, envelope.Content)
+	assert.Equal(t, 2, envelope.Version)
+	assert.Equal(t, map[string]any{"id": "x"}, envelope.Metadata)
+	assert.ErrorContains(t, missingErr, "missing content")
+}
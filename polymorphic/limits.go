@@ -0,0 +1,97 @@
+package polymorphic
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// DefaultMaxDepth is the default limit on how deeply an envelope's JSON may
+// nest objects and arrays, including any envelopes nested in its content.
+const DefaultMaxDepth = 128
+
+// ErrMaxDepthExceeded is returned (wrapped) by Envelope.UnmarshalJSON when
+// the envelope nests deeper than the configured maximum depth.
+var ErrMaxDepthExceeded = errors.New("polymorphic: maximum nesting depth exceeded")
+
+// ContentTooLargeError is returned by Envelope.UnmarshalJSON when the raw
+// content of an envelope exceeds the limit set with SetMaxContentBytes.
+type ContentTooLargeError struct {
+	Discriminator string
+	Size          int
+	Limit         int
+}
+
+func (e *ContentTooLargeError) Error() string {
+	return fmt.Sprintf("content for %q is %d bytes, exceeding the limit of %d", e.Discriminator, e.Size, e.Limit)
+}
+
+var (
+	maxDepth        atomic.Int64
+	maxContentBytes atomic.Int64
+)
+
+func init() {
+	maxDepth.Store(DefaultMaxDepth)
+}
+
+// SetMaxDepth sets the maximum JSON nesting depth accepted when unmarshaling
+// an envelope. Each level of object or array counts, so an envelope whose
+// content holds further envelopes consumes several levels per hop. Values
+// less than or equal to zero disable the check. The setting is process-wide
+// and safe for concurrent use.
+func SetMaxDepth(depth int) {
+	maxDepth.Store(int64(depth))
+}
+
+// MaxDepth returns the maximum nesting depth currently enforced by
+// Envelope.UnmarshalJSON, or a value <= 0 when the check is disabled.
+func MaxDepth() int {
+	return int(maxDepth.Load())
+}
+
+// SetMaxContentBytes sets the maximum size, in bytes, of an envelope's raw
+// content accepted by Envelope.UnmarshalJSON. Larger content is rejected with
+// a *ContentTooLargeError before it is decoded. Values less than or equal to
+// zero (the default) disable the check. The setting is process-wide and safe
+// for concurrent use.
+func SetMaxContentBytes(limit int) {
+	maxContentBytes.Store(int64(limit))
+}
+
+// MaxContentBytes returns the content size limit currently enforced by
+// Envelope.UnmarshalJSON, or a value <= 0 when the check is disabled.
+func MaxContentBytes() int {
+	return int(maxContentBytes.Load())
+}
+
+// checkDepth returns an error wrapping ErrMaxDepthExceeded if data nests
+// objects or arrays deeper than limit. Brackets inside strings are ignored.
+func checkDepth(data []byte, limit int) error {
+	depth := 0
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > limit {
+				return fmt.Errorf("%w (limit %d)", ErrMaxDepthExceeded, limit)
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package polymorphic
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// MigrationFunc upgrades the raw content of an envelope by one version.
+type MigrationFunc = func(json.RawMessage) (json.RawMessage, error)
+
+var (
+	migrationsMu sync.RWMutex
+	migrations   = make(map[string]map[int]MigrationFunc)
+)
+
+// RegisterMigration registers fn to upgrade content for discriminator from
+// fromVersion to fromVersion+1. Envelope.UnmarshalJSON applies migrations
+// to the raw content before decoding, chaining them from the envelope's
+// $version (zero when absent) for as long as a migration for the current
+// version exists, and sets Envelope.Version to the version reached.
+// Registering the same discriminator and version again replaces the
+// previous migration. It panics if discriminator is empty or fn is nil.
+func RegisterMigration(discriminator string, fromVersion int, fn MigrationFunc) {
+	if discriminator == "" {
+		panic("discriminator must be non-empty")
+	}
+	if fn == nil {
+		panic("migration must be non-nil")
+	}
+
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+
+	if migrations[discriminator] == nil {
+		migrations[discriminator] = make(map[int]MigrationFunc)
+	}
+	migrations[discriminator][fromVersion] = fn
+}
+
+// migrateContent applies the registered migrations for discriminator
+// starting at version and returns the upgraded content and its version.
+func migrateContent(discriminator string, version int, raw json.RawMessage) (json.RawMessage, int, error) {
+	migrationsMu.RLock()
+	steps := migrations[discriminator]
+	migrationsMu.RUnlock()
+
+	for {
+		migrationsMu.RLock()
+		fn, ok := steps[version]
+		migrationsMu.RUnlock()
+		if !ok {
+			return raw, version, nil
+		}
+		migrated, err := fn(raw)
+		if err != nil {
+			return nil, version, fmt.Errorf("failed to migrate %q from version %d: %w", discriminator, version, err)
+		}
+		raw = migrated
+		version++
+	}
+}
+
+func clearMigrations() {
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+
+	migrations = make(map[string]map[int]MigrationFunc)
+}
@@ -0,0 +1,75 @@
+package polymorphic
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func renameField(from, to string) MigrationFunc {
+	return func(raw json.RawMessage) (json.RawMessage, error) {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, err
+		}
+		if value, ok := fields[from]; ok {
+			fields[to] = value
+			delete(fields, from)
+		}
+		return json.Marshal(fields)
+	}
+}
+
+func TestShouldMigrateContentGivenOlderVersionedPayload(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Person]()
+	RegisterMigration("person", 1, renameField("fullName", "displayName"))
+	RegisterMigration("person", 2, renameField("displayName", "name"))
+	data := []byte(`{"$type":"person","$version":1,"content":{"fullName":"Alice","age":30}}`)
+
+	// Act
+	envelope, err := UnmarshalPolymorphicJSON(data)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 3, envelope.Version)
+	assert.Equal(t, &Person{Name: "Alice", Age: 30}, envelope.Content)
+}
+
+func TestShouldSkipMigrationsGivenCurrentVersion(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Person]()
+	RegisterMigration("person", 1, renameField("fullName", "name"))
+	data := []byte(`{"$type":"person","$version":2,"content":{"name":"Bob","fullName":"ignored"}}`)
+
+	// Act
+	envelope, err := UnmarshalPolymorphicJSON(data)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, envelope.Version)
+	assert.Equal(t, &Person{Name: "Bob"}, envelope.Content)
+}
+
+func TestShouldFailDecodeGivenMigrationError(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Person]()
+	boom := errors.New("boom")
+	RegisterMigration("person", 0, func(json.RawMessage) (json.RawMessage, error) { return nil, boom })
+
+	// Act
+	_, err := UnmarshalPolymorphicJSON([]byte(`{"$type":"person","content":{"name":"Carol"}}`))
+
+	// Assert
+	require.ErrorIs(t, err, boom)
+	assert.ErrorContains(t, err, `failed to migrate "person" from version 0`)
+}
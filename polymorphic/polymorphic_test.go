@@ -3,9 +3,11 @@ package polymorphic
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestShouldLoadFactoryGivenRegisteredType(t *testing.T) {
@@ -480,6 +482,15 @@ func TestRegisterWithDiscriminatorPanicsGivenEmptyString(t *testing.T) {
 	}, "RegisterWithDiscriminator with empty string should panic")
 }
 
+func TestRegisterWithDiscriminatorPanicsGivenNilFactory(t *testing.T) {
+	ClearRegistry()
+	assert.PanicsWithValue(t, "factory must be non-nil", func() {
+		RegisterWithDiscriminator("person", nil)
+	}, "RegisterWithDiscriminator with nil factory should panic")
+	_, err := loadRegistration("person")
+	assert.Error(t, err)
+}
+
 func TestUnmarshalFailsGivenEmptyType(t *testing.T) {
 	ClearRegistry()
 	RegisterType[Person]()
@@ -521,6 +532,93 @@ func TestToPageFiltersToRequestedTypeGivenMixedEnvelopes(t *testing.T) {
 	assert.Equal(t, 25, result.Models[1].Age)
 }
 
+// nestedPageJSON builds a chain of levels PolymorphicPage envelopes, each
+// holding the next in its envelopes array.
+func nestedPageJSON(levels int) string {
+	inner := `{"$type":"mesh://pages/page","content":{}}`
+	for i := 1; i < levels; i++ {
+		inner = `{"$type":"mesh://pages/page","content":{"envelopes":[` + inner + `]}}`
+	}
+	return inner
+}
+
+func TestShouldRejectEnvelopeGivenNestingBeyondMaxDepth(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	SetMaxDepth(30)
+	t.Cleanup(func() { SetMaxDepth(DefaultMaxDepth) })
+	data := []byte(nestedPageJSON(50))
+
+	// Act
+	_, err := UnmarshalPolymorphicJSON(data)
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrMaxDepthExceeded)
+}
+
+func TestShouldDecodeNestedEnvelopesGivenDepthWithinLimit(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	SetMaxDepth(30)
+	t.Cleanup(func() { SetMaxDepth(DefaultMaxDepth) })
+	data := []byte(nestedPageJSON(5))
+
+	// Act
+	envelope, err := UnmarshalPolymorphicJSON(data)
+
+	// Assert
+	require.NoError(t, err)
+	page, ok := envelope.Content.(*PolymorphicPage)
+	require.True(t, ok)
+	assert.Len(t, page.Envelopes, 1)
+}
+
+func TestShouldIgnoreBracketsInsideStringsWhenCheckingDepth(t *testing.T) {
+	// Arrange
+	data := []byte(`{"a":"[[[[{{{{\"]]]]","b":[1]}`)
+
+	// Act
+	err := checkDepth(data, 2)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestShouldRejectEnvelopeGivenContentLargerThanLimit(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	RegisterType[Person]()
+	SetMaxContentBytes(32)
+	t.Cleanup(func() { SetMaxContentBytes(0) })
+	data := []byte(`{"$type":"person","content":{"name":"` + strings.Repeat("A", 64) + `","age":30}}`)
+
+	// Act
+	_, err := UnmarshalPolymorphicJSON(data)
+
+	// Assert
+	var tooLarge *ContentTooLargeError
+	require.ErrorAs(t, err, &tooLarge)
+	assert.Equal(t, "person", tooLarge.Discriminator)
+	assert.Equal(t, 32, tooLarge.Limit)
+	assert.Greater(t, tooLarge.Size, 32)
+}
+
+func TestShouldDecodeEnvelopeGivenContentWithinLimit(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	RegisterType[Person]()
+	SetMaxContentBytes(64)
+	t.Cleanup(func() { SetMaxContentBytes(0) })
+
+	// Act
+	envelope, err := UnmarshalPolymorphicJSON([]byte(`{"$type":"person","content":{"name":"Alice","age":30}}`))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, &Person{Name: "Alice", Age: 30}, envelope.Content)
+}
+
 type Car struct {
 	Make  string `json:"make"`
 	Model string `json:"model"`
@@ -540,3 +638,329 @@ type Person struct {
 func (e *Person) GetDiscriminator() string {
 	return "person"
 }
+
+type Tags []string
+
+func (t Tags) GetDiscriminator() string {
+	return "tags"
+}
+
+type Priority int
+
+func (p Priority) GetDiscriminator() string {
+	return "priority"
+}
+
+func TestShouldRoundTripEnvelopeGivenRegisteredSliceType(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	Register(func() Tags { return Tags{} })
+	original := Tags{"red", "green"}
+
+	// Act
+	data, err := MarshalPolymorphicJSON(original)
+	require.NoError(t, err)
+	envelope, err := UnmarshalPolymorphicJSON(data)
+
+	// Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"$type":"tags","content":["red","green"]}`, string(data))
+	assert.Equal(t, original, envelope.Content)
+}
+
+func TestShouldRoundTripEnvelopeGivenRegisteredScalarAliasType(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	Register(func() Priority { return 0 })
+	original := Priority(3)
+
+	// Act
+	data, err := MarshalPolymorphicJSON(original)
+	require.NoError(t, err)
+	envelope, err := UnmarshalPolymorphicJSON(data)
+
+	// Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"$type":"priority","content":3}`, string(data))
+	assert.Equal(t, original, envelope.Content)
+}
+
+func TestShouldRoundTripEnvelopeGivenPointerRegisteredSliceType(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	RegisterWithDiscriminator("tag-list", func() any { return &[]string{} })
+	envelope := &Envelope{Discriminator: "tag-list", Content: &[]string{"a", "b"}}
+
+	// Act
+	data, err := json.Marshal(envelope)
+	require.NoError(t, err)
+	decoded, err := UnmarshalPolymorphicJSON(data)
+
+	// Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"$type":"tag-list","content":["a","b"]}`, string(data))
+	assert.Equal(t, &[]string{"a", "b"}, decoded.Content)
+}
+
+func TestShouldHashEnvelopesIdenticallyGivenLogicallyEqualContent(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	RegisterType[Person]()
+	decoded, err := UnmarshalPolymorphicJSON([]byte(`{ "content": { "age": 30, "name": "Alice" }, "$type": "person" }`))
+	require.NoError(t, err)
+	built := NewEnvelope(&Person{Name: "Alice", Age: 30})
+	raw := &Envelope{Discriminator: "person", Content: json.RawMessage("{\n  \"age\": 30,\n  \"name\": \"Alice\"\n}")}
+
+	// Act
+	decodedHash, err := ContentHash(decoded)
+	require.NoError(t, err)
+	builtHash, err := ContentHash(built)
+	require.NoError(t, err)
+	rawHash, err := ContentHash(raw)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Len(t, builtHash, 64)
+	assert.Equal(t, builtHash, decodedHash)
+	assert.Equal(t, builtHash, rawHash)
+}
+
+func TestShouldHashEnvelopesDifferentlyGivenDifferentContent(t *testing.T) {
+	// Arrange
+	alice := NewEnvelope(&Person{Name: "Alice", Age: 30})
+	bob := NewEnvelope(&Person{Name: "Bob", Age: 30})
+
+	// Act
+	aliceHash, err := ContentHash(alice)
+	require.NoError(t, err)
+	bobHash, err := ContentHash(bob)
+	require.NoError(t, err)
+	_, nilErr := ContentHash(nil)
+
+	// Assert
+	assert.NotEqual(t, aliceHash, bobHash)
+	assert.Error(t, nilErr)
+}
+
+func TestShouldRoundTripVersionGivenVersionedEnvelope(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	RegisterType[Person]()
+
+	// Act
+	data, err := MarshalPolymorphicJSONVersioned("person", 2, &Person{Name: "Alice", Age: 30})
+	require.NoError(t, err)
+	envelope, err := UnmarshalPolymorphicJSON(data)
+	require.NoError(t, err)
+
+	// Assert
+	assert.JSONEq(t, `{"$type":"person","$version":2,"content":{"name":"Alice","age":30}}`, string(data))
+	assert.Equal(t, 2, envelope.Version)
+	assert.Equal(t, &Person{Name: "Alice", Age: 30}, envelope.Content)
+}
+
+func TestShouldOmitVersionGivenUnversionedEnvelope(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	RegisterType[Person]()
+
+	// Act
+	data, err := MarshalPolymorphicJSON(&Person{Name: "Bob"})
+	require.NoError(t, err)
+	envelope, err := UnmarshalPolymorphicJSON(data)
+	require.NoError(t, err)
+	_, invalidErr := UnmarshalPolymorphicJSON([]byte(`{"$type":"person","$version":"two","content":{}}`))
+
+	// Assert
+	assert.NotContains(t, string(data), "$version")
+	assert.Zero(t, envelope.Version)
+	assert.ErrorContains(t, invalidErr, "invalid $version format")
+}
+
+func TestShouldRoundTripMetadataGivenEnvelopeWithMeta(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Person]()
+	envelope := &Envelope{
+		Discriminator: "person",
+		Metadata:      map[string]any{"correlationId": "abc-123", "timestamp": "2024-05-01T12:00:00Z"},
+		Content:       &Person{Name: "Alice", Age: 30},
+	}
+
+	// Act
+	data, err := json.Marshal(envelope)
+	require.NoError(t, err)
+	decoded, err := UnmarshalPolymorphicJSON(data)
+	require.NoError(t, err)
+
+	// Assert
+	assert.JSONEq(t, `{"$type":"person","meta":{"correlationId":"abc-123","timestamp":"2024-05-01T12:00:00Z"},"content":{"name":"Alice","age":30}}`, string(data))
+	assert.Equal(t, envelope.Metadata, decoded.Metadata)
+	assert.Equal(t, &Person{Name: "Alice", Age: 30}, decoded.Content)
+}
+
+func TestShouldDecodeContentIndependentlyGivenMetaWithContentFields(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Person]()
+	data := []byte(`{"$type":"person","meta":{"name":"header","age":1},"content":{"name":"Alice","age":30}}`)
+
+	// Act
+	envelope, err := UnmarshalPolymorphicJSON(data)
+	require.NoError(t, err)
+	var target Person
+	intoErr := DecodeInto(data, &target)
+
+	// Assert
+	assert.Equal(t, &Person{Name: "Alice", Age: 30}, envelope.Content)
+	assert.Equal(t, map[string]any{"name": "header", "age": float64(1)}, envelope.Metadata)
+	require.NoError(t, intoErr)
+	assert.Equal(t, Person{Name: "Alice", Age: 30}, target)
+}
+
+func TestShouldOmitMetaGivenEnvelopeWithoutMetadata(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Person]()
+
+	// Act
+	data, err := MarshalPolymorphicJSON(&Person{Name: "Bob"})
+	require.NoError(t, err)
+	envelope, err := UnmarshalPolymorphicJSON(data)
+	require.NoError(t, err)
+	_, invalidErr := UnmarshalPolymorphicJSON([]byte(`{"$type":"person","meta":[1],"content":{}}`))
+
+	// Assert
+	assert.NotContains(t, string(data), "meta")
+	assert.Nil(t, envelope.Metadata)
+	assert.ErrorContains(t, invalidErr, "invalid meta format")
+}
+
+func TestShouldListDiscriminatorsInSortedOrder(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Person]()
+	RegisterType[Car]()
+
+	// Act
+	discriminators := Discriminators()
+
+	// Assert
+	assert.Equal(t, []string{"car", "mesh://pages/page", "person"}, discriminators)
+}
+
+func TestShouldDecodeIntoPreallocatedInstanceGivenMatchingDiscriminator(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Person]()
+	target := &Person{Name: "Stale", Age: 99}
+
+	// Act
+	err := DecodeInto([]byte(`{"$type":"person","content":{"name":"Alice"}}`), target)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, &Person{Name: "Alice"}, target)
+}
+
+func TestShouldNotCallFactoryGivenDecodeInto(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	calls := 0
+	RegisterWithDiscriminator("person", func() any {
+		calls++
+		return &Person{}
+	})
+	registered := calls
+	target := &Person{}
+
+	// Act
+	err := DecodeInto([]byte(`{"$type":"person","content":{"name":"Alice"}}`), target)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", target.Name)
+	assert.Equal(t, registered, calls)
+}
+
+func TestShouldDecodeIntoValueTypeGivenValueFactory(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	Register(func() Tags { return Tags{} })
+	var target Tags
+
+	// Act
+	err := DecodeInto([]byte(`{"$type":"tags","content":["red","green"]}`), &target)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, Tags{"red", "green"}, target)
+}
+
+func TestShouldRejectDecodeIntoGivenMismatchedTarget(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Person]()
+	RegisterType[Car]()
+	target := &Person{Name: "Alice"}
+
+	// Act
+	err := DecodeInto([]byte(`{"$type":"car","content":{"make":"Volvo"}}`), target)
+	nilErr := DecodeInto([]byte(`{"$type":"person","content":{}}`), (*Person)(nil))
+
+	// Assert
+	var mismatch *DiscriminatorMismatchError
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, "car", mismatch.Discriminator)
+	assert.ErrorContains(t, err, `cannot decode "car" (registered as *polymorphic.Car) into *polymorphic.Person`)
+	assert.Equal(t, &Person{Name: "Alice"}, target)
+	assert.ErrorContains(t, nilErr, "non-nil pointer")
+}
+
+func TestShouldDecodeTypedValueGivenRegisteredDiscriminator(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Person]()
+	data := []byte(`{"$type":"person","content":{"name":"Alice","age":30}}`)
+
+	// Act
+	pointer, pointerErr := DecodeTyped[*Person](data)
+	value, valueErr := DecodeTyped[Person](data)
+
+	// Assert
+	require.NoError(t, pointerErr)
+	require.NoError(t, valueErr)
+	assert.Equal(t, &Person{Name: "Alice", Age: 30}, pointer)
+	assert.Equal(t, Person{Name: "Alice", Age: 30}, value)
+}
+
+func TestShouldReturnZeroValueGivenDecodeTypedDiscriminatorMismatch(t *testing.T) {
+	// Arrange
+	ClearRegistry()
+	t.Cleanup(ClearRegistry)
+	RegisterType[Person]()
+	RegisterType[Car]()
+
+	// Act
+	pointer, pointerErr := DecodeTyped[*Person]([]byte(`{"$type":"car","content":{"make":"Volvo"}}`))
+	value, valueErr := DecodeTyped[Person]([]byte(`{"$type":"car","content":{"make":"Volvo"}}`))
+	_, unknownErr := DecodeTyped[Person]([]byte(`{"$type":"boat","content":{}}`))
+
+	// Assert
+	var mismatch *DiscriminatorMismatchError
+	require.ErrorAs(t, pointerErr, &mismatch)
+	assert.Equal(t, "car", mismatch.Discriminator)
+	assert.Nil(t, pointer)
+	require.ErrorAs(t, valueErr, &mismatch)
+	assert.Equal(t, Person{}, value)
+	assert.Error(t, unknownErr)
+}
@@ -2,6 +2,8 @@ package polymorphic
 
 import (
 	"fmt"
+	"reflect"
+	"slices"
 	"sync"
 	"sync/atomic"
 )
@@ -16,35 +18,49 @@ type Polymorphic interface {
 // TypeFactory creates instances of registered types.
 type TypeFactory = func() any
 
+// registration is a registered factory together with the type of the values
+// it returns, recorded once so decoders can check a target's type without
+// calling the factory.
+type registration struct {
+	factory TypeFactory
+	typ     reflect.Type
+}
+
 var (
 	registryMu   sync.Mutex
-	types        = make(map[string]TypeFactory)
-	defaultTypes = make(map[string]TypeFactory)
-	registryView atomic.Value // stores map[string]TypeFactory
+	types        = make(map[string]registration)
+	defaultTypes = make(map[string]registration)
+	registryView atomic.Value // stores map[string]registration
 )
 
 func init() {
-	registryView.Store(cloneFactories(types))
+	registryView.Store(cloneRegistrations(types))
 }
 
 func registerWithDiscriminator(discriminator string, factory TypeFactory, isDefault bool) {
 	if discriminator == "" {
 		panic("discriminator must be non-empty")
 	}
+	if factory == nil {
+		panic("factory must be non-nil")
+	}
+	entry := registration{factory: factory, typ: reflect.TypeOf(factory())}
 
 	registryMu.Lock()
 	defer registryMu.Unlock()
 
-	types[discriminator] = factory
+	types[discriminator] = entry
 	if isDefault {
-		defaultTypes[discriminator] = factory
+		defaultTypes[discriminator] = entry
 	}
-	registryView.Store(cloneFactories(types))
+	registryView.Store(cloneRegistrations(types))
 }
 
 // RegisterWithDiscriminator stores a factory function under the given
 // discriminator. The factory should return a pointer to a zero-value
-// instance of the concrete type. It panics if discriminator is empty.
+// instance of the concrete type. It is invoked once during registration,
+// to record that type, so any side effects it has run at registration time.
+// It panics if discriminator is empty or factory is nil.
 func RegisterWithDiscriminator(discriminator string, factory TypeFactory) {
 	registerWithDiscriminator(discriminator, factory, false)
 }
@@ -118,28 +134,51 @@ func CreateInstance(discriminator string) (Polymorphic, error) {
 // LoadFactory returns the factory function registered for the
 // discriminator, or an error if there is none.
 func LoadFactory(discriminator string) (TypeFactory, error) {
-	current := registryView.Load().(map[string]TypeFactory)
-	if factory, ok := current[discriminator]; ok {
-		return factory, nil
+	entry, err := loadRegistration(discriminator)
+	if err != nil {
+		return nil, err
+	}
+	return entry.factory, nil
+}
+
+// loadRegistration returns the registration for the discriminator, or an
+// error if there is none.
+func loadRegistration(discriminator string) (registration, error) {
+	current := registryView.Load().(map[string]registration)
+	if entry, ok := current[discriminator]; ok {
+		return entry, nil
+	}
+	return registration{}, fmt.Errorf("type %q is not registered", discriminator)
+}
+
+// Discriminators returns the registered discriminators in sorted order.
+func Discriminators() []string {
+	current := registryView.Load().(map[string]registration)
+	discriminators := make([]string, 0, len(current))
+	for discriminator := range current {
+		discriminators = append(discriminators, discriminator)
 	}
-	return nil, fmt.Errorf("type %q is not registered", discriminator)
+	slices.Sort(discriminators)
+	return discriminators
 }
 
-func cloneFactories(source map[string]TypeFactory) map[string]TypeFactory {
-	cloned := make(map[string]TypeFactory, len(source))
-	for discriminator, factory := range source {
-		cloned[discriminator] = factory
+func cloneRegistrations(source map[string]registration) map[string]registration {
+	cloned := make(map[string]registration, len(source))
+	for discriminator, entry := range source {
+		cloned[discriminator] = entry
 	}
 	return cloned
 }
 
-// ClearRegistry resets the registry to the package default factories.
-// Useful in tests to remove custom registrations without leaving the
-// package in a partially uninitialized state.
+// ClearRegistry resets the registry to the package default factories and
+// removes all registered migrations. Useful in tests to remove custom
+// registrations without leaving the package in a partially uninitialized
+// state.
 func ClearRegistry() {
 	registryMu.Lock()
 	defer registryMu.Unlock()
 
-	types = cloneFactories(defaultTypes)
-	registryView.Store(cloneFactories(types))
+	types = cloneRegistrations(defaultTypes)
+	registryView.Store(cloneRegistrations(types))
+	clearMigrations()
 }
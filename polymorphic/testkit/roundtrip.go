@@ -0,0 +1,32 @@
+package testkit
+
+import (
+	"github.com/fgrzl/json/polymorphic"
+	"github.com/stretchr/testify/assert"
+)
+
+// AssertRoundTrip marshals value into an envelope under discriminator,
+// unmarshals it through the registry, and asserts that the decoded content
+// deep-equals value, reporting a diff when a field does not survive (for
+// example an unexported field or a missing json tag). value should have the
+// type the discriminator's factory returns, usually a pointer. It reports
+// failures through t without stopping the test and returns whether the round
+// trip succeeded.
+func AssertRoundTrip(t assert.TestingT, discriminator string, value any) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	data, err := polymorphic.MarshalPolymorphicJSONVersioned(discriminator, 0, value)
+	if !assert.NoError(t, err, "marshal %s", discriminator) {
+		return false
+	}
+	envelope, err := polymorphic.UnmarshalPolymorphicJSON(data)
+	if !assert.NoError(t, err, "unmarshal %s from %s", discriminator, data) {
+		return false
+	}
+	if !assert.Equal(t, discriminator, envelope.Discriminator, "discriminator changed in round trip") {
+		return false
+	}
+	return assert.Equal(t, value, envelope.Content, "%s did not survive the envelope round trip via %s", discriminator, data)
+}
@@ -0,0 +1,74 @@
+package testkit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fgrzl/json/polymorphic"
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripPerson struct {
+	Name    string    `json:"name"`
+	Age     int       `json:"age"`
+	Born    time.Time `json:"born"`
+	Aliases []string  `json:"aliases,omitempty"`
+}
+
+func (p *roundTripPerson) GetDiscriminator() string { return "roundtrip-person" }
+
+type lossyPerson struct {
+	Name   string `json:"name"`
+	secret string
+}
+
+func (p *lossyPerson) GetDiscriminator() string { return "lossy-person" }
+
+// recordingT captures failures reported by an assertion.
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestShouldPassRoundTripGivenRegisteredPerson(t *testing.T) {
+	// Arrange
+	t.Cleanup(polymorphic.ClearRegistry)
+	polymorphic.ClearRegistry()
+	polymorphic.RegisterType[roundTripPerson]()
+	person := &roundTripPerson{
+		Name:    "Alice",
+		Age:     30,
+		Born:    time.Date(1994, time.May, 1, 0, 0, 0, 0, time.UTC),
+		Aliases: []string{"Al"},
+	}
+
+	// Act
+	ok := AssertRoundTrip(t, "roundtrip-person", person)
+
+	// Assert
+	assert.True(t, ok)
+}
+
+func TestShouldReportDiffGivenFieldLostInRoundTrip(t *testing.T) {
+	// Arrange
+	t.Cleanup(polymorphic.ClearRegistry)
+	polymorphic.ClearRegistry()
+	polymorphic.RegisterType[lossyPerson]()
+	recorder := &recordingT{}
+
+	// Act
+	ok := AssertRoundTrip(recorder, "lossy-person", &lossyPerson{Name: "Bob", secret: "s"})
+	unregistered := AssertRoundTrip(&recordingT{}, "missing", &lossyPerson{})
+
+	// Assert
+	assert.False(t, ok)
+	assert.False(t, unregistered)
+	if assert.Len(t, recorder.errors, 1) {
+		assert.Contains(t, recorder.errors[0], "lossy-person did not survive the envelope round trip")
+		assert.Contains(t, recorder.errors[0], `secret: (string) (len=1) "s"`)
+	}
+}
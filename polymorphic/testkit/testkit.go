@@ -1,4 +1,6 @@
-// Package testkit provides helpers for polymorphic registration tests.
+// Package testkit provides helpers for tests of polymorphic registrations:
+// TestPolymorphicRegistrations checks discriminator-to-type mappings, and
+// AssertRoundTrip checks that a value survives the envelope format.
 package testkit
 
 import (